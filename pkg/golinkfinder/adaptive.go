@@ -0,0 +1,144 @@
+package golinkfinder
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+const (
+	adaptiveStart    = 2
+	adaptiveCooldown = time.Second
+)
+
+type statusError struct {
+	code int
+	// challenge names the bot protection that answered, if any.
+	challenge string
+}
+
+func (e *statusError) Error() string {
+	if e.challenge != "" {
+		return fmt.Sprintf("bad status code: %d (%s challenge)", e.code, e.challenge)
+	}
+	return fmt.Sprintf("bad status code: %d", e.code)
+}
+
+// aimdLimiter caps in-flight requests to one host. The limit grows by one
+// for every window of successful requests and halves when the host starts
+// failing, at most once per cooldown so a burst of errors counts once.
+// Jobs over the limit are parked rather than waited for, so a throttled
+// host never holds up the workers fetching other hosts.
+type aimdLimiter struct {
+	mu           sync.Mutex
+	limit        float64
+	max          int
+	inFlight     int
+	parked       []parkedJob
+	lastDecrease time.Time
+}
+
+// parkedJob is a job waiting for a slot of its host, with what the worker
+// taking it over needs to run it and hand back its result.
+type parkedJob struct {
+	ctx  context.Context
+	job  scanJob
+	emit func(linkFinderResult)
+}
+
+func newAIMDLimiter(max int) *aimdLimiter {
+	l := &aimdLimiter{limit: adaptiveStart, max: max}
+	if max < adaptiveStart {
+		l.limit = float64(max)
+	}
+	return l
+}
+
+// admit takes a slot for p, or parks p and returns false when the host is
+// at its limit. A parked job is handed to the worker releasing a slot.
+func (l *aimdLimiter) admit(p parkedJob) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.inFlight >= int(l.limit) {
+		l.parked = append(l.parked, p)
+		return false
+	}
+	l.inFlight++
+	return true
+}
+
+// release frees a slot and adjusts the limit. When a parked job fits under
+// the new limit, its slot is kept for it and it is returned for the caller
+// to run.
+func (l *aimdLimiter) release(healthy bool) (parkedJob, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.inFlight--
+	if healthy {
+		l.limit += 1 / l.limit
+		if l.limit > float64(l.max) {
+			l.limit = float64(l.max)
+		}
+	} else if time.Since(l.lastDecrease) > adaptiveCooldown {
+		l.limit /= 2
+		if l.limit < 1 {
+			l.limit = 1
+		}
+		l.lastDecrease = time.Now()
+	}
+	if len(l.parked) == 0 || l.inFlight >= int(l.limit) {
+		return parkedJob{}, false
+	}
+	next := l.parked[0]
+	l.parked[0] = parkedJob{}
+	l.parked = l.parked[1:]
+	l.inFlight++
+	return next, true
+}
+
+type adaptiveScheduler struct {
+	mu    sync.Mutex
+	max   int
+	hosts map[string]*aimdLimiter
+}
+
+func newAdaptiveScheduler(max int) *adaptiveScheduler {
+	return &adaptiveScheduler{max: max, hosts: make(map[string]*aimdLimiter)}
+}
+
+func (a *adaptiveScheduler) limiter(targetURL string) *aimdLimiter {
+	host := targetURL
+	if u, err := url.Parse(targetURL); err == nil && u.Host != "" {
+		host = u.Host
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	l, ok := a.hosts[host]
+	if !ok {
+		l = newAIMDLimiter(a.max)
+		a.hosts[host] = l
+	}
+	return l
+}
+
+// hostHealthy reports whether a fetch outcome says the host is coping. Plain
+// client errors such as 404 are the target's answer, not a sign of overload.
+func hostHealthy(err error) bool {
+	if err == nil {
+		return true
+	}
+	var skipped *skipError
+	var authErr *authRequiredError
+	if errors.As(err, &skipped) || errors.As(err, &authErr) || aborted(err) {
+		return true
+	}
+	var se *statusError
+	if errors.As(err, &se) {
+		return se.challenge == "" && se.code != http.StatusTooManyRequests && se.code < 500
+	}
+	return false
+}
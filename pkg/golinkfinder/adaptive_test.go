@@ -0,0 +1,93 @@
+package golinkfinder
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestAIMDLimiter(t *testing.T) {
+	l := newAIMDLimiter(4)
+	job := func(name string) parkedJob { return parkedJob{job: scanJob{url: name}} }
+	if !l.admit(job("a")) || !l.admit(job("b")) {
+		t.Fatal("the first two jobs were not admitted")
+	}
+	if l.admit(job("c")) {
+		t.Fatal("a job over the starting limit was admitted")
+	}
+	next, ok := l.release(true)
+	if !ok || next.job.url != "c" {
+		t.Fatalf("release handed over %v, %v; want the parked job", next.job.url, ok)
+	}
+	if l.inFlight != 2 {
+		t.Errorf("inFlight = %d after handing over, want 2", l.inFlight)
+	}
+
+	// A failing host halves the limit, once per cooldown.
+	l.limit = 4
+	l.release(false)
+	l.release(false)
+	if l.limit != 2 {
+		t.Errorf("limit = %v after a burst of failures, want 2", l.limit)
+	}
+	for i := 0; i < 20; i++ {
+		l.inFlight++
+		l.release(true)
+	}
+	if l.limit != 4 {
+		t.Errorf("limit = %v after many successes, want the maximum 4", l.limit)
+	}
+}
+
+// Run with -race: parked jobs move between workers.
+func TestAdaptiveRun(t *testing.T) {
+	var mu sync.Mutex
+	inFlight, peak := 0, 0
+	throttled := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		inFlight++
+		peak = max(peak, inFlight)
+		mu.Unlock()
+		time.Sleep(10 * time.Millisecond)
+		mu.Lock()
+		inFlight--
+		mu.Unlock()
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer throttled.Close()
+	healthy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/javascript")
+		fmt.Fprintf(w, "fetch('/api%s');", r.URL.Path)
+	}))
+	defer healthy.Close()
+	defer func(w io.Writer) { statusOut = w }(statusOut)
+	statusOut = io.Discard
+
+	o := defaultScanOptions()
+	o.threads, o.adaptive, o.quiet = 8, true, true
+	s, err := newScanSession(&o, cliOutput())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	var jobs []scanJob
+	for i := 0; i < 20; i++ {
+		jobs = append(jobs, scanJob{url: fmt.Sprintf("%s/%d", throttled.URL, i)}, scanJob{url: fmt.Sprintf("%s/%d", healthy.URL, i)})
+	}
+	found, failed := s.run(context.Background(), jobs)
+	defer found.Close()
+	if failed != 20 || found.Len() != 20 {
+		t.Errorf("got %d endpoints and %d failures, want 20 and 20", found.Len(), failed)
+	}
+	if s.stats.Targets != len(jobs) {
+		t.Errorf("%d of %d targets reported", s.stats.Targets, len(jobs))
+	}
+	if peak > adaptiveStart {
+		t.Errorf("%d requests in flight to a host answering 429, want at most %d", peak, adaptiveStart)
+	}
+}
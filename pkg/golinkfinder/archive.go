@@ -0,0 +1,113 @@
+package golinkfinder
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+)
+
+// archiveJobs turns the text files of a .zip, .tar, .tar.gz or .tgz
+// archive into scan jobs attributed to "archive!/inner/path". Files with a
+// known source extension are typed by it; other files are kept when they
+// sniff as text. Members larger than gitMaxFileSize are skipped.
+func archiveJobs(archive string) ([]scanJob, error) {
+	lower := strings.ToLower(archive)
+	switch {
+	case strings.HasSuffix(lower, ".zip"):
+		return zipJobs(archive)
+	case strings.HasSuffix(lower, ".tar"), strings.HasSuffix(lower, ".tar.gz"), strings.HasSuffix(lower, ".tgz"):
+		return tarJobs(archive, !strings.HasSuffix(lower, ".tar"))
+	}
+	return nil, fmt.Errorf("unsupported archive '%s' (valid: .zip, .tar, .tar.gz, .tgz)", archive)
+}
+
+// archiveJob reads one member, returning false for binary or oversized
+// files.
+func archiveJob(archive, name string, r io.Reader) (scanJob, bool, error) {
+	body, err := io.ReadAll(io.LimitReader(r, gitMaxFileSize+1))
+	if err != nil {
+		return scanJob{}, false, fmt.Errorf("could not read %s in %s: %v", name, archive, err)
+	}
+	if len(body) > gitMaxFileSize {
+		return scanJob{}, false, nil
+	}
+	contentType, known := gitExtensions[strings.ToLower(path.Ext(name))]
+	if !known {
+		contentType = http.DetectContentType(body)
+		if !strings.HasPrefix(contentType, "text/") {
+			return scanJob{}, false, nil
+		}
+	}
+	return scanJob{url: archive + "!" + path.Clean("/"+name), contentType: contentType, body: body}, true, nil
+}
+
+func zipJobs(archive string) ([]scanJob, error) {
+	zr, err := zip.OpenReader(archive)
+	if err != nil {
+		return nil, fmt.Errorf("could not open archive: %v", err)
+	}
+	defer zr.Close()
+	jobs := make([]scanJob, 0)
+	for _, f := range zr.File {
+		if f.FileInfo().IsDir() || f.UncompressedSize64 > gitMaxFileSize {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return nil, fmt.Errorf("could not read %s in %s: %v", f.Name, archive, err)
+		}
+		job, ok, err := archiveJob(archive, f.Name, rc)
+		rc.Close()
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			jobs = append(jobs, job)
+		}
+	}
+	return jobs, nil
+}
+
+func tarJobs(archive string, gzipped bool) ([]scanJob, error) {
+	file, err := os.Open(archive)
+	if err != nil {
+		return nil, fmt.Errorf("could not open archive: %v", err)
+	}
+	defer file.Close()
+	var r io.Reader = file
+	if gzipped {
+		gz, err := gzip.NewReader(file)
+		if err != nil {
+			return nil, fmt.Errorf("could not open archive: %v", err)
+		}
+		defer gz.Close()
+		r = gz
+	}
+	tr := tar.NewReader(r)
+	jobs := make([]scanJob, 0)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return jobs, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("could not read archive %s: %v", archive, err)
+		}
+		if hdr.Typeflag != tar.TypeReg || hdr.Size > gitMaxFileSize {
+			continue
+		}
+		job, ok, err := archiveJob(archive, hdr.Name, tr)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			jobs = append(jobs, job)
+		}
+	}
+}
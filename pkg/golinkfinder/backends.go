@@ -0,0 +1,169 @@
+package golinkfinder
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const categoryBackend = "backend"
+
+// backendWindow is how far around an anchor the other fields of the same
+// config object are looked for.
+const backendWindow = 600
+
+var (
+	// configFieldRegex matches key: "value" and key = "value" pairs in
+	// object literals and env-style blocks.
+	configFieldRegex = regexp.MustCompile(`["']?([A-Za-z_$][\w$]*)["']?\s*[:=]\s*["']([^"'\n]{1,300})["']`)
+
+	firebaseAnchorRegex = regexp.MustCompile(`["']?projectId["']?\s*:\s*["']([a-z0-9-]{4,40})["']`)
+	supabaseURLRegex    = regexp.MustCompile(`https://([a-z0-9]{20})\.supabase\.(?:co|in)\b`)
+	algoliaAnchorRegex  = regexp.MustCompile(`(?i)["']?(?:algolia_?app_?id|appId|applicationId)["']?\s*[:=]\s*["']([A-Z0-9]{10})["']|algoliasearch\(\s*["']([A-Z0-9]{10})["']\s*,\s*["']([a-f0-9]{32})["']`)
+	algoliaKeyRegex     = regexp.MustCompile(`(?i)["']?(?:algolia_?(?:search_?)?api_?key|apiKey|searchKey|searchApiKey)["']?\s*[:=]\s*["']([a-f0-9]{32})["']`)
+	mapboxTokenRegex    = regexp.MustCompile(`\b[ps]k\.eyJ[A-Za-z0-9_-]{10,}\.[A-Za-z0-9_-]{10,}`)
+)
+
+var firebaseFields = []string{"apiKey", "authDomain", "databaseURL", "storageBucket", "messagingSenderId", "appId", "measurementId"}
+
+// around returns content within backendWindow bytes of [start, end).
+func around(content string, start, end int) string {
+	start, end = start-backendWindow, end+backendWindow
+	if start < 0 {
+		start = 0
+	}
+	if end > len(content) {
+		end = len(content)
+	}
+	return content[start:end]
+}
+
+// configFields returns the first value of every key in text.
+func configFields(text string) map[string]string {
+	fields := make(map[string]string)
+	for _, m := range configFieldRegex.FindAllStringSubmatch(text, -1) {
+		if _, ok := fields[m[1]]; !ok {
+			fields[m[1]] = m[2]
+		}
+	}
+	return fields
+}
+
+// jwtClaim returns a string claim of an unverified JWT.
+func jwtClaim(token, name string) string {
+	_, claims, ok := describeJWT(token)
+	if !ok {
+		return ""
+	}
+	s, _ := claims[name].(string)
+	return s
+}
+
+// backendExtractor recognizes the client configs of hosted backends and
+// reports each as one finding naming the service, with the identifying
+// value (project, URL, app ID or token) and the rest of the config in the
+// note. Their URLs are reported as endpoints too.
+type backendExtractor struct{}
+
+func (backendExtractor) Extract(source, contentType string, body []byte) []Finding {
+	content := string(body)
+	lines := newLineIndex(content)
+	seen := make(map[string]struct{})
+	findings := make([]Finding, 0)
+	add := func(offset int, f Finding) {
+		key := f.Category + "\x00" + f.Value
+		if _, ok := seen[key]; ok {
+			return
+		}
+		seen[key] = struct{}{}
+		f.Source, f.Line, f.Offset = source, lines.line(offset), offset
+		findings = append(findings, f)
+	}
+
+	for _, loc := range firebaseAnchorRegex.FindAllStringSubmatchIndex(content, -1) {
+		fields := configFields(around(content, loc[0], loc[1]))
+		project := content[loc[2]:loc[3]]
+		if !strings.HasPrefix(fields["apiKey"], "AIza") && !strings.Contains(fields["authDomain"], "firebaseapp.com") {
+			continue
+		}
+		note := []string{"firebase"}
+		for _, name := range firebaseFields {
+			if v := fields[name]; v != "" {
+				note = append(note, name+" "+v)
+			}
+		}
+		add(loc[0], Finding{Value: project, Category: categoryBackend, Note: strings.Join(note, ", ")})
+		if u := fields["databaseURL"]; strings.HasPrefix(u, "https://") {
+			add(loc[0], Finding{Value: u, Category: categoryEndpoint, Note: "firebase database"})
+		}
+		if d := fields["authDomain"]; d != "" {
+			add(loc[0], Finding{Value: "https://" + d, Category: categoryEndpoint, Note: "firebase auth domain"})
+		}
+	}
+
+	for _, loc := range supabaseURLRegex.FindAllStringIndex(content, -1) {
+		u := content[loc[0]:loc[1]]
+		note := []string{"supabase"}
+		for _, m := range jwtRegex.FindAllString(around(content, loc[0], loc[1]), -1) {
+			if role := jwtClaim(m, "role"); role != "" {
+				note = append(note, role+" key "+m)
+				break
+			}
+		}
+		add(loc[0], Finding{Value: u, Category: categoryBackend, Note: strings.Join(note, ", ")})
+	}
+
+	for _, loc := range algoliaAnchorRegex.FindAllStringSubmatchIndex(content, -1) {
+		var app, key string
+		if loc[2] >= 0 {
+			app = content[loc[2]:loc[3]]
+			if m := algoliaKeyRegex.FindStringSubmatch(around(content, loc[0], loc[1])); m != nil {
+				key = m[1]
+			}
+		} else {
+			app, key = content[loc[4]:loc[5]], content[loc[6]:loc[7]]
+		}
+		// appId alone also names Firebase apps; Algolia's need a key.
+		if key == "" && !strings.Contains(strings.ToLower(content[loc[0]:loc[1]]), "algolia") {
+			continue
+		}
+		note := "algolia"
+		if key != "" {
+			note += ", api key " + key
+		}
+		add(loc[0], Finding{Value: app, Category: categoryBackend, Note: note})
+		add(loc[0], Finding{Value: fmt.Sprintf("https://%s-dsn.algolia.net", strings.ToLower(app)), Category: categoryEndpoint, Note: "algolia"})
+	}
+
+	for _, loc := range mapboxTokenRegex.FindAllStringIndex(content, -1) {
+		token := content[loc[0]:loc[1]]
+		note := "mapbox, public token"
+		if strings.HasPrefix(token, "sk.") {
+			note = "mapbox, secret token"
+		}
+		if user := mapboxUser(token); user != "" {
+			note += ", account " + user
+		}
+		add(loc[0], Finding{Value: token, Category: categoryBackend, Note: note})
+	}
+	return findings
+}
+
+// mapboxUser decodes the account name from a Mapbox token's payload.
+func mapboxUser(token string) string {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return ""
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return ""
+	}
+	var payload struct {
+		U string `json:"u"`
+	}
+	json.Unmarshal(raw, &payload)
+	return payload.U
+}
@@ -0,0 +1,134 @@
+package golinkfinder
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var errByteBudget = errors.New("-max-total-bytes reached")
+
+// byteBudget meters what every connection of a run reads off the wire:
+// -max-bandwidth paces the reads of all workers together, and
+// -max-total-bytes fails every read once the run has downloaded that much.
+// HTTP/3 runs over UDP and isn't metered.
+type byteBudget struct {
+	rate  float64 // bytes per second, 0 = unlimited
+	limit int64   // total bytes, 0 = unlimited
+	total atomic.Int64
+
+	mu   sync.Mutex
+	next time.Time
+}
+
+func newByteBudget(bandwidth, total string) (*byteBudget, error) {
+	if bandwidth == "" && total == "" {
+		return nil, nil
+	}
+	b := &byteBudget{}
+	if bandwidth != "" {
+		n, err := parseByteSize(strings.TrimSuffix(strings.ToLower(bandwidth), "/s"))
+		if err != nil {
+			return nil, fmt.Errorf("invalid -max-bandwidth: %v", err)
+		}
+		b.rate = float64(n)
+	}
+	if total != "" {
+		n, err := parseByteSize(total)
+		if err != nil {
+			return nil, fmt.Errorf("invalid -max-total-bytes: %v", err)
+		}
+		b.limit = n
+	}
+	return b, nil
+}
+
+// parseByteSize reads sizes like 512, 64KB, 10MB or 1.5GB (units of 1024).
+func parseByteSize(size string) (int64, error) {
+	s := strings.ToUpper(strings.TrimSpace(size))
+	mult := int64(1)
+	for _, u := range []struct {
+		suffix string
+		mult   int64
+	}{{"GB", 1 << 30}, {"MB", 1 << 20}, {"KB", 1 << 10}, {"G", 1 << 30}, {"M", 1 << 20}, {"K", 1 << 10}, {"B", 1}} {
+		if strings.HasSuffix(s, u.suffix) {
+			s, mult = strings.TrimSpace(strings.TrimSuffix(s, u.suffix)), u.mult
+			break
+		}
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil || f <= 0 {
+		return 0, fmt.Errorf("'%s' is not a size like 500KB or 10MB", size)
+	}
+	return int64(f * float64(mult)), nil
+}
+
+func (b *byteBudget) exhausted() bool {
+	return b != nil && b.limit > 0 && b.total.Load() >= b.limit
+}
+
+// chunk caps one read so a paused read never sleeps much over 100ms.
+func (b *byteBudget) chunk(n int) int {
+	if b.rate <= 0 {
+		return n
+	}
+	max := int(b.rate / 10)
+	if max < 512 {
+		max = 512
+	}
+	if n > max {
+		return max
+	}
+	return n
+}
+
+// account records n bytes read and waits until the rate allows them.
+func (b *byteBudget) account(n int) {
+	b.total.Add(int64(n))
+	if b.rate <= 0 || n <= 0 {
+		return
+	}
+	b.mu.Lock()
+	now := time.Now()
+	if b.next.Before(now) {
+		b.next = now
+	}
+	b.next = b.next.Add(time.Duration(float64(n) / b.rate * float64(time.Second)))
+	wait := b.next.Sub(now)
+	b.mu.Unlock()
+	time.Sleep(wait)
+}
+
+type meteredConn struct {
+	net.Conn
+	budget *byteBudget
+}
+
+func (c *meteredConn) Read(p []byte) (int, error) {
+	if c.budget.exhausted() {
+		return 0, errByteBudget
+	}
+	n, err := c.Conn.Read(p[:c.budget.chunk(len(p))])
+	c.budget.account(n)
+	return n, err
+}
+
+// metered wraps dial so its connections count against budget.
+func metered(dial dialFunc, budget *byteBudget) dialFunc {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		if budget.exhausted() {
+			return nil, errByteBudget
+		}
+		conn, err := dial(ctx, network, addr)
+		if err != nil {
+			return nil, err
+		}
+		return &meteredConn{Conn: conn, budget: budget}, nil
+	}
+}
@@ -0,0 +1,154 @@
+package golinkfinder
+
+import (
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+)
+
+const categoryBase = "base"
+
+// apiBaseRegex matches the API base a bundle configures for its HTTP
+// client: axios.create({baseURL: "..."}), axios.defaults.baseURL = "...",
+// and constants such as API_BASE_URL or apiUrl.
+var apiBaseRegex = regexp.MustCompile(`(?i)\b(?:base_?url|api_?base(?:_?url)?|api_?url|api_?root|api_?host|api_?endpoint)["']?\s*[:=]\s*["'` + "`" + `]((?:https?:)?/[^"'` + "`" + `\s]{0,200})["'` + "`" + `]`)
+
+var (
+	// createClientRegex matches api = axios.create({ ahead of a baseURL
+	// key, and defaultsClientRegex the api.defaults. of
+	// api.defaults.baseURL = "...".
+	createClientRegex   = regexp.MustCompile(`(?:^|[^\w$.])([\w$]+)\s*=\s*axios\.create\s*\(\s*\{[^;{}]*$`)
+	defaultsClientRegex = regexp.MustCompile(`(?:^|[^\w$.])([\w$]+)\.defaults\.$`)
+)
+
+// baseExtractor reports the API bases of a script under "base", with the
+// client each configures as the sink: api for api = axios.create({baseURL:
+// "..."}), axios for axios.defaults.baseURL = "...", none for a constant.
+// With -r, the relative endpoints passed to that client are resolved
+// under the base sourceBases picks for it.
+type baseExtractor struct{}
+
+func (baseExtractor) Extract(source, contentType string, body []byte) []Finding {
+	content := string(body)
+	lines := newLineIndex(content)
+	seen := make(map[[2]string]struct{})
+	findings := make([]Finding, 0)
+	for _, m := range apiBaseRegex.FindAllStringSubmatchIndex(content, -1) {
+		value := content[m[2]:m[3]]
+		if strings.Contains(value, "${") || strings.Contains(value, "{{") {
+			continue
+		}
+		if value != "/" {
+			value = strings.TrimSuffix(value, "/")
+		}
+		if u, err := url.Parse(value); err != nil || (strings.HasPrefix(value, "//") && u.Host == "") {
+			continue
+		}
+		client := baseClient(content[max(0, m[0]-200):m[0]])
+		if _, ok := seen[[2]string{client, value}]; ok {
+			continue
+		}
+		seen[[2]string{client, value}] = struct{}{}
+		findings = append(findings, Finding{Source: source, Value: value, Category: categoryBase, Sink: client, Line: lines.line(m[2]), Offset: m[2]})
+	}
+	return findings
+}
+
+// baseClient names the client whose base is set by the key that before
+// leads up to, or returns "" for a plain constant.
+func baseClient(before string) string {
+	if m := createClientRegex.FindStringSubmatch(before); m != nil {
+		return m[1]
+	}
+	if m := defaultsClientRegex.FindStringSubmatch(before); m != nil {
+		return m[1]
+	}
+	return ""
+}
+
+// sourceBases returns the API base of each client a source's findings
+// configure. Bundles often carry the bases of vendored SDKs too, so when a
+// client has several the one on the source's own origin (or relative to
+// it) wins, and with no single such base, the client gets none.
+func sourceBases(source *url.URL, findings []Finding) map[string]string {
+	bases, own := make(map[string][]string), make(map[string][]string)
+	for _, f := range findings {
+		if f.Category != categoryBase || f.Sink == "" || f.Value == "/" {
+			continue
+		}
+		bases[f.Sink] = append(bases[f.Sink], f.Value)
+		if !strings.HasPrefix(f.Value, "//") && strings.HasPrefix(f.Value, "/") || sameOrigin(source, f.Value) {
+			own[f.Sink] = append(own[f.Sink], f.Value)
+		}
+	}
+	picked := make(map[string]string)
+	for client := range bases {
+		switch {
+		case len(bases[client]) == 1:
+			picked[client] = bases[client][0]
+		case len(own[client]) == 1:
+			picked[client] = own[client][0]
+		}
+	}
+	return picked
+}
+
+// withBase puts a relative endpoint under the base of the client it is
+// passed to: api for api("/x") and api.get("/x"). Endpoints given to fetch,
+// XMLHttpRequest or another client, or to no call at all, keep resolving
+// against the script.
+func withBase(bases map[string]string, f Finding) Finding {
+	if f.Category != categoryEndpoint || f.Sink == "" || !takesBase(f.Value) {
+		return f
+	}
+	base, ok := bases[f.Sink]
+	if i := strings.LastIndex(f.Sink, "."); !ok && i > 0 {
+		base, ok = bases[f.Sink[:i]]
+	}
+	if ok {
+		f.Value, f.Base = joinBase(base, f.Value), base
+	}
+	return f
+}
+
+// takesBase reports whether an endpoint is a relative API path that its
+// client would send to the base: not a URL, fragment or static asset.
+func takesBase(value string) bool {
+	if value == "" || strings.Contains(value, "://") || strings.HasPrefix(value, "//") || strings.HasPrefix(value, "#") || strings.HasPrefix(value, ".") {
+		return false
+	}
+	lower := strings.ToLower(value)
+	if i := strings.IndexAny(lower, "?#"); i >= 0 {
+		lower = lower[:i]
+	}
+	for _, suffix := range append(assetSuffixes, ".html", ".ico", ".woff", ".woff2", ".ttf", ".map", ".json") {
+		if strings.HasSuffix(lower, suffix) {
+			return false
+		}
+	}
+	return true
+}
+
+// joinBase puts value under base the way axios does, unless value already
+// starts with the base's path (/v2/users under https://api.x.com/v2).
+func joinBase(base, value string) string {
+	origin, basePath := "", base
+	if u, err := url.Parse(base); err == nil && u.Host != "" {
+		basePath = u.Path
+		origin = strings.TrimSuffix(base, u.Path)
+	}
+	if basePath != "" && basePath != "/" && (value == basePath || strings.HasPrefix(value, basePath+"/") || strings.HasPrefix(value, basePath+"?")) {
+		return origin + value
+	}
+	// path.Join would clean the query and the trailing slash; keep both.
+	rest, query := value, ""
+	if i := strings.IndexAny(value, "?#"); i >= 0 {
+		rest, query = value[:i], value[i:]
+	}
+	joined := path.Join("/", basePath, strings.TrimPrefix(rest, "/"))
+	if strings.HasSuffix(rest, "/") && joined != "/" {
+		joined += "/"
+	}
+	return origin + joined + query
+}
@@ -0,0 +1,57 @@
+package golinkfinder
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+func TestWithBase(t *testing.T) {
+	body := `const api = axios.create({ timeout: 5000, baseURL: "https://api.example.com/v2" });
+axios.defaults.baseURL = "/backend";
+const API_BASE_URL = "https://const.example.com";
+api.get("/users");
+api("/orders");
+axios.post("/login");
+fetch("/health");
+xhr.open("GET", "/xhr");
+const page = "/plain";
+api.get("/logo.png");
+`
+	sinks, err := newSinkMatcher(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rules, err := buildRules("", sinks)
+	if err != nil {
+		t.Fatal(err)
+	}
+	source := "https://example.com/app.js"
+	findings := extractAll([]Extractor{ruleExtractor{rules: rules}, baseExtractor{}}, source, "application/javascript", []byte(body))
+	u, _ := url.Parse(source)
+	bases := sourceBases(u, findings)
+	if want := map[string]string{"api": "https://api.example.com/v2", "axios": "/backend"}; !reflect.DeepEqual(bases, want) {
+		t.Errorf("sourceBases = %v, want %v", bases, want)
+	}
+
+	got := make(map[string]string)
+	for _, f := range findings {
+		if f.Category == categoryEndpoint {
+			f = withBase(bases, f)
+			got[f.Value] = f.Base
+		}
+	}
+	want := map[string]string{
+		"https://api.example.com/v2/users":  "https://api.example.com/v2",
+		"https://api.example.com/v2/orders": "https://api.example.com/v2",
+		"/backend/login":                    "/backend",
+		"/backend":                          "",
+		"/health":                           "",
+		"/xhr":                              "",
+		"/plain":                            "",
+		"/logo.png":                         "",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
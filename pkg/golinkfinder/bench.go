@@ -0,0 +1,219 @@
+package golinkfinder
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/pprof"
+	"sort"
+	"strings"
+	"time"
+)
+
+type benchFile struct {
+	path string
+	body []byte
+}
+
+type benchTiming struct {
+	name    string
+	elapsed time.Duration
+	matches int
+}
+
+// loadCorpus reads the given files, walking directories recursively.
+func loadCorpus(paths []string) ([]benchFile, int64, error) {
+	var files []benchFile
+	var total int64
+	for _, root := range paths {
+		err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() {
+				return err
+			}
+			body, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			files = append(files, benchFile{path: path, body: body})
+			total += int64(len(body))
+			return nil
+		})
+		if err != nil {
+			return nil, 0, err
+		}
+	}
+	return files, total, nil
+}
+
+func mbPerSec(bytes int64, elapsed time.Duration) float64 {
+	if elapsed <= 0 {
+		return 0
+	}
+	return float64(bytes) / (1 << 20) / elapsed.Seconds()
+}
+
+func ruleName(rule extractionRule) string {
+	pattern := rule.re.String()
+	if len(pattern) > 48 {
+		pattern = pattern[:45] + "..."
+	}
+	return rule.category + " " + pattern
+}
+
+func extractorName(e Extractor) string {
+	if p, ok := e.(*subprocessExtractor); ok {
+		return "plugin " + p.name
+	}
+	return strings.TrimPrefix(fmt.Sprintf("%T", e), "main.")
+}
+
+func runBench(args []string) {
+	o := defaultScanOptions()
+	var (
+		iterations int
+		cpuProfile string
+		memProfile string
+	)
+	fs := flag.NewFlagSet("bench", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: golinkfinder bench [flags] <file or directory>...\n")
+		fs.PrintDefaults()
+	}
+	fs.StringVar(&o.extract, "extract", "", "Also benchmark these optional rules: email, ip, host, secrets, or all.")
+	fs.Var(&o.plugins, "plugin", "External extractor command to include (repeatable).")
+	fs.IntVar(&iterations, "n", 3, "Number of passes over the corpus.")
+	fs.StringVar(&cpuProfile, "cpuprofile", "", "Write a pprof CPU profile of the full-pipeline passes to this file.")
+	fs.StringVar(&memProfile, "memprofile", "", "Write a pprof heap profile to this file after the run.")
+	fs.BoolVar(&o.noColor, "no-color", false, "Disable colorized output.")
+	fs.Parse(args)
+	initColors(o.noColor, "")
+
+	if fs.NArg() == 0 {
+		fs.Usage()
+		fatal(errors.New("no corpus files given"))
+	}
+	if iterations < 1 {
+		iterations = 1
+	}
+	files, total, err := loadCorpus(fs.Args())
+	if err != nil {
+		fatal(err)
+	}
+	sinks, err := newSinkMatcher(nil)
+	if err != nil {
+		fatal(err)
+	}
+	rules, err := buildRules(o.extract, sinks)
+	if err != nil {
+		fatal(err)
+	}
+	extractors := coreExtractors(&o, rules, cliOutput())
+	for _, command := range o.plugins {
+		plugin, err := startPlugin(command, o.pluginTimeout, cliOutput())
+		if err != nil {
+			fatal(err)
+		}
+		defer plugin.Close()
+		extractors = append(extractors, plugin)
+	}
+	fmt.Printf("%s[*] Corpus: %d file(s), %.2f MB, %d pass(es)%s\n", c.Yellow, len(files), float64(total)/(1<<20), iterations, c.End)
+
+	if cpuProfile != "" {
+		f, err := os.Create(cpuProfile)
+		if err != nil {
+			fatal(err)
+		}
+		defer f.Close()
+		if err := pprof.StartCPUProfile(f); err != nil {
+			fatal(err)
+		}
+	}
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+	findings := 0
+	start := time.Now()
+	for i := 0; i < iterations; i++ {
+		for _, file := range files {
+			findings += len(extractAll(extractors, file.path, "", file.body))
+		}
+	}
+	elapsed := time.Since(start)
+	runtime.ReadMemStats(&after)
+	if cpuProfile != "" {
+		pprof.StopCPUProfile()
+	}
+
+	perPass := elapsed / time.Duration(iterations)
+	fmt.Printf("\n%s[+] Full pipeline:%s %s per pass, %.2f MB/s, %d finding(s) per pass\n", c.Blue, c.End, perPass.Round(time.Microsecond), mbPerSec(total*int64(iterations), elapsed), findings/iterations)
+	fmt.Printf("    %d allocs and %.2f MB allocated per pass\n", (after.Mallocs-before.Mallocs)/uint64(iterations), float64(after.TotalAlloc-before.TotalAlloc)/(1<<20)/float64(iterations))
+
+	// Each rule on its own, against the same decoded text extract matches.
+	timings := make([]benchTiming, 0, len(rules)+len(extractors))
+	decoded := make([]string, len(files))
+	for i, file := range files {
+		decoded[i], _ = decodeEscapes(string(toUTF8(file.body, "")))
+	}
+	for _, rule := range rules {
+		t := benchTiming{name: ruleName(rule)}
+		start := time.Now()
+		for i := 0; i < iterations; i++ {
+			for _, content := range decoded {
+				t.matches += len(rule.re.FindAllStringSubmatchIndex(content, -1))
+			}
+		}
+		t.elapsed = time.Since(start) / time.Duration(iterations)
+		t.matches /= iterations
+		timings = append(timings, t)
+	}
+	for _, e := range extractors {
+		if _, ok := e.(ruleExtractor); ok {
+			continue
+		}
+		t := benchTiming{name: extractorName(e)}
+		start := time.Now()
+		for i := 0; i < iterations; i++ {
+			for _, file := range files {
+				t.matches += len(e.Extract(file.path, "", file.body))
+			}
+		}
+		t.elapsed = time.Since(start) / time.Duration(iterations)
+		t.matches /= iterations
+		timings = append(timings, t)
+	}
+	sort.Slice(timings, func(i, j int) bool { return timings[i].elapsed > timings[j].elapsed })
+
+	var sum time.Duration
+	for _, t := range timings {
+		sum += t.elapsed
+	}
+	fmt.Printf("\n%s[+] Per-rule timing (per pass, slowest first):%s\n", c.Blue, c.End)
+	fmt.Printf("  %12s %6s %10s %8s  %s\n", "time", "share", "MB/s", "matches", "rule")
+	for _, t := range timings {
+		share := 0.0
+		if sum > 0 {
+			share = 100 * float64(t.elapsed) / float64(sum)
+		}
+		color := ""
+		if share >= 50 && len(timings) > 2 {
+			color = c.Red
+		}
+		fmt.Printf("  %s%12s %5.1f%% %10.2f %8d  %s%s\n", color, t.elapsed.Round(time.Microsecond), share, mbPerSec(total, t.elapsed), t.matches, t.name, c.End)
+	}
+
+	if memProfile != "" {
+		f, err := os.Create(memProfile)
+		if err != nil {
+			fatal(err)
+		}
+		defer f.Close()
+		runtime.GC()
+		if err := pprof.WriteHeapProfile(f); err != nil {
+			fatal(err)
+		}
+	}
+}
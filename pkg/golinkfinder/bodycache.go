@@ -0,0 +1,70 @@
+package golinkfinder
+
+import (
+	"crypto/sha256"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// bodyCache remembers the findings of every body already extracted, keyed
+// by its SHA-256, so identical content served from several URLs (CDN
+// mirrors, versioned copies of a bundle) is only matched once.
+type bodyCache struct {
+	mu      sync.Mutex
+	entries map[[sha256.Size]byte][]Finding
+	// hits counts bodies whose findings were reused.
+	hits int
+}
+
+func newBodyCache() *bodyCache {
+	return &bodyCache{entries: make(map[[sha256.Size]byte][]Finding)}
+}
+
+// sourceDependent reports findings whose values were resolved against the
+// source they came from, such as chunk URLs or relative WebSocket
+// endpoints. Those differ per URL and can't be reused for another one.
+func sourceDependent(source string, findings []Finding) bool {
+	u, err := url.Parse(source)
+	if err != nil || u.Host == "" {
+		return false
+	}
+	for _, f := range findings {
+		if strings.Contains(f.Value, u.Host) {
+			return true
+		}
+	}
+	return false
+}
+
+// extract returns the findings for body, reusing those of an identical
+// body seen under another source with Source rewritten to this one.
+func (bc *bodyCache) extract(extractors []Extractor, source, contentType string, body []byte) []Finding {
+	key := sha256.Sum256(body)
+	bc.mu.Lock()
+	cached, ok := bc.entries[key]
+	if ok && cached != nil {
+		bc.hits++
+	}
+	bc.mu.Unlock()
+
+	if ok && cached != nil {
+		findings := make([]Finding, len(cached))
+		for i, f := range cached {
+			f.Source = source
+			findings[i] = f
+		}
+		return findings
+	}
+
+	findings := extractAll(extractors, source, contentType, body)
+	// A nil entry marks a body that has to be extracted per source.
+	var entry []Finding
+	if !sourceDependent(source, findings) {
+		entry = append([]Finding{}, findings...)
+	}
+	bc.mu.Lock()
+	bc.entries[key] = entry
+	bc.mu.Unlock()
+	return findings
+}
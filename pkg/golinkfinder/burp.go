@@ -0,0 +1,86 @@
+package golinkfinder
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// burpItems is Burp Suite's "Save items" XML export.
+type burpItems struct {
+	Items []struct {
+		URL      string `xml:"url"`
+		Method   string `xml:"method"`
+		Response struct {
+			Base64 bool   `xml:"base64,attr"`
+			Data   string `xml:",chardata"`
+		} `xml:"response"`
+	} `xml:"item"`
+}
+
+// burpJobs reads a Burp XML export and returns one job per item that has a
+// response, with the recorded body so nothing is fetched again.
+func burpJobs(path string) ([]scanJob, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read Burp export: %v", err)
+	}
+	var export burpItems
+	if err := xml.Unmarshal(data, &export); err != nil {
+		return nil, fmt.Errorf("invalid Burp export: %v", err)
+	}
+
+	jobs := make([]scanJob, 0, len(export.Items))
+	for _, item := range export.Items {
+		raw := []byte(item.Response.Data)
+		if item.Response.Base64 {
+			if raw, err = base64.StdEncoding.DecodeString(strings.TrimSpace(item.Response.Data)); err != nil {
+				return nil, fmt.Errorf("%s: invalid base64 response: %v", item.URL, err)
+			}
+		}
+		if len(raw) == 0 {
+			continue
+		}
+		body, contentType, err := parseRawResponse(raw)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", item.URL, err)
+		}
+		if len(body) == 0 {
+			continue
+		}
+		jobs = append(jobs, scanJob{url: item.URL, method: item.Method, contentType: contentType, body: body})
+	}
+	return jobs, nil
+}
+
+// parseRawResponse splits a recorded HTTP response into its decoded body
+// and content type, undoing chunked and gzip encoding.
+func parseRawResponse(raw []byte) ([]byte, string, error) {
+	resp, err := http.ReadResponse(bufio.NewReader(bytes.NewReader(raw)), nil)
+	if err != nil {
+		return nil, "", fmt.Errorf("invalid recorded response: %v", err)
+	}
+	defer resp.Body.Close()
+	var reader io.Reader = resp.Body
+	if strings.EqualFold(resp.Header.Get("Content-Encoding"), "gzip") {
+		gz, err := gzip.NewReader(resp.Body)
+		if err != nil {
+			return nil, "", fmt.Errorf("invalid gzip body: %v", err)
+		}
+		defer gz.Close()
+		reader = gz
+	}
+	// Recorded bodies are often cut short; keep what was captured.
+	body, err := io.ReadAll(reader)
+	if err != nil && err != io.ErrUnexpectedEOF && len(body) == 0 {
+		return nil, "", fmt.Errorf("could not read recorded body: %v", err)
+	}
+	return body, resp.Header.Get("Content-Type"), nil
+}
@@ -0,0 +1,50 @@
+package golinkfinder
+
+import (
+	"net/url"
+	"strings"
+)
+
+// canonicalURL normalizes a target URL the ways near-duplicates in
+// crawled and archived lists differ: scheme and host case, default ports,
+// an empty path, fragments and, with -strip-query, the query string.
+func canonicalURL(raw string, stripQuery bool) string {
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return raw
+	}
+	u.Host = strings.ToLower(u.Host)
+	if port := u.Port(); u.Scheme == "http" && port == "80" || u.Scheme == "https" && port == "443" {
+		u.Host = strings.TrimSuffix(u.Host, ":"+port)
+	}
+	if u.Path == "" {
+		u.Path, u.RawPath = "/", ""
+	}
+	u.Fragment, u.RawFragment = "", ""
+	if stripQuery {
+		u.RawQuery, u.ForceQuery = "", false
+	}
+	return u.String()
+}
+
+// canonicalJobs canonicalizes the URL of every fetched target.
+func canonicalJobs(jobs []scanJob, stripQuery bool) {
+	for i := range jobs {
+		if jobs[i].body == nil {
+			jobs[i].url = canonicalURL(jobs[i].url, stripQuery)
+		}
+	}
+}
+
+// slashless drops the trailing slash of a URL's path, so /app and /app/
+// count as the same target.
+func slashless(raw string) string {
+	path, query, hasQuery := strings.Cut(raw, "?")
+	if strings.HasSuffix(path, "/") && !strings.HasSuffix(path, "://") && strings.Count(path, "/") > 3 {
+		path = strings.TrimSuffix(path, "/")
+	}
+	if hasQuery {
+		return path + "?" + query
+	}
+	return path
+}
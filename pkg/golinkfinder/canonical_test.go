@@ -0,0 +1,30 @@
+package golinkfinder
+
+import "testing"
+
+func TestCanonicalURL(t *testing.T) {
+	tests := []struct {
+		raw        string
+		stripQuery bool
+		want       string
+	}{
+		{"https://Example.COM/app.js", false, "https://example.com/app.js"},
+		{"https://example.com:443/app.js", false, "https://example.com/app.js"},
+		{"http://example.com:80/app.js", false, "http://example.com/app.js"},
+		{"http://example.com:443/app.js", false, "http://example.com:443/app.js"},
+		{"https://example.com:8443/app.js", false, "https://example.com:8443/app.js"},
+		{"https://example.com", false, "https://example.com/"},
+		{"https://example.com/app.js#main", false, "https://example.com/app.js"},
+		{"https://example.com/app.js?v=1", false, "https://example.com/app.js?v=1"},
+		{"https://example.com/app.js?v=1", true, "https://example.com/app.js"},
+		{"https://example.com/a%20b.js", false, "https://example.com/a%20b.js"},
+		{"ftp://Example.com/file", false, "ftp://Example.com/file"},
+		{"file:///tmp/app.js", false, "file:///tmp/app.js"},
+		{"not a url", false, "not a url"},
+	}
+	for _, tt := range tests {
+		if got := canonicalURL(tt.raw, tt.stripQuery); got != tt.want {
+			t.Errorf("canonicalURL(%q, %v) = %q, want %q", tt.raw, tt.stripQuery, got, tt.want)
+		}
+	}
+}
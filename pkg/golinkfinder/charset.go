@@ -0,0 +1,20 @@
+package golinkfinder
+
+import (
+	"golang.org/x/net/html/charset"
+)
+
+// toUTF8 transcodes body to UTF-8 using the charset from the Content-Type
+// header, a BOM, or an HTML meta tag, in that order. Bodies that already are
+// (or look like) UTF-8 are returned unchanged.
+func toUTF8(body []byte, contentType string) []byte {
+	enc, name, _ := charset.DetermineEncoding(body, contentType)
+	if name == "utf-8" || enc == nil {
+		return body
+	}
+	decoded, err := enc.NewDecoder().Bytes(body)
+	if err != nil {
+		return body
+	}
+	return decoded
+}
@@ -0,0 +1,68 @@
+package golinkfinder
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// outputChecksums is the -checksums sidecar of a saved output: which scan
+// wrote it, when, and the SHA-256 of every file, so a report can later
+// show the evidence was not edited. The outputs themselves stay plain.
+type outputChecksums struct {
+	ScanID  string         `json:"scan_id"`
+	Version string         `json:"version"`
+	Date    time.Time      `json:"date"`
+	Targets int            `json:"targets"`
+	Files   []fileChecksum `json:"files"`
+}
+
+type fileChecksum struct {
+	Name   string `json:"name"`
+	Bytes  int64  `json:"bytes"`
+	SHA256 string `json:"sha256"`
+}
+
+func checksumFile(path string) (fileChecksum, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return fileChecksum{}, err
+	}
+	defer file.Close()
+	h := sha256.New()
+	n, err := io.Copy(h, file)
+	if err != nil {
+		return fileChecksum{}, err
+	}
+	return fileChecksum{Name: filepath.Base(path), Bytes: n, SHA256: hex.EncodeToString(h.Sum(nil))}, nil
+}
+
+// writeChecksums saves the checksums of files (relative to dir) to path.
+func writeChecksums(path, dir string, files []string, st *scanStats) error {
+	sums := outputChecksums{ScanID: st.ScanID, Version: st.Version, Date: time.Now().UTC(), Targets: st.Targets}
+	for _, name := range files {
+		sum, err := checksumFile(filepath.Join(dir, name))
+		if err != nil {
+			return fmt.Errorf("could not checksum '%s': %v", name, err)
+		}
+		sum.Name = name
+		sums.Files = append(sums.Files, sum)
+	}
+	data, err := json.MarshalIndent(sums, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+// checksumSidecar writes <path>.sha256.json next to a single output file.
+func checksumSidecar(path string, st *scanStats) {
+	if err := writeChecksums(path+".sha256.json", filepath.Dir(path), []string{filepath.Base(path)}, st); err != nil {
+		fmt.Fprintf(os.Stderr, "%s[!] Error writing checksums: %v%s\n", c.Red, err, c.End)
+	}
+}
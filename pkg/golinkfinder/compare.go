@@ -0,0 +1,398 @@
+package golinkfinder
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+// runEntry is one value of a stored run, whatever file it came from.
+type runEntry struct {
+	Value    string
+	Category string
+	Note     string
+	Context  string
+	// Sources names the sources when the file lists them; Count is their
+	// number either way.
+	Sources map[string]struct{}
+	Count   int
+}
+
+// loadRun reads the results of a past run: an -o .json list (entries or
+// groups), -jsonl findings, or a plain list with one value per line.
+func loadRun(path string) (map[string]*runEntry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read run: %v", err)
+	}
+	run := make(map[string]*runEntry)
+	add := func(value, category, note, context, source string, count int) {
+		e, ok := run[value]
+		if !ok {
+			e = &runEntry{Value: value, Sources: make(map[string]struct{})}
+			run[value] = e
+		}
+		if e.Category == "" {
+			e.Category = category
+		}
+		if e.Note == "" {
+			e.Note = note
+		}
+		if e.Context == "" {
+			e.Context = context
+		}
+		if source != "" {
+			e.Sources[source] = struct{}{}
+		}
+		if len(e.Sources) > e.Count {
+			e.Count = len(e.Sources)
+		}
+		if count > e.Count {
+			e.Count = count
+		}
+	}
+	addEntry := func(le listEntry) {
+		if len(le.Refs) == 0 {
+			add(le.Value, le.Category, "", "", "", le.Sources)
+		}
+		for _, ref := range le.Refs {
+			add(le.Value, le.Category, "", "", ref.Source, le.Sources)
+		}
+	}
+
+	trimmed := bytes.TrimSpace(data)
+	if bytes.HasPrefix(trimmed, []byte("[")) {
+		var items []json.RawMessage
+		if err := json.Unmarshal(trimmed, &items); err != nil {
+			return nil, fmt.Errorf("could not parse %s: %v", path, err)
+		}
+		for _, item := range items {
+			var g listGroup
+			if err := json.Unmarshal(item, &g); err == nil && g.Entries != nil {
+				for _, le := range g.Entries {
+					addEntry(le)
+				}
+				continue
+			}
+			var le listEntry
+			if err := json.Unmarshal(item, &le); err != nil {
+				return nil, fmt.Errorf("could not parse %s: %v", path, err)
+			}
+			addEntry(le)
+		}
+		return run, nil
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 64*1024), 16<<20)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		if !strings.HasPrefix(line, "{") {
+			add(line, "", "", "", "", 0)
+			continue
+		}
+		var rec formatRecord
+		if err := json.Unmarshal([]byte(line), &rec); err != nil {
+			return nil, fmt.Errorf("%s:%d: %v", path, n, err)
+		}
+		if rec.Endpoint != "" {
+			add(rec.Endpoint, rec.Category, rec.Note, rec.Context, rec.Source, 0)
+		}
+	}
+	return run, scanner.Err()
+}
+
+type compareItem struct {
+	Value    string `json:"value"`
+	Category string `json:"category,omitempty"`
+	Note     string `json:"note,omitempty"`
+	Context  string `json:"context,omitempty"`
+	Sources  int    `json:"sources,omitempty"`
+}
+
+type compareChange struct {
+	Value   string   `json:"value"`
+	Changes []string `json:"changes"`
+}
+
+type compareSection struct {
+	Added   []compareItem   `json:"added"`
+	Removed []compareItem   `json:"removed"`
+	Changed []compareChange `json:"changed"`
+}
+
+// summaryRow counts one thing in both runs.
+type summaryRow struct {
+	Name string `json:"name"`
+	Old  int    `json:"old"`
+	New  int    `json:"new"`
+}
+
+// compareSummary sets the two runs side by side: their totals, values per
+// category and the hosts named most in the new run.
+type compareSummary struct {
+	Totals     []summaryRow `json:"totals"`
+	Categories []summaryRow `json:"categories"`
+	Hosts      []summaryRow `json:"hosts"`
+}
+
+// compareReport splits the differences between two runs into endpoints
+// and secrets (secret, jwt and backend findings).
+type compareReport struct {
+	Old       string         `json:"old"`
+	New       string         `json:"new"`
+	Summary   compareSummary `json:"summary"`
+	Endpoints compareSection `json:"endpoints"`
+	Secrets   compareSection `json:"secrets"`
+}
+
+func secretLike(category string) bool {
+	return category == categorySecret || category == categoryJWT || category == categoryBackend
+}
+
+func compareRuns(oldPath, newPath string, oldRun, newRun map[string]*runEntry) *compareReport {
+	r := &compareReport{Old: oldPath, New: newPath}
+	for _, s := range []*compareSection{&r.Endpoints, &r.Secrets} {
+		s.Added, s.Removed, s.Changed = []compareItem{}, []compareItem{}, []compareChange{}
+	}
+	section := func(e *runEntry) *compareSection {
+		if secretLike(e.Category) {
+			return &r.Secrets
+		}
+		return &r.Endpoints
+	}
+	item := func(e *runEntry) compareItem {
+		return compareItem{Value: e.Value, Category: e.Category, Note: e.Note, Context: e.Context, Sources: e.Count}
+	}
+	for value, n := range newRun {
+		o, ok := oldRun[value]
+		if !ok {
+			s := section(n)
+			s.Added = append(s.Added, item(n))
+			continue
+		}
+		if changes := entryChanges(o, n); len(changes) > 0 {
+			s := section(n)
+			s.Changed = append(s.Changed, compareChange{Value: value, Changes: changes})
+		}
+	}
+	for value, o := range oldRun {
+		if _, ok := newRun[value]; !ok {
+			s := section(o)
+			s.Removed = append(s.Removed, item(o))
+		}
+	}
+	r.Summary = summarizeRuns(oldRun, newRun)
+	for _, s := range []*compareSection{&r.Endpoints, &r.Secrets} {
+		sort.Slice(s.Added, func(i, j int) bool { return s.Added[i].Value < s.Added[j].Value })
+		sort.Slice(s.Removed, func(i, j int) bool { return s.Removed[i].Value < s.Removed[j].Value })
+		sort.Slice(s.Changed, func(i, j int) bool { return s.Changed[i].Value < s.Changed[j].Value })
+	}
+	return r
+}
+
+// summarizeRuns counts the values, sources, categories and hosts of both
+// runs. Plain lists have no categories; their values count as endpoints.
+func summarizeRuns(oldRun, newRun map[string]*runEntry) compareSummary {
+	type counts struct {
+		values     int
+		sources    map[string]struct{}
+		categories map[string]int
+		hosts      map[string]int
+	}
+	count := func(run map[string]*runEntry) counts {
+		c := counts{values: len(run), sources: make(map[string]struct{}), categories: make(map[string]int), hosts: make(map[string]int)}
+		for _, e := range run {
+			for s := range e.Sources {
+				c.sources[s] = struct{}{}
+			}
+			category := e.Category
+			if category == "" {
+				category = categoryEndpoint
+			}
+			c.categories[category]++
+			if host := findingHost(Finding{Value: e.Value, Category: category}); host != "" {
+				c.hosts[host]++
+			}
+		}
+		return c
+	}
+	o, n := count(oldRun), count(newRun)
+	sum := compareSummary{Totals: []summaryRow{{"values", o.values, n.values}, {"sources", len(o.sources), len(n.sources)}}}
+
+	categories := make(map[string]int)
+	for k, v := range o.categories {
+		categories[k] += v
+	}
+	for k, v := range n.categories {
+		categories[k] += v
+	}
+	for _, e := range sortedCounts(categories) {
+		sum.Categories = append(sum.Categories, summaryRow{e.key, o.categories[e.key], n.categories[e.key]})
+	}
+	for i, e := range sortedCounts(n.hosts) {
+		if i == summaryTopHosts {
+			break
+		}
+		sum.Hosts = append(sum.Hosts, summaryRow{e.key, o.hosts[e.key], e.count})
+	}
+	return sum
+}
+
+// entryChanges describes how a value present in both runs differs: its
+// category, its note (a secret turning inactive, say) or its sources.
+func entryChanges(o, n *runEntry) []string {
+	var changes []string
+	if o.Category != "" && n.Category != "" && o.Category != n.Category {
+		changes = append(changes, fmt.Sprintf("category %s -> %s", o.Category, n.Category))
+	}
+	if o.Note != n.Note && o.Note != "" && n.Note != "" {
+		changes = append(changes, fmt.Sprintf("note %q -> %q", o.Note, n.Note))
+	}
+	if len(o.Sources) > 0 && len(n.Sources) > 0 {
+		added, removed := missingFrom(n.Sources, o.Sources), missingFrom(o.Sources, n.Sources)
+		if len(added) > 0 || len(removed) > 0 {
+			change := fmt.Sprintf("sources %d -> %d", o.Count, n.Count)
+			for _, s := range added {
+				change += ", +" + s
+			}
+			for _, s := range removed {
+				change += ", -" + s
+			}
+			changes = append(changes, change)
+		}
+	} else if o.Count != n.Count && o.Count > 0 && n.Count > 0 {
+		changes = append(changes, fmt.Sprintf("sources %d -> %d", o.Count, n.Count))
+	}
+	return changes
+}
+
+func (r *compareReport) writeText(w io.Writer) {
+	fmt.Fprintf(w, "%s%s[*] Comparing %s -> %s%s%s\n", c.Bold, c.Yellow, r.Old, r.New, c.End, c.End)
+	fmt.Fprintf(w, "\n%s[+] Summary (old -> new):%s\n", c.Blue, c.End)
+	for _, group := range [][]summaryRow{r.Summary.Totals, r.Summary.Categories, r.Summary.Hosts} {
+		for _, row := range group {
+			fmt.Fprintf(w, "  %-30s %d -> %d\n", row.Name, row.Old, row.New)
+		}
+	}
+	for _, part := range []struct {
+		name string
+		s    compareSection
+	}{{"Endpoints", r.Endpoints}, {"Secrets", r.Secrets}} {
+		s := part.s
+		fmt.Fprintf(w, "\n%s[+] %s: %d added, %d removed, %d changed%s\n", c.Blue, part.name, len(s.Added), len(s.Removed), len(s.Changed), c.End)
+		for _, it := range s.Added {
+			fmt.Fprintf(w, "%s+ %s%s%s\n", c.Green, it.Value, itemNote(it), c.End)
+		}
+		for _, it := range s.Removed {
+			fmt.Fprintf(w, "%s- %s%s%s\n", c.Red, it.Value, itemNote(it), c.End)
+		}
+		for _, ch := range s.Changed {
+			fmt.Fprintf(w, "%s~ %s%s (%s)\n", c.Yellow, ch.Value, c.End, strings.Join(ch.Changes, "; "))
+		}
+	}
+}
+
+func itemNote(it compareItem) string {
+	var parts []string
+	if it.Category != "" && it.Category != categoryEndpoint {
+		parts = append(parts, it.Category)
+	}
+	if it.Note != "" {
+		parts = append(parts, it.Note)
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return "  (" + strings.Join(parts, ", ") + ")"
+}
+
+var compareHTML = template.Must(template.New("compare").Parse(`<!DOCTYPE html>
+<html><head><meta charset="utf-8"><title>golinkfinder: {{.Old}} vs {{.New}}</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; margin-bottom: 1.5em; }
+td, th { border: 1px solid #ccc; padding: 2px 8px; text-align: left; font-family: monospace; }
+.context { color: #666; white-space: pre-wrap; }
+.added { background: #e6ffed; } .removed { background: #ffeef0; } .changed { background: #fffbdd; }
+</style></head><body>
+<h1>{{.Old}} &rarr; {{.New}}</h1>
+{{define "rows"}}{{range .}}<tr><td>{{.Name}}</td><td>{{.Old}}</td><td>{{.New}}</td></tr>
+{{end}}{{end}}
+<h2>Summary</h2>
+<table>
+<tr><th></th><th>Old</th><th>New</th></tr>
+{{template "rows" .Summary.Totals}}<tr><th colspan="3">By category</th></tr>
+{{template "rows" .Summary.Categories}}{{if .Summary.Hosts}}<tr><th colspan="3">Top hosts</th></tr>
+{{template "rows" .Summary.Hosts}}{{end}}</table>
+{{define "section"}}<table>
+<tr><th></th><th>Value</th><th>Category</th><th>Details</th></tr>
+{{range .Added}}<tr class="added"><td>+</td><td>{{.Value}}</td><td>{{.Category}}</td><td>{{.Note}}{{if .Context}}<br><code class="context">{{.Context}}</code>{{end}}</td></tr>
+{{end}}{{range .Removed}}<tr class="removed"><td>-</td><td>{{.Value}}</td><td>{{.Category}}</td><td>{{.Note}}{{if .Context}}<br><code class="context">{{.Context}}</code>{{end}}</td></tr>
+{{end}}{{range .Changed}}<tr class="changed"><td>~</td><td>{{.Value}}</td><td></td><td>{{range .Changes}}{{.}}<br>{{end}}</td></tr>
+{{end}}</table>{{end}}
+<h2>Endpoints ({{len .Endpoints.Added}} added, {{len .Endpoints.Removed}} removed, {{len .Endpoints.Changed}} changed)</h2>
+{{template "section" .Endpoints}}
+<h2>Secrets ({{len .Secrets.Added}} added, {{len .Secrets.Removed}} removed, {{len .Secrets.Changed}} changed)</h2>
+{{template "section" .Secrets}}
+</body></html>
+`))
+
+// runCompare implements report -compare: the differences between two
+// stored runs, as text, JSON or HTML.
+func runCompare(oldPath, newPath, format, output string, ignore *ignoreList) {
+	if format != "text" && format != "json" && format != "html" {
+		fatal(fmt.Errorf("unknown -format '%s' (valid: text, json, html)", format))
+	}
+	oldRun, err := loadRun(oldPath)
+	if err != nil {
+		fatal(err)
+	}
+	newRun, err := loadRun(newPath)
+	if err != nil {
+		fatal(err)
+	}
+	for _, run := range []map[string]*runEntry{oldRun, newRun} {
+		for value, e := range run {
+			if ignore.match(e.Category, value) != nil {
+				delete(run, value)
+			}
+		}
+	}
+	report := compareRuns(oldPath, newPath, oldRun, newRun)
+
+	var w io.Writer = os.Stdout
+	if output != "" {
+		file, err := os.Create(output)
+		if err != nil {
+			fatal(fmt.Errorf("could not create report: %v", err))
+		}
+		defer file.Close()
+		w = file
+		if format == "text" {
+			initColors(true, "")
+		}
+	}
+	switch format {
+	case "text":
+		report.writeText(w)
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		err = enc.Encode(report)
+	case "html":
+		err = compareHTML.Execute(w, report)
+	}
+	if err != nil {
+		fatal(err)
+	}
+}
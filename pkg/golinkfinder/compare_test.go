@@ -0,0 +1,137 @@
+package golinkfinder
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeRun(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadRun(t *testing.T) {
+	tests := []struct {
+		name, content string
+		want          map[string]string
+	}{
+		{"plain.txt", "/api/a\n\n/api/b\n", map[string]string{
+			"/api/a": " 0",
+			"/api/b": " 0",
+		}},
+		{"list.json", `[{"value":"/api/a","category":"endpoint","sources":2,"refs":[{"source":"https://x/1.js","offset":0},{"source":"https://x/2.js","offset":5}]},{"value":"AKIA","category":"secret","sources":1}]`, map[string]string{
+			"/api/a": "endpoint 2",
+			"AKIA":   "secret 1",
+		}},
+		{"groups.json", `[{"group":"x","endpoints":[{"value":"/api/a","category":"endpoint"}]},{"group":"y","endpoints":[{"value":"/api/b"}]}]`, map[string]string{
+			"/api/a": "endpoint 0",
+			"/api/b": " 0",
+		}},
+		{"run.jsonl", "{\"source\":\"https://x/1.js\",\"endpoint\":\"/api/a\",\"category\":\"endpoint\"}\n{\"source\":\"https://x/2.js\",\"endpoint\":\"/api/a\",\"category\":\"endpoint\"}\n{\"source\":\"https://x/1.js\",\"endpoint\":\"tok\",\"category\":\"jwt\",\"note\":\"alg HS256\"}\n", map[string]string{
+			"/api/a": "endpoint 2",
+			"tok":    "jwt 1",
+		}},
+	}
+	for _, tt := range tests {
+		run, err := loadRun(writeRun(t, tt.name, tt.content))
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		got := map[string]string{}
+		for value, e := range run {
+			got[value] = fmt.Sprintf("%s %d", e.Category, e.Count)
+		}
+		if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	if _, err := loadRun(writeRun(t, "bad.jsonl", "/api/a\n{oops\n")); err == nil || !strings.Contains(err.Error(), ":2:") {
+		t.Errorf("a broken line is reported as %v, want its line number", err)
+	}
+}
+
+func TestCompareRuns(t *testing.T) {
+	oldRun, err := loadRun(writeRun(t, "old.jsonl", strings.Join([]string{
+		`{"source":"https://x/1.js","endpoint":"/api/kept","category":"endpoint"}`,
+		`{"source":"https://x/1.js","endpoint":"/api/gone","category":"endpoint"}`,
+		`{"source":"https://x/1.js","endpoint":"/api/moved","category":"endpoint"}`,
+		`{"source":"https://x/1.js","endpoint":"AKIAOLD","category":"secret","note":"aws (active)"}`,
+		`{"source":"https://x/1.js","endpoint":"AKIAKEPT","category":"secret","note":"aws (active)"}`,
+	}, "\n")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	newRun, err := loadRun(writeRun(t, "new.jsonl", strings.Join([]string{
+		`{"source":"https://x/1.js","endpoint":"/api/kept","category":"endpoint"}`,
+		`{"source":"https://x/2.js","endpoint":"/api/moved","category":"endpoint"}`,
+		`{"source":"https://x/1.js","endpoint":"/api/new","category":"endpoint"}`,
+		`{"source":"https://x/1.js","endpoint":"AKIAKEPT","category":"secret","note":"aws (inactive)"}`,
+		`{"source":"https://x/1.js","endpoint":"AKIANEW","category":"secret","note":"aws (active)"}`,
+	}, "\n")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := compareRuns("old", "new", oldRun, newRun)
+
+	values := func(items []compareItem) []string {
+		var out []string
+		for _, it := range items {
+			out = append(out, it.Value)
+		}
+		return out
+	}
+	changes := func(items []compareChange) []string {
+		var out []string
+		for _, ch := range items {
+			out = append(out, ch.Value+": "+strings.Join(ch.Changes, "; "))
+		}
+		return out
+	}
+	checks := []struct {
+		name string
+		got  []string
+		want []string
+	}{
+		{"added endpoints", values(r.Endpoints.Added), []string{"/api/new"}},
+		{"removed endpoints", values(r.Endpoints.Removed), []string{"/api/gone"}},
+		{"changed endpoints", changes(r.Endpoints.Changed), []string{"/api/moved: sources 1 -> 1, +https://x/2.js, -https://x/1.js"}},
+		{"added secrets", values(r.Secrets.Added), []string{"AKIANEW"}},
+		{"removed secrets", values(r.Secrets.Removed), []string{"AKIAOLD"}},
+		{"changed secrets", changes(r.Secrets.Changed), []string{`AKIAKEPT: note "aws (active)" -> "aws (inactive)"`}},
+	}
+	for _, check := range checks {
+		if fmt.Sprint(check.got) != fmt.Sprint(check.want) {
+			t.Errorf("%s: got %q, want %q", check.name, check.got, check.want)
+		}
+	}
+	if got := fmt.Sprint(r.Summary.Totals); got != "[{values 5 5} {sources 1 2}]" {
+		t.Errorf("totals %s", got)
+	}
+
+	defer func(colors Colors) { c = colors }(c)
+	c = Colors{}
+	var text bytes.Buffer
+	r.writeText(&text)
+	for _, line := range []string{"+ /api/new\n", "- /api/gone\n", "~ /api/moved (sources", "+ AKIANEW  (secret, aws (active))\n", "[+] Secrets: 1 added, 1 removed, 1 changed"} {
+		if !strings.Contains(text.String(), line) {
+			t.Errorf("text report lacks %q:\n%s", line, text.String())
+		}
+	}
+	var html bytes.Buffer
+	if err := compareHTML.Execute(&html, r); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(html.String(), `<tr class="removed"><td>-</td><td>AKIAOLD</td>`) {
+		t.Errorf("HTML report lacks the removed secret:\n%s", html.String())
+	}
+}
@@ -0,0 +1,93 @@
+package golinkfinder
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// confidenceLevels are the confidence values of a finding, lowest first.
+var confidenceLevels = []string{"low", "medium", "high"}
+
+var (
+	// requestContextRegex matches code that sends a request just before a
+	// value: fetch(, axios.get(, $.ajax({url:, xhr.open("GET", and href=.
+	requestContextRegex = regexp.MustCompile(`(?i)(?:\bfetch|\baxios(?:\.\w+)?|\.(?:get|post|put|patch|delete|head|request|ajax|open)|\$\.\w+|\burl\s*[:=]|\b(?:href|src|action)\s*=|\bendpoint\s*[:=]|\bpath\s*[:=])\s*\(?\s*(?:["'` + "`" + `]\w+["'` + "`" + `]\s*,\s*)?\{?\s*(?:\w+\s*:\s*)?["'` + "`" + `]?$`)
+	apiPathRegex        = regexp.MustCompile(`(?i)(?:^|/)(?:api|graphql|rest|rpc|v\d+|oauth2?|auth|admin|internal|services?)(?:/|$|\?)`)
+	// noiseRegex matches "/" values that are rarely paths: regex or date
+	// fragments, single characters, repeated slashes.
+	noiseRegex = regexp.MustCompile(`^/(?:[^a-zA-Z0-9]*|[a-zA-Z0-9]|.*//.*|.*\(.*\).*)$`)
+)
+
+func confidenceRank(level string) int {
+	for i, l := range confidenceLevels {
+		if l == level {
+			return i
+		}
+	}
+	return -1
+}
+
+func checkConfidence(level string) error {
+	if level != "" && confidenceRank(level) < 0 {
+		return fmt.Errorf("unknown -min-confidence '%s' (valid: %s)", level, strings.Join(confidenceLevels, ", "))
+	}
+	return nil
+}
+
+// scoreConfidence sets the confidence of every finding that has none,
+// from the rule that found it and the code around it.
+func scoreConfidence(body []byte, findings []Finding) {
+	for i := range findings {
+		if findings[i].Confidence == "" {
+			findings[i].Confidence = confidenceOf(body, findings[i])
+		}
+	}
+}
+
+func confidenceOf(body []byte, f Finding) string {
+	switch f.Category {
+	case categorySpec, categoryGRPC, categoryChunk, categoryModule, categoryFeed, categoryRoute, categoryBase, categoryEnvEndpoint, categorySecret, categoryJWT, categoryBackend, categoryRealtime:
+		// Structural extractors and prefixed tokens rarely misfire.
+		return "high"
+	case categoryEndpoint:
+	default:
+		return "medium"
+	}
+	value := f.Value
+	if f.Sink != "" || strings.Contains(value, "://") {
+		return "high"
+	}
+	if noiseRegex.MatchString(value) {
+		return "low"
+	}
+	if f.Offset > 0 && f.Offset <= len(body) {
+		start := f.Offset - 80
+		if start < 0 {
+			start = 0
+		}
+		// The value's opening quote is the last byte before it.
+		if requestContextRegex.Match(body[start : f.Offset-1]) {
+			return "high"
+		}
+	}
+	if apiPathRegex.MatchString(value) {
+		return "high"
+	}
+	return "medium"
+}
+
+// filterConfidence drops the findings below min.
+func filterConfidence(findings []Finding, min string) []Finding {
+	if min == "" {
+		return findings
+	}
+	rank := confidenceRank(min)
+	kept := make([]Finding, 0, len(findings))
+	for _, f := range findings {
+		if confidenceRank(f.Confidence) >= rank {
+			kept = append(kept, f)
+		}
+	}
+	return kept
+}
@@ -0,0 +1,60 @@
+package golinkfinder
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// fileConfig is the optional YAML configuration file. It is looked up at
+// $XDG_CONFIG_HOME/golinkfinder/config.yaml unless -config points elsewhere.
+type fileConfig struct {
+	// Profiles adds or overrides -profile presets. Each maps flag names to
+	// the values the profile sets, e.g. {"t": "5", "rate": "2"}.
+	Profiles map[string]map[string]string `yaml:"profiles"`
+	// Rules are custom extraction rules, run in addition to the built-in
+	// ones under -rule-timeout.
+	Rules []userRule `yaml:"rules"`
+	// Login is a request run before the scan whose cookies and token are
+	// sent with the scan's requests.
+	Login *loginConfig `yaml:"login"`
+	// RulePack is where 'golinkfinder rules update' gets the community
+	// rule pack from.
+	RulePack *rulePackConfig `yaml:"rule_pack"`
+}
+
+func defaultConfigPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "golinkfinder", "config.yaml")
+}
+
+// loadConfig reads path, or the default location when path is empty. A
+// missing default file is not an error; a missing explicit one is.
+func loadConfig(path string) (*fileConfig, error) {
+	explicit := path != ""
+	if !explicit {
+		path = defaultConfigPath()
+	}
+	cfg := &fileConfig{}
+	if path == "" {
+		return cfg, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if !explicit && errors.Is(err, fs.ErrNotExist) {
+			return cfg, nil
+		}
+		return nil, fmt.Errorf("could not read config file: %v", err)
+	}
+	if err := yaml.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("could not parse config file '%s': %v", path, err)
+	}
+	return cfg, nil
+}
@@ -0,0 +1,11 @@
+//go:build !windows
+
+package golinkfinder
+
+import "os"
+
+// enableVirtualTerminal is a no-op outside Windows, where terminals
+// interpret ANSI escapes already.
+func enableVirtualTerminal(f *os.File) bool {
+	return true
+}
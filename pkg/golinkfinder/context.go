@@ -0,0 +1,51 @@
+package golinkfinder
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// signalContext is cancelled by Ctrl-C or SIGTERM, so a run stops sending
+// requests and still reports what it found. A second signal kills the
+// process as usual.
+func signalContext() (context.Context, context.CancelFunc) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+	return ctx, stop
+}
+
+// withTimeout bounds ctx by d; 0 means no limit.
+func withTimeout(ctx context.Context, d time.Duration) (context.Context, context.CancelFunc) {
+	if d <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, d)
+}
+
+// sleep waits d or until ctx is done, returning ctx's error in that case.
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// aborted reports errors caused by cancellation or a phase timeout rather
+// than by the target. Callers report those as ctx.Err() itself; a wrapped
+// deadline is a request that timed out (-timeout), which is the target's.
+func aborted(err error) bool {
+	return err == context.Canceled || err == context.DeadlineExceeded
+}
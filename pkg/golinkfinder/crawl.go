@@ -0,0 +1,74 @@
+package golinkfinder
+
+import (
+	"net/url"
+	"path"
+	"strings"
+)
+
+// crawlExtensions are the link targets the crawler fetches: pages, scripts
+// and JSON. Anything else (images, styles, fonts, archives) is only listed.
+var crawlExtensions = map[string]bool{
+	"": true, ".html": true, ".htm": true, ".xhtml": true, ".php": true, ".asp": true, ".aspx": true,
+	".jsp": true, ".do": true, ".action": true, ".cfm": true, ".js": true, ".mjs": true, ".json": true,
+}
+
+// crawlScope is the set of origins seeded on the command line; the crawler
+// never leaves them.
+type crawlScope map[string]struct{}
+
+func newCrawlScope(jobs []scanJob) crawlScope {
+	scope := make(crawlScope)
+	for _, job := range jobs {
+		if u, err := url.Parse(job.url); err == nil && u.Host != "" {
+			scope[u.Scheme+"://"+u.Host] = struct{}{}
+		}
+	}
+	return scope
+}
+
+// crawlTarget resolves a link found in base and returns the URL to queue,
+// without its fragment, if it stays in scope and points at something the
+// crawler fetches.
+func (scope crawlScope) crawlTarget(base *url.URL, link string) (string, bool) {
+	if base == nil {
+		return "", false
+	}
+	ref, err := url.Parse(strings.TrimSpace(link))
+	if err != nil {
+		return "", false
+	}
+	u := base.ResolveReference(ref)
+	u.Fragment = ""
+	if _, ok := scope[u.Scheme+"://"+u.Host]; !ok {
+		return "", false
+	}
+	if !crawlExtensions[strings.ToLower(path.Ext(u.Path))] {
+		return "", false
+	}
+	return u.String(), true
+}
+
+// sameOrigin reports whether raw has the scheme, host and port of base.
+func sameOrigin(base *url.URL, raw string) bool {
+	u, err := url.Parse(raw)
+	if err != nil || base == nil {
+		return false
+	}
+	return strings.EqualFold(u.Scheme, base.Scheme) && originHost(u) == originHost(base)
+}
+
+// originHost is the lower-cased host:port of u, with the default port of
+// its scheme filled in.
+func originHost(u *url.URL) string {
+	port := u.Port()
+	if port == "" {
+		switch strings.ToLower(u.Scheme) {
+		case "http":
+			port = "80"
+		case "https":
+			port = "443"
+		}
+	}
+	return strings.ToLower(u.Hostname()) + ":" + port
+}
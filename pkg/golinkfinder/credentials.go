@@ -0,0 +1,232 @@
+package golinkfinder
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// credentialStore hands out the Authorization header for a host, so secrets
+// can come from the environment or ~/.netrc instead of the command line:
+//
+//   - GLF_AUTH_HOST_<HOST>, the host upper-cased with every other character
+//     as '_' (API_EXAMPLE_COM_8443, then API_EXAMPLE_COM): "user:pass" for
+//     basic auth or a full header value such as "Bearer xyz"
+//   - a ~/.netrc (or $NETRC) machine entry for the host
+//   - -auth user:pass (default $GLF_AUTH) and the netrc default entry, for
+//     the hosts of the targets only, so they never reach third parties
+type credentialStore struct {
+	netrc    map[string]string
+	fallback string
+	basic    string
+
+	mu    sync.RWMutex
+	seeds map[string]struct{}
+}
+
+func basicAuth(userPass string) string {
+	return "Basic " + base64.StdEncoding.EncodeToString([]byte(userPass))
+}
+
+// authValue turns a credential into a header value: "user:pass" becomes
+// basic auth, anything with a space is used as it is.
+func authValue(credential string) string {
+	if strings.Contains(credential, " ") {
+		return credential
+	}
+	return basicAuth(credential)
+}
+
+func netrcPath(path string) string {
+	if path != "" {
+		return path
+	}
+	if env := os.Getenv("NETRC"); env != "" {
+		return env
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".netrc")
+}
+
+// parseNetrc returns the basic-auth header of each machine and of the
+// default entry.
+func parseNetrc(data string) (map[string]string, string) {
+	machines := make(map[string]string)
+	fallback := ""
+	var machine, login, password string
+	inEntry, isDefault := false, false
+	flush := func() {
+		if inEntry && login != "" {
+			if isDefault {
+				fallback = basicAuth(login + ":" + password)
+			} else if _, ok := machines[machine]; !ok {
+				machines[machine] = basicAuth(login + ":" + password)
+			}
+		}
+		machine, login, password = "", "", ""
+		inEntry, isDefault = false, false
+	}
+
+	lines := strings.Split(data, "\n")
+	for i := 0; i < len(lines); i++ {
+		fields := strings.Fields(lines[i])
+		for j := 0; j < len(fields); j++ {
+			next := func() string {
+				if j+1 < len(fields) {
+					j++
+					return fields[j]
+				}
+				return ""
+			}
+			switch fields[j] {
+			case "machine":
+				flush()
+				inEntry, machine = true, next()
+			case "default":
+				flush()
+				inEntry, isDefault = true, true
+			case "login":
+				login = next()
+			case "password":
+				password = next()
+			case "account":
+				next()
+			case "macdef":
+				// A macro runs until the next empty line.
+				flush()
+				for i++; i < len(lines) && strings.TrimSpace(lines[i]) != ""; i++ {
+				}
+				j = len(fields)
+			}
+		}
+	}
+	flush()
+	return machines, fallback
+}
+
+func loadCredentials(o *scanOptions) (*credentialStore, error) {
+	store := &credentialStore{basic: o.auth, seeds: make(map[string]struct{})}
+	if o.auth != "" && !strings.Contains(o.auth, ":") {
+		return nil, fmt.Errorf("-auth must be user:pass")
+	}
+	path := netrcPath(o.netrcFile)
+	if path == "" {
+		return store, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) && o.netrcFile == "" {
+			return store, nil
+		}
+		return nil, fmt.Errorf("could not read netrc file: %v", err)
+	}
+	store.netrc, store.fallback = parseNetrc(string(data))
+	return store, nil
+}
+
+// trust marks the hosts of jobs as targets, which also receive -auth and
+// the netrc default entry.
+func (cs *credentialStore) trust(jobs []scanJob) {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	for _, job := range jobs {
+		if u, err := url.Parse(job.url); err == nil && u.Host != "" {
+			cs.seeds[strings.ToLower(u.Host)] = struct{}{}
+		}
+	}
+}
+
+// trustKey is the context key of the hosts withTrust marks as targets.
+type trustKey struct{}
+
+// withTrust marks the hosts of jobs as targets for the requests made with
+// the returned context only, so an API scan doesn't widen who receives
+// -auth for the scans after it.
+func withTrust(ctx context.Context, jobs []scanJob) context.Context {
+	hosts := make(map[string]struct{}, len(jobs))
+	for _, job := range jobs {
+		if u, err := url.Parse(job.url); err == nil && u.Host != "" {
+			hosts[strings.ToLower(u.Host)] = struct{}{}
+		}
+	}
+	return context.WithValue(ctx, trustKey{}, hosts)
+}
+
+// trusted reports whether host (host[:port]) is one of the targets, of the
+// session or of the scan ctx belongs to.
+func (cs *credentialStore) trusted(ctx context.Context, host string) bool {
+	host = strings.ToLower(host)
+	if hosts, ok := ctx.Value(trustKey{}).(map[string]struct{}); ok {
+		if _, ok := hosts[host]; ok {
+			return true
+		}
+	}
+	cs.mu.RLock()
+	defer cs.mu.RUnlock()
+	_, ok := cs.seeds[host]
+	return ok
+}
+
+func authEnvName(host string) string {
+	return "GLF_AUTH_HOST_" + strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		}
+		return '_'
+	}, host)
+}
+
+// authorization returns the Authorization header for host (host[:port]),
+// or "".
+func (cs *credentialStore) authorization(ctx context.Context, host string) string {
+	host = strings.ToLower(host)
+	hostname := host
+	if u, err := url.Parse("//" + host); err == nil {
+		hostname = u.Hostname()
+	}
+	for _, name := range []string{authEnvName(host), authEnvName(hostname)} {
+		if v := os.Getenv(name); v != "" {
+			return authValue(v)
+		}
+	}
+	if v, ok := cs.netrc[hostname]; ok {
+		return v
+	}
+	if !cs.trusted(ctx, host) {
+		return ""
+	}
+	if cs.basic != "" {
+		return basicAuth(cs.basic)
+	}
+	return cs.fallback
+}
+
+// authTransport adds the stored credentials to requests that don't carry
+// their own Authorization header, including redirects, followed chunks and
+// probes.
+type authTransport struct {
+	next  http.RoundTripper
+	creds *credentialStore
+}
+
+func (t *authTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Header.Get("Authorization") == "" {
+		if auth := t.creds.authorization(req.Context(), req.URL.Host); auth != "" {
+			req = req.Clone(req.Context())
+			req.Header.Set("Authorization", auth)
+		}
+	}
+	return t.next.RoundTrip(req)
+}
@@ -0,0 +1,43 @@
+package golinkfinder
+
+import "testing"
+
+func TestParseNetrc(t *testing.T) {
+	data := `# comment lines are not tokens netrc knows
+machine api.example.com login alice password s3cret
+machine api.example.com login mallory password second
+
+machine multi.example.com
+	login bob
+	account ignored
+	password hunter2
+
+macdef init
+machine macro.example.com login eve password fromMacro
+
+machine nologin.example.com password orphan
+default login anon password guest
+`
+	machines, fallback := parseNetrc(data)
+	want := map[string]string{
+		"api.example.com":   basicAuth("alice:s3cret"),
+		"multi.example.com": basicAuth("bob:hunter2"),
+	}
+	for host, auth := range want {
+		if machines[host] != auth {
+			t.Errorf("machine %s: got %q, want %q", host, machines[host], auth)
+		}
+	}
+	for _, host := range []string{"macro.example.com", "nologin.example.com"} {
+		if auth, ok := machines[host]; ok {
+			t.Errorf("machine %s should have no credentials, got %q", host, auth)
+		}
+	}
+	if fallback != basicAuth("anon:guest") {
+		t.Errorf("default entry: got %q, want %q", fallback, basicAuth("anon:guest"))
+	}
+
+	if machines, fallback := parseNetrc(""); len(machines) != 0 || fallback != "" {
+		t.Errorf("empty netrc: got %v and %q", machines, fallback)
+	}
+}
@@ -0,0 +1,228 @@
+package golinkfinder
+
+import (
+	"database/sql"
+	"fmt"
+	"html/template"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// dashboard is the web UI of monitor -dashboard: the targets and how their
+// last scans went, the recent runs, the newest findings and the history of
+// each source, all read from the -db results database.
+type dashboard struct {
+	rdb *resultsDB
+	// port is the port listened on; requests must name it in their Host.
+	port string
+
+	mu       sync.Mutex
+	targets  []string
+	pass     int
+	lastPass time.Time
+	nextPass time.Time
+}
+
+func (d *dashboard) setTargets(jobs []scanJob) {
+	targets := make([]string, len(jobs))
+	for i, job := range jobs {
+		targets[i] = job.url
+	}
+	d.mu.Lock()
+	d.targets = targets
+	d.mu.Unlock()
+}
+
+func (d *dashboard) passDone(pass int, next time.Time) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.pass, d.lastPass, d.nextPass = pass, time.Now(), next
+}
+
+type dashboardTarget struct {
+	URL       string
+	LastSeen  string
+	LastError string
+	Findings  int
+}
+
+type dashboardRun struct {
+	ID                         int64
+	Started, Finished          string
+	Targets, Failed, Endpoints int
+}
+
+type dashboardFinding struct {
+	Value, Category, Source string
+	FirstSeen, LastSeen     string
+	Gone                    bool
+}
+
+// allowsHost reports whether a request's Host is the dashboard's own
+// loopback address. A page on a DNS-rebound name reaches the listener
+// with its own name as Host and is refused.
+func (d *dashboard) allowsHost(hostport string) bool {
+	return loopbackHost(hostport, d.port)
+}
+
+func (d *dashboard) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !d.allowsHost(r.Host) {
+		http.Error(w, "forbidden host", http.StatusForbidden)
+		return
+	}
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var err error
+	switch r.URL.Path {
+	case "/":
+		err = d.overview(w)
+	case "/source":
+		err = d.source(w, r.URL.Query().Get("url"))
+	default:
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func (d *dashboard) overview(w http.ResponseWriter) error {
+	page := struct {
+		Pass               int
+		LastPass, NextPass string
+		Targets            []dashboardTarget
+		Runs               []dashboardRun
+		Feed               []dashboardFinding
+	}{}
+	d.mu.Lock()
+	page.Pass = d.pass
+	if !d.lastPass.IsZero() {
+		page.LastPass = d.lastPass.Format(time.RFC3339)
+		page.NextPass = d.nextPass.Format(time.RFC3339)
+	}
+	targets := d.targets
+	d.mu.Unlock()
+
+	for _, target := range targets {
+		t := dashboardTarget{URL: target}
+		err := d.rdb.db.QueryRow(`SELECT s.last_seen, s.last_error, (SELECT COUNT(*) FROM endpoint_sources WHERE source_id = s.id AND last_run_id = s.last_run_id)
+			FROM sources s WHERE s.url = ?`, target).Scan(&t.LastSeen, &t.LastError, &t.Findings)
+		if err != nil && err != sql.ErrNoRows {
+			return fmt.Errorf("could not load targets: %v", err)
+		}
+		page.Targets = append(page.Targets, t)
+	}
+
+	rows, err := d.rdb.db.Query(`SELECT id, started_at, COALESCE(finished_at, ''), targets, failed, endpoints FROM runs ORDER BY id DESC LIMIT 20`)
+	if err != nil {
+		return fmt.Errorf("could not load runs: %v", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var run dashboardRun
+		if err := rows.Scan(&run.ID, &run.Started, &run.Finished, &run.Targets, &run.Failed, &run.Endpoints); err != nil {
+			return err
+		}
+		page.Runs = append(page.Runs, run)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	feed, err := d.rdb.db.Query(`SELECT e.value, e.category, e.first_seen, e.last_seen, MIN(s.url) FROM endpoints e
+		JOIN endpoint_sources es ON es.endpoint_id = e.id
+		JOIN sources s ON s.id = es.source_id
+		GROUP BY e.id ORDER BY e.first_seen DESC, e.id DESC LIMIT 200`)
+	if err != nil {
+		return fmt.Errorf("could not load findings: %v", err)
+	}
+	defer feed.Close()
+	for feed.Next() {
+		var f dashboardFinding
+		if err := feed.Scan(&f.Value, &f.Category, &f.FirstSeen, &f.LastSeen, &f.Source); err != nil {
+			return err
+		}
+		page.Feed = append(page.Feed, f)
+	}
+	if err := feed.Err(); err != nil {
+		return err
+	}
+	return dashboardHTML.ExecuteTemplate(w, "overview", page)
+}
+
+// source shows every value ever found in one source, newest first; values
+// the last scan of the source no longer found are marked gone.
+func (d *dashboard) source(w http.ResponseWriter, sourceURL string) error {
+	page := struct {
+		URL, FirstSeen, LastSeen, LastError string
+		Findings                            []dashboardFinding
+	}{URL: sourceURL}
+	err := d.rdb.db.QueryRow(`SELECT first_seen, last_seen, last_error FROM sources WHERE url = ?`, sourceURL).Scan(&page.FirstSeen, &page.LastSeen, &page.LastError)
+	if err == sql.ErrNoRows {
+		return dashboardHTML.ExecuteTemplate(w, "source", page)
+	}
+	if err != nil {
+		return fmt.Errorf("could not load source: %v", err)
+	}
+	rows, err := d.rdb.db.Query(`SELECT e.value, e.category, es.first_seen, es.last_seen, es.last_run_id < s.last_run_id FROM endpoint_sources es
+		JOIN endpoints e ON e.id = es.endpoint_id
+		JOIN sources s ON s.id = es.source_id
+		WHERE s.url = ? ORDER BY es.first_seen DESC, e.value`, sourceURL)
+	if err != nil {
+		return fmt.Errorf("could not load source history: %v", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var f dashboardFinding
+		if err := rows.Scan(&f.Value, &f.Category, &f.FirstSeen, &f.LastSeen, &f.Gone); err != nil {
+			return err
+		}
+		page.Findings = append(page.Findings, f)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	return dashboardHTML.ExecuteTemplate(w, "source", page)
+}
+
+var dashboardHTML = template.Must(template.New("dashboard").Parse(`
+{{define "head"}}<!DOCTYPE html>
+<html><head><meta charset="utf-8"><meta http-equiv="refresh" content="30"><title>golinkfinder monitor</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; margin-bottom: 1.5em; }
+td, th { border: 1px solid #ccc; padding: 2px 8px; text-align: left; }
+td.v { font-family: monospace; }
+.error { color: #b00; } .gone { color: #999; text-decoration: line-through; }
+</style></head><body>{{end}}
+
+{{define "overview"}}{{template "head"}}
+<h1>golinkfinder monitor</h1>
+<p>{{if .LastPass}}Pass #{{.Pass}} finished at {{.LastPass}}; next pass at {{.NextPass}}.{{else}}First pass running.{{end}}</p>
+<h2>Targets</h2>
+<table><tr><th>URL</th><th>Last scanned</th><th>Findings</th><th>Last error</th></tr>
+{{range .Targets}}<tr><td class="v"><a href="/source?url={{.URL}}">{{.URL}}</a></td><td>{{.LastSeen}}</td><td>{{.Findings}}</td><td class="error">{{.LastError}}</td></tr>
+{{end}}</table>
+<h2>Newest findings</h2>
+<table><tr><th>First seen</th><th>Value</th><th>Category</th><th>Source</th></tr>
+{{range .Feed}}<tr><td>{{.FirstSeen}}</td><td class="v">{{.Value}}</td><td>{{.Category}}</td><td class="v"><a href="/source?url={{.Source}}">{{.Source}}</a></td></tr>
+{{end}}</table>
+<h2>Runs</h2>
+<table><tr><th>ID</th><th>Started</th><th>Finished</th><th>Targets</th><th>Failed</th><th>Endpoints</th></tr>
+{{range .Runs}}<tr><td>{{.ID}}</td><td>{{.Started}}</td><td>{{.Finished}}</td><td>{{.Targets}}</td><td>{{.Failed}}</td><td>{{.Endpoints}}</td></tr>
+{{end}}</table>
+</body></html>{{end}}
+
+{{define "source"}}{{template "head"}}
+<p><a href="/">&larr; overview</a></p>
+<h1 class="v">{{.URL}}</h1>
+{{if .FirstSeen}}<p>First scanned {{.FirstSeen}}, last scanned {{.LastSeen}}.{{if .LastError}} <span class="error">Last error: {{.LastError}}</span>{{end}}</p>
+<table><tr><th>Value</th><th>Category</th><th>First seen</th><th>Last seen</th></tr>
+{{range .Findings}}<tr{{if .Gone}} class="gone"{{end}}><td class="v">{{.Value}}</td><td>{{.Category}}</td><td>{{.FirstSeen}}</td><td>{{.LastSeen}}</td></tr>
+{{end}}</table>{{else}}<p>Not scanned yet.</p>{{end}}
+</body></html>{{end}}
+`))
@@ -0,0 +1,65 @@
+package golinkfinder
+
+import (
+	"encoding/base64"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+var (
+	// data: URIs carrying script, markup or JSON, base64 or percent-encoded.
+	dataURIRegex = regexp.MustCompile(`(?i)data:((?:text|application)/(?:x-)?(?:javascript|ecmascript|html|json))((?:;[\w\-]+=[\w\-]+)*)(;base64)?,([A-Za-z0-9+/=%\-_.!~*()]+)`)
+	// javascript: URIs are only worth decoding when percent-encoded; plain
+	// ones are already matched in the surrounding body.
+	jsURIRegexes = []*regexp.Regexp{
+		regexp.MustCompile(`(?i)"\s*javascript:([^"]*%[0-9a-f]{2}[^"]*)"`),
+		regexp.MustCompile(`(?i)'\s*javascript:([^']*%[0-9a-f]{2}[^']*)'`),
+	}
+)
+
+// embeddedContent is a script or document decoded from a URI inside a
+// body, with the offset of that URI in the body.
+type embeddedContent struct {
+	offset      int
+	contentType string
+	body        []byte
+}
+
+// embeddedContents decodes the data: and javascript: URIs in body.
+func embeddedContents(body []byte) []embeddedContent {
+	content := string(body)
+	var embedded []embeddedContent
+	if strings.Contains(content, "data:") {
+		for _, loc := range dataURIRegex.FindAllStringSubmatchIndex(content, -1) {
+			mediaType := strings.ToLower(content[loc[2]:loc[3]])
+			payload := content[loc[8]:loc[9]]
+			var decoded []byte
+			var err error
+			if loc[6] >= 0 {
+				payload, _ = url.PathUnescape(payload)
+				decoded, err = base64.StdEncoding.DecodeString(payload)
+				if err != nil {
+					decoded, err = base64.RawStdEncoding.DecodeString(strings.TrimRight(payload, "="))
+				}
+			} else {
+				var s string
+				s, err = url.PathUnescape(payload)
+				decoded = []byte(s)
+			}
+			if err == nil && len(decoded) > 0 {
+				embedded = append(embedded, embeddedContent{offset: loc[0], contentType: mediaType, body: decoded})
+			}
+		}
+	}
+	if strings.Contains(content, "javascript:") {
+		for _, re := range jsURIRegexes {
+			for _, loc := range re.FindAllStringSubmatchIndex(content, -1) {
+				if decoded, err := url.PathUnescape(content[loc[2]:loc[3]]); err == nil {
+					embedded = append(embedded, embeddedContent{offset: loc[0], contentType: "application/javascript", body: []byte(decoded)})
+				}
+			}
+		}
+	}
+	return embedded
+}
@@ -0,0 +1,275 @@
+package golinkfinder
+
+import (
+	"database/sql"
+	_ "embed"
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+//go:embed schema.sql
+var dbSchema string
+
+// dbMigrations bring databases created by older versions up to the current
+// schema. Each statement may already have been applied.
+var dbMigrations = []string{
+	`ALTER TABLE endpoint_sources ADD COLUMN line INTEGER NOT NULL DEFAULT 0`,
+	`ALTER TABLE endpoint_sources ADD COLUMN offset INTEGER NOT NULL DEFAULT 0`,
+	`ALTER TABLE runs ADD COLUMN scan_id TEXT NOT NULL DEFAULT ''`,
+	`ALTER TABLE runs ADD COLUMN version TEXT NOT NULL DEFAULT ''`,
+	`ALTER TABLE runs ADD COLUMN config TEXT NOT NULL DEFAULT '{}'`,
+	`ALTER TABLE sources ADD COLUMN content_hash TEXT NOT NULL DEFAULT ''`,
+	`ALTER TABLE runs ADD COLUMN labels TEXT NOT NULL DEFAULT '{}'`,
+}
+
+type resultsDB struct {
+	db *sql.DB
+}
+
+func dbNow() string {
+	return time.Now().UTC().Format(time.RFC3339)
+}
+
+func openResultsDB(path string) (*resultsDB, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, fmt.Errorf("could not open database: %v", err)
+	}
+	db.SetMaxOpenConns(1)
+	if _, err := db.Exec(dbSchema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("could not apply schema: %v", err)
+	}
+	for _, migration := range dbMigrations {
+		if _, err := db.Exec(migration); err != nil && !strings.Contains(err.Error(), "duplicate column") {
+			db.Close()
+			return nil, fmt.Errorf("could not migrate schema: %v", err)
+		}
+	}
+	return &resultsDB{db: db}, nil
+}
+
+func (r *resultsDB) Close() error {
+	return r.db.Close()
+}
+
+// startRun records the start of a scan and returns its run ID, under which
+// its sources are recorded.
+func (r *resultsDB) startRun(st *scanStats, args []string, targets int) (int64, error) {
+	res, err := r.db.Exec(`INSERT INTO runs (started_at, args, targets, scan_id, version, config, labels) VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		dbNow(), strings.Join(args, " "), targets, st.ScanID, st.Version, configJSON(st.Config), configJSON(st.Labels))
+	if err != nil {
+		return 0, fmt.Errorf("could not record run: %v", err)
+	}
+	return res.LastInsertId()
+}
+
+func (r *resultsDB) finishRun(runID int64, targets, failed, endpoints int) error {
+	_, err := r.db.Exec(`UPDATE runs SET finished_at = ?, targets = ?, failed = ?, endpoints = ? WHERE id = ?`,
+		dbNow(), targets, failed, endpoints, runID)
+	if err != nil {
+		return fmt.Errorf("could not finish run: %v", err)
+	}
+	return nil
+}
+
+// recordSource upserts a scanned source and links every value found in it,
+// refreshing last_seen on rows that already exist from earlier runs. A
+// failed fetch (empty hash) keeps the last known content hash.
+func (r *resultsDB) recordSource(runID int64, sourceURL, hash string, scanErr error, findings []Finding) error {
+	now := dbNow()
+	lastError := ""
+	if scanErr != nil {
+		lastError = scanErr.Error()
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("could not begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	var sourceID int64
+	err = tx.QueryRow(`INSERT INTO sources (url, first_seen, last_seen, last_run_id, last_error, content_hash) VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT(url) DO UPDATE SET last_seen = excluded.last_seen, last_run_id = excluded.last_run_id, last_error = excluded.last_error,
+			content_hash = CASE WHEN excluded.content_hash != '' THEN excluded.content_hash ELSE content_hash END
+		RETURNING id`, sourceURL, now, now, runID, lastError, hash).Scan(&sourceID)
+	if err != nil {
+		return fmt.Errorf("could not record source: %v", err)
+	}
+
+	for _, f := range findings {
+		var endpointID int64
+		err = tx.QueryRow(`INSERT INTO endpoints (value, category, first_seen, last_seen) VALUES (?, ?, ?, ?)
+			ON CONFLICT(value, category) DO UPDATE SET last_seen = excluded.last_seen
+			RETURNING id`, f.Value, f.Category, now, now).Scan(&endpointID)
+		if err != nil {
+			return fmt.Errorf("could not record endpoint: %v", err)
+		}
+		_, err = tx.Exec(`INSERT INTO endpoint_sources (endpoint_id, source_id, first_seen, last_seen, last_run_id, line, offset) VALUES (?, ?, ?, ?, ?, ?, ?)
+			ON CONFLICT(endpoint_id, source_id) DO UPDATE SET last_seen = excluded.last_seen, last_run_id = excluded.last_run_id,
+				line = excluded.line, offset = excluded.offset`,
+			endpointID, sourceID, now, now, runID, f.Line, f.Offset)
+		if err != nil {
+			return fmt.Errorf("could not link endpoint to source: %v", err)
+		}
+	}
+	return tx.Commit()
+}
+
+// knownHashes returns the last content hash recorded for each source.
+func (r *resultsDB) knownHashes() (map[string]string, error) {
+	rows, err := r.db.Query(`SELECT url, content_hash FROM sources WHERE content_hash != ''`)
+	if err != nil {
+		return nil, fmt.Errorf("could not load source hashes: %v", err)
+	}
+	defer rows.Close()
+
+	hashes := make(map[string]string)
+	for rows.Next() {
+		var u, hash string
+		if err := rows.Scan(&u, &hash); err != nil {
+			return nil, err
+		}
+		hashes[u] = hash
+	}
+	return hashes, rows.Err()
+}
+
+func (r *resultsDB) knownValues() (map[string]struct{}, error) {
+	rows, err := r.db.Query(`SELECT DISTINCT value FROM endpoints`)
+	if err != nil {
+		return nil, fmt.Errorf("could not load known values: %v", err)
+	}
+	defer rows.Close()
+
+	known := make(map[string]struct{})
+	for rows.Next() {
+		var value string
+		if err := rows.Scan(&value); err != nil {
+			return nil, err
+		}
+		known[value] = struct{}{}
+	}
+	return known, rows.Err()
+}
+
+func runQuery(args []string) {
+	initColors(false, "")
+	fs := flag.NewFlagSet("query", flag.ExitOnError)
+	var (
+		dbPath      string
+		category    string
+		source      string
+		since       time.Duration
+		runID       int64
+		listRuns    bool
+		newOnly     bool
+		provenance  bool
+		labelFilter stringList
+	)
+	fs.StringVar(&dbPath, "db", "", "SQLite results database to query.")
+	fs.StringVar(&category, "category", "", "Only show values of this category.")
+	fs.StringVar(&source, "source", "", "Only show values found in sources containing this substring.")
+	fs.DurationVar(&since, "since", 0, "Only show values last seen within this duration (e.g. 24h).")
+	fs.Int64Var(&runID, "run", 0, "Only show values seen in this run ID.")
+	fs.BoolVar(&newOnly, "new", false, "With -run, only show values first seen in that run.")
+	fs.BoolVar(&listRuns, "runs", false, "List recorded runs instead of values.")
+	fs.BoolVar(&provenance, "provenance", false, "Print one row per source with the line and byte offset of the value.")
+	fs.Var(&labelFilter, "label", "Only show values last seen in runs with this -label key=value (repeatable).")
+	fs.Parse(args)
+
+	if dbPath == "" {
+		fs.Usage()
+		fatal(errors.New("-db is required"))
+	}
+	rdb, err := openResultsDB(dbPath)
+	if err != nil {
+		fatal(err)
+	}
+	defer rdb.Close()
+
+	if listRuns {
+		rows, err := rdb.db.Query(`SELECT id, scan_id, version, started_at, COALESCE(finished_at, ''), targets, failed, endpoints, labels, args FROM runs ORDER BY id`)
+		if err != nil {
+			fatal(err)
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var (
+				id                                             int64
+				scanID, runVersion, started, finished, runArgs string
+				runLabels                                      string
+				targets, failed, endpoints                     int
+			)
+			if err := rows.Scan(&id, &scanID, &runVersion, &started, &finished, &targets, &failed, &endpoints, &runLabels, &runArgs); err != nil {
+				fatal(err)
+			}
+			if runLabels == "{}" || runLabels == "null" {
+				runLabels = ""
+			}
+			fmt.Printf("%d\t%s\t%s\t%s\t%s\ttargets=%d failed=%d endpoints=%d\t%s\t%s\n", id, scanID, runVersion, started, finished, targets, failed, endpoints, runLabels, runArgs)
+		}
+		return
+	}
+
+	columns := `e.value, e.category, e.first_seen, e.last_seen, '', 0, 0`
+	if provenance {
+		columns = `e.value, e.category, e.first_seen, e.last_seen, s.url, es.line, es.offset`
+	}
+	query := `SELECT DISTINCT ` + columns + ` FROM endpoints e
+		JOIN endpoint_sources es ON es.endpoint_id = e.id
+		JOIN sources s ON s.id = es.source_id WHERE 1 = 1`
+	var params []interface{}
+	if category != "" {
+		query += ` AND e.category = ?`
+		params = append(params, category)
+	}
+	if source != "" {
+		query += ` AND s.url LIKE ?`
+		params = append(params, "%"+source+"%")
+	}
+	if since > 0 {
+		query += ` AND e.last_seen >= ?`
+		params = append(params, time.Now().Add(-since).UTC().Format(time.RFC3339))
+	}
+	for _, label := range labelFilter {
+		key, value, _ := strings.Cut(label, "=")
+		query += ` AND es.last_run_id IN (SELECT id FROM runs WHERE json_extract(labels, '$.' || json_quote(?)) = ?)`
+		params = append(params, strings.TrimSpace(key), strings.TrimSpace(value))
+	}
+	if runID > 0 {
+		query += ` AND es.last_run_id = ?`
+		params = append(params, runID)
+		if newOnly {
+			query += ` AND e.first_seen >= (SELECT started_at FROM runs WHERE id = ?)`
+			params = append(params, runID)
+		}
+	}
+	query += ` ORDER BY 1, 5`
+
+	rows, err := rdb.db.Query(query, params...)
+	if err != nil {
+		fatal(err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			value, cat, firstSeen, lastSeen, source string
+			line, offset                            int
+		)
+		if err := rows.Scan(&value, &cat, &firstSeen, &lastSeen, &source, &line, &offset); err != nil {
+			fatal(err)
+		}
+		if provenance {
+			fmt.Printf("%s\t%s\t%s\t%s\t%s\t%d\t%d\n", value, cat, firstSeen, lastSeen, source, line, offset)
+		} else {
+			fmt.Printf("%s\t%s\t%s\t%s\n", value, cat, firstSeen, lastSeen)
+		}
+	}
+}
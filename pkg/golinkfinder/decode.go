@@ -0,0 +1,112 @@
+package golinkfinder
+
+import (
+	"strconv"
+	"strings"
+)
+
+// htmlEntities are the named entities worth decoding before matching:
+// quotes delimit endpoints, the rest appear inside them.
+var htmlEntities = map[string]byte{
+	"quot":   '"',
+	"apos":   '\'',
+	"amp":    '&',
+	"sol":    '/',
+	"colon":  ':',
+	"quest":  '?',
+	"equals": '=',
+	"num":    '#',
+}
+
+// urlEscapes are the percent-encodings decoded before matching. Only the
+// characters that give an endpoint its shape are touched, so an encoded
+// '&' or '=' inside a query value keeps its meaning.
+var urlEscapes = map[string]byte{"2f": '/', "3a": ':', "3f": '?'}
+
+// decodeEscapes undoes the JSON (\/, \", \u002f), HTML entity (&#x2f;,
+// &quot;) and URL (%2F) escaping that hides endpoints in serialized state
+// and attributes. Only printable ASCII results are decoded. It returns the
+// decoded text and, for each of its bytes, the offset of the byte it came
+// from; both are unchanged (and the offsets nil) when nothing was escaped.
+func decodeEscapes(content string) (string, []int) {
+	if !strings.ContainsAny(content, `\&%`) {
+		return content, nil
+	}
+	var b strings.Builder
+	offsets := make([]int, 0, len(content))
+	emit := func(ch byte, from int) {
+		b.WriteByte(ch)
+		offsets = append(offsets, from)
+	}
+	changed := false
+	for i := 0; i < len(content); {
+		ch, n := decodeEscapeAt(content, i)
+		if n == 0 {
+			emit(content[i], i)
+			i++
+			continue
+		}
+		emit(ch, i)
+		i += n
+		changed = true
+	}
+	if !changed {
+		return content, nil
+	}
+	return b.String(), offsets
+}
+
+// decodeEscapeAt decodes an escape sequence starting at i, returning the
+// character and the sequence length, or a length of 0 if there is none.
+func decodeEscapeAt(content string, i int) (byte, int) {
+	rest := content[i:]
+	switch rest[0] {
+	case '\\':
+		if len(rest) >= 2 && (rest[1] == '/' || rest[1] == '"' || rest[1] == '\'') {
+			return rest[1], 2
+		}
+		if len(rest) >= 6 && rest[1] == 'u' {
+			if v, err := strconv.ParseUint(rest[2:6], 16, 16); err == nil && printableASCII(v) {
+				return byte(v), 6
+			}
+		}
+		if len(rest) >= 4 && rest[1] == 'x' {
+			if v, err := strconv.ParseUint(rest[2:4], 16, 8); err == nil && printableASCII(v) {
+				return byte(v), 4
+			}
+		}
+	case '%':
+		if len(rest) >= 3 {
+			if ch, ok := urlEscapes[strings.ToLower(rest[1:3])]; ok {
+				return ch, 3
+			}
+		}
+	case '&':
+		end := strings.IndexByte(rest, ';')
+		if end < 2 || end > 10 {
+			return 0, 0
+		}
+		name := rest[1:end]
+		if ch, ok := htmlEntities[name]; ok {
+			return ch, end + 1
+		}
+		if name[0] != '#' {
+			return 0, 0
+		}
+		var v uint64
+		var err error
+		if len(name) > 1 && (name[1] == 'x' || name[1] == 'X') {
+			v, err = strconv.ParseUint(name[2:], 16, 16)
+		} else {
+			v, err = strconv.ParseUint(name[1:], 10, 16)
+		}
+		if err == nil && printableASCII(v) {
+			return byte(v), end + 1
+		}
+	}
+	return 0, 0
+}
+
+func printableASCII(v uint64) bool {
+	return v >= 0x20 && v < 0x7f
+}
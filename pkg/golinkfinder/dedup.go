@@ -0,0 +1,77 @@
+package golinkfinder
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+)
+
+// dedupKeys are the -dedup-key modes. "value" dedups what is printed (the
+// raw match, or the resolved URL with -r); the others report the first
+// value seen for each key:
+//   - raw: the match as written, so /api/x found on two hosts is one line
+//     even with -r;
+//   - url: the URL resolved against its source;
+//   - path: the resolved URL's cleaned path, without host, query, fragment
+//     or trailing slash;
+//   - host-path: host plus that path.
+var dedupKeys = []string{"value", "raw", "url", "path", "host-path"}
+
+// deduper maps each key to the first value reported for it. It lives in
+// memory even with -spill-dir, holding one entry per unique key.
+type deduper struct {
+	mode  string
+	first map[string]string
+}
+
+func newDeduper(mode string) (*deduper, error) {
+	if mode == "" || mode == "value" {
+		return nil, nil
+	}
+	for _, k := range dedupKeys {
+		if mode == k {
+			return &deduper{mode: mode, first: make(map[string]string)}, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown -dedup-key '%s' (valid: %s)", mode, strings.Join(dedupKeys, ", "))
+}
+
+// resolves reports modes whose keys tell values of different hosts apart,
+// which only works when the printed values are resolved too.
+func (d *deduper) resolves() bool {
+	return d != nil && (d.mode == "url" || d.mode == "host-path")
+}
+
+// value returns what to report for a finding whose match was raw and whose
+// printed value is value: the first value with the same key. Only URL
+// categories are keyed; others keep value.
+func (d *deduper) value(base *url.URL, f Finding, raw string) string {
+	if d == nil || !resolvableCategory(f.Category) {
+		return f.Value
+	}
+	key := raw
+	if d.mode != "raw" {
+		u, err := url.Parse(resolveAgainst(base, raw, false))
+		if err != nil {
+			return f.Value
+		}
+		switch d.mode {
+		case "url":
+			key = u.String()
+		case "path":
+			key = normalizedPath(u)
+		case "host-path":
+			key = strings.ToLower(u.Host) + normalizedPath(u)
+		}
+	}
+	if first, ok := d.first[key]; ok {
+		return first
+	}
+	d.first[key] = f.Value
+	return f.Value
+}
+
+func normalizedPath(u *url.URL) string {
+	return path.Clean("/" + u.Path)
+}
@@ -0,0 +1,95 @@
+package golinkfinder
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/proxy"
+)
+
+type dialFunc func(ctx context.Context, network, addr string) (net.Conn, error)
+
+// newDialer returns the function every connection is opened with. By
+// default it is a plain net.Dialer; -unix sends all traffic to a Unix
+// socket whatever the URL says, and -upstream to a fixed TCP address or
+// through a SOCKS5 proxy. -4 and -6 restrict TCP connections (to the
+// target or the upstream) to one address family.
+func newDialer(o *scanOptions) (dialFunc, error) {
+	dialer := &net.Dialer{}
+	if o.noHappyEyeballs {
+		// Try a dual-stack host's addresses one at a time instead of
+		// racing IPv6 against IPv4.
+		dialer.FallbackDelay = -1
+	}
+	family := ""
+	switch {
+	case o.ipv4 && o.ipv6:
+		return nil, fmt.Errorf("-4 and -6 can't be combined")
+	case o.ipv4:
+		family = "4"
+	case o.ipv6:
+		family = "6"
+	}
+	direct := familyDialer{dialer: dialer, family: family, dns: newDNSCache(o.dnsTTL, o.dnsConcurrency)}
+	switch {
+	case o.unixSocket != "" && o.upstream != "":
+		return nil, fmt.Errorf("-unix and -upstream can't be combined")
+	case o.unixSocket != "":
+		return func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return direct.DialContext(ctx, "unix", o.unixSocket)
+		}, nil
+	case o.upstream == "":
+		return direct.DialContext, nil
+	}
+
+	u, err := url.Parse(o.upstream)
+	if err != nil || u.Host == "" {
+		// A bare host:port.
+		return func(ctx context.Context, network, _ string) (net.Conn, error) {
+			return direct.DialContext(ctx, network, o.upstream)
+		}, nil
+	}
+	switch u.Scheme {
+	case "tcp":
+		return func(ctx context.Context, network, _ string) (net.Conn, error) {
+			return direct.DialContext(ctx, network, u.Host)
+		}, nil
+	case "socks5", "socks5h":
+		var auth *proxy.Auth
+		if u.User != nil {
+			password, _ := u.User.Password()
+			auth = &proxy.Auth{User: u.User.Username(), Password: password}
+		}
+		socks, err := proxy.SOCKS5("tcp", u.Host, auth, direct)
+		if err != nil {
+			return nil, fmt.Errorf("invalid -upstream: %v", err)
+		}
+		return socks.(proxy.ContextDialer).DialContext, nil
+	}
+	return nil, fmt.Errorf("unsupported -upstream scheme '%s' (valid: tcp, socks5, or host:port)", u.Scheme)
+}
+
+// familyDialer pins tcp connections to tcp4 or tcp6 when family is set,
+// resolving their hosts through the DNS cache.
+type familyDialer struct {
+	dialer *net.Dialer
+	family string
+	dns    *dnsCache
+}
+
+func (d familyDialer) DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	if network == "tcp" && d.family != "" {
+		network += d.family
+	}
+	if strings.HasPrefix(network, "tcp") {
+		return d.dns.dial(ctx, d.dialer, network, addr)
+	}
+	return d.dialer.DialContext(ctx, network, addr)
+}
+
+func (d familyDialer) Dial(network, addr string) (net.Conn, error) {
+	return d.DialContext(context.Background(), network, addr)
+}
@@ -0,0 +1,100 @@
+package golinkfinder
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+)
+
+func readLineSet(path string) (map[string]struct{}, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	set := make(map[string]struct{})
+	for _, line := range readLines(file, nil) {
+		set[line] = struct{}{}
+	}
+	return set, nil
+}
+
+// missingFrom returns the sorted entries of a that are not present in b.
+func missingFrom(a, b map[string]struct{}) []string {
+	out := make([]string, 0)
+	for entry := range a {
+		if _, ok := b[entry]; !ok {
+			out = append(out, entry)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+func runDiff(args []string) {
+	var (
+		only       string
+		noColor    bool
+		theme      string
+		ignoreFile string
+		noIgnore   bool
+	)
+	fs := flag.NewFlagSet("diff", flag.ExitOnError)
+	fs.StringVar(&only, "only", "", "Only show 'added' or 'removed' endpoints, without the +/- prefix.")
+	fs.BoolVar(&noColor, "no-color", false, "Disable colorized output.")
+	fs.StringVar(&theme, "theme", "", "Color theme: dark, or light for light terminals (default $GOLINKFINDER_THEME, else dark).")
+	fs.StringVar(&ignoreFile, "ignore-file", "", "File of triaged findings to leave out (default ./"+defaultIgnoreFile+" when present).")
+	fs.BoolVar(&noIgnore, "no-ignore", false, "Don't read the ignore file.")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: golinkfinder diff [flags] <old.txt> <new.txt>\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if err := checkTheme(theme); err != nil {
+		fatal(err)
+	}
+	initColors(noColor, theme)
+
+	if fs.NArg() != 2 || (only != "" && only != "added" && only != "removed") {
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	oldSet, err := readLineSet(fs.Arg(0))
+	if err != nil {
+		fatal(err)
+	}
+	newSet, err := readLineSet(fs.Arg(1))
+	if err != nil {
+		fatal(err)
+	}
+
+	ignore, err := openIgnoreList(ignoreFile, noIgnore)
+	if err != nil {
+		fatal(err)
+	}
+	ignore.drop(oldSet)
+	ignore.drop(newSet)
+
+	added := missingFrom(newSet, oldSet)
+	removed := missingFrom(oldSet, newSet)
+	switch only {
+	case "added":
+		for _, entry := range added {
+			fmt.Println(entry)
+		}
+	case "removed":
+		for _, entry := range removed {
+			fmt.Println(entry)
+		}
+	default:
+		for _, entry := range added {
+			fmt.Printf("%s+ %s%s\n", c.Green, entry, c.End)
+		}
+		for _, entry := range removed {
+			fmt.Printf("%s- %s%s\n", c.Red, entry, c.End)
+		}
+	}
+}
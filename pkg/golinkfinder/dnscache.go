@@ -0,0 +1,207 @@
+package golinkfinder
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"time"
+)
+
+// dnsNegativeTTL is how long a host that doesn't exist is remembered.
+// Timeouts and other resolver failures are never cached: they are what an
+// overloaded resolver answers and the next try may succeed.
+const dnsNegativeTTL = 30 * time.Second
+
+// dnsCache resolves the hosts the scan connects to once per -dns-ttl, with
+// concurrent lookups of the same host sharing one query and at most
+// -dns-concurrency queries in flight, so lists spanning thousands of
+// subdomains don't overload the resolver.
+type dnsCache struct {
+	resolver *net.Resolver
+	ttl      time.Duration
+	// sem holds a slot per lookup in flight; nil means no limit.
+	sem chan struct{}
+
+	mu      sync.Mutex
+	entries map[string]*dnsEntry
+}
+
+type dnsEntry struct {
+	// done is closed once addrs and err are set.
+	done    chan struct{}
+	addrs   []net.IPAddr
+	err     error
+	expires time.Time
+}
+
+func newDNSCache(ttl time.Duration, concurrency int) *dnsCache {
+	d := &dnsCache{resolver: net.DefaultResolver, ttl: ttl, entries: make(map[string]*dnsEntry)}
+	if concurrency > 0 {
+		d.sem = make(chan struct{}, concurrency)
+	}
+	return d
+}
+
+func (d *dnsCache) lookup(ctx context.Context, host string) ([]net.IPAddr, error) {
+	d.mu.Lock()
+	e, ok := d.entries[host]
+	if ok {
+		select {
+		case <-e.done:
+			if time.Now().After(e.expires) {
+				ok = false
+			}
+		default:
+		}
+	}
+	if !ok {
+		e = &dnsEntry{done: make(chan struct{})}
+		d.entries[host] = e
+		d.mu.Unlock()
+		d.resolve(ctx, host, e)
+	} else {
+		d.mu.Unlock()
+	}
+
+	select {
+	case <-e.done:
+		return e.addrs, e.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// resolve fills e in the background, so a caller giving up doesn't cancel
+// the query others wait on.
+func (d *dnsCache) resolve(ctx context.Context, host string, e *dnsEntry) {
+	go func() {
+		defer close(e.done)
+		if d.sem != nil {
+			d.sem <- struct{}{}
+			defer func() { <-d.sem }()
+		}
+		lookupCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 10*time.Second)
+		defer cancel()
+		e.addrs, e.err = d.resolver.LookupIPAddr(lookupCtx, host)
+		var dnsErr *net.DNSError
+		switch {
+		case e.err == nil:
+			e.expires = time.Now().Add(d.ttl)
+		case errors.As(e.err, &dnsErr) && dnsErr.IsNotFound:
+			e.expires = time.Now().Add(dnsNegativeTTL)
+		default:
+			d.forget(host, e)
+		}
+	}()
+}
+
+// forget drops a failed lookup so the next connection asks again.
+func (d *dnsCache) forget(host string, e *dnsEntry) {
+	d.mu.Lock()
+	if d.entries[host] == e {
+		delete(d.entries, host)
+	}
+	d.mu.Unlock()
+}
+
+// dial resolves addr through the cache and connects to its addresses of
+// the wanted family. With fallback (happy eyeballs), the other family is
+// raced 300ms after the first, as net.Dialer does.
+func (d *dnsCache) dial(ctx context.Context, dialer *net.Dialer, network, addr string) (net.Conn, error) {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil || net.ParseIP(host) != nil {
+		return dialer.DialContext(ctx, network, addr)
+	}
+	addrs, err := d.lookup(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+	// The primaries are the addresses of the first one's family.
+	var primaries, fallbacks []string
+	primaryV4 := false
+	for _, a := range addrs {
+		v4 := a.IP.To4() != nil
+		if network == "tcp4" && !v4 || network == "tcp6" && v4 {
+			continue
+		}
+		if len(primaries) == 0 {
+			primaryV4 = v4
+		}
+		target := net.JoinHostPort(a.IP.String(), port)
+		if v4 == primaryV4 {
+			primaries = append(primaries, target)
+		} else {
+			fallbacks = append(fallbacks, target)
+		}
+	}
+	if len(primaries) == 0 {
+		return nil, &net.DNSError{Err: "no suitable address", Name: host, IsNotFound: true}
+	}
+	if dialer.FallbackDelay < 0 || len(fallbacks) == 0 {
+		return dialSerial(ctx, dialer, network, append(primaries, fallbacks...))
+	}
+
+	type result struct {
+		conn    net.Conn
+		err     error
+		primary bool
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	results := make(chan result, 2)
+	fallbackStart := make(chan struct{})
+	go func() {
+		conn, err := dialSerial(ctx, dialer, network, primaries)
+		if err != nil {
+			close(fallbackStart)
+		}
+		results <- result{conn, err, true}
+	}()
+	go func() {
+		select {
+		case <-fallbackStart:
+		case <-time.After(300 * time.Millisecond):
+		case <-ctx.Done():
+		}
+		conn, err := dialSerial(ctx, dialer, network, fallbacks)
+		results <- result{conn, err, false}
+	}()
+	var firstErr error
+	for i := 0; i < 2; i++ {
+		res := <-results
+		if res.err == nil {
+			cancel()
+			if i == 0 {
+				// Close the loser if it connects too.
+				go func() {
+					if late := <-results; late.conn != nil {
+						late.conn.Close()
+					}
+				}()
+			}
+			return res.conn, nil
+		}
+		if firstErr == nil || res.primary {
+			firstErr = res.err
+		}
+	}
+	return nil, firstErr
+}
+
+func dialSerial(ctx context.Context, dialer *net.Dialer, network string, targets []string) (net.Conn, error) {
+	var firstErr error
+	for _, target := range targets {
+		conn, err := dialer.DialContext(ctx, network, target)
+		if err == nil {
+			return conn, nil
+		}
+		if firstErr == nil {
+			firstErr = err
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	return nil, firstErr
+}
@@ -0,0 +1,85 @@
+package golinkfinder
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const categoryEnvEndpoint = "env-endpoint"
+
+// envPrefix is the object a build-time setting is read from: process.env,
+// import.meta.env or a runtime env object such as window._env_.
+const envPrefix = `(?:process\.env\.|import\.meta\.env\.|window\.(?:_env_|__env__|__ENV__|env|ENV)\.)`
+
+// envRef matches an env reference, or a bare upper-case constant naming a
+// URL.
+const envRef = envPrefix + `?([A-Za-z_][A-Za-z0-9_]{2,})`
+
+var (
+	// envTemplateRegex matches `${API_URL}/users/${id}` template literals.
+	envTemplateRegex = regexp.MustCompile("`\\$\\{\\s*" + envRef + "\\s*\\}(/(?:[\\w\\-./?=&%~:#@+]|\\$\\{[^}`]{1,60}\\})*)")
+	// envConcatRegex matches process.env.REACT_APP_API + "/login".
+	envConcatRegex   = regexp.MustCompile(`\b` + envRef + `\s*\+\s*["'` + "`" + `](/[^"'` + "`" + `\s]{0,200})["'` + "`" + `]`)
+	envPrefixRegex   = regexp.MustCompile(`^` + envPrefix)
+	envPrefixedRegex = regexp.MustCompile(envPrefix + `$`)
+	envNameHint      = regexp.MustCompile(`(?i)url|uri|api|host|base|endpoint|domain|server|origin|backend`)
+)
+
+// envExtractor reports endpoints built from environment variables with
+// the placeholder kept (${API_URL}/users) under "env-endpoint". Variables
+// given with -var are substituted instead, and the result is reported as
+// an ordinary endpoint noting the template it came from.
+type envExtractor struct {
+	vars map[string]string
+}
+
+func (e envExtractor) Extract(source, contentType string, body []byte) []Finding {
+	content := string(body)
+	if !strings.Contains(content, "${") && !strings.Contains(content, "+") {
+		return nil
+	}
+	lines := newLineIndex(content)
+	seen := make(map[string]struct{})
+	findings := make([]Finding, 0)
+	for _, re := range []*regexp.Regexp{envTemplateRegex, envConcatRegex} {
+		for _, m := range re.FindAllStringSubmatchIndex(content, -1) {
+			name, path := content[m[2]:m[3]], content[m[4]:m[5]]
+			// Bare names must be prefixed env references or look like a
+			// URL setting, or any variable would do.
+			prefixed := envPrefixedRegex.MatchString(content[m[0]:m[2]])
+			if !prefixed && (strings.ToUpper(name) != name || !envNameHint.MatchString(name)) {
+				continue
+			}
+			template := "${" + name + "}" + path
+			if _, ok := seen[template]; ok {
+				continue
+			}
+			seen[template] = struct{}{}
+			f := Finding{Source: source, Value: template, Category: categoryEnvEndpoint, Line: lines.line(m[2]), Offset: m[2]}
+			if value, ok := e.vars[name]; ok {
+				f.Value, f.Category, f.Note = strings.TrimSuffix(value, "/")+path, categoryEndpoint, template
+			}
+			findings = append(findings, f)
+		}
+	}
+	return findings
+}
+
+// parseVars reads -var NAME=value pairs; NAME may keep its process.env.
+// or import.meta.env. prefix.
+func parseVars(pairs []string) (map[string]string, error) {
+	if len(pairs) == 0 {
+		return nil, nil
+	}
+	vars := make(map[string]string)
+	for _, pair := range pairs {
+		name, value, ok := strings.Cut(pair, "=")
+		name = envPrefixRegex.ReplaceAllString(strings.TrimSpace(name), "")
+		if !ok || name == "" {
+			return nil, fmt.Errorf("-var must be NAME=value, got '%s'", pair)
+		}
+		vars[name] = strings.TrimSpace(value)
+	}
+	return vars, nil
+}
@@ -0,0 +1,81 @@
+package golinkfinder
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+const categoryModule = "module"
+
+// ES module specifiers: static imports and re-exports (import x from "...",
+// import "...", export * from "...") and dynamic import("...").
+var (
+	esmStaticImportRegex  = regexp.MustCompile(`(?:^|[;\s}])(?:import|export)\s*(?:[\w$*{}\s,]+?\s*from\s*)?["']([^"'\n]+)["']`)
+	esmDynamicImportRegex = regexp.MustCompile(`\bimport\s*\(\s*["'` + "`" + `]([^"'` + "`" + `$\n]+)["'` + "`" + `]\s*\)`)
+)
+
+// moduleSpecifier reports whether spec names a file that can be fetched:
+// relative, root-relative or absolute. Bare specifiers ("react") only
+// resolve through a bundler or an import map.
+func moduleSpecifier(spec string) bool {
+	return strings.HasPrefix(spec, "./") || strings.HasPrefix(spec, "../") || strings.HasPrefix(spec, "/") ||
+		strings.HasPrefix(spec, "http://") || strings.HasPrefix(spec, "https://")
+}
+
+// esModules returns the URLs of the modules a script imports, resolved
+// against the script's URL as browsers do.
+func esModules(source, content string) []string {
+	if !strings.Contains(content, "import") && !strings.Contains(content, "export") {
+		return nil
+	}
+	base, _ := url.Parse(source)
+	seen := make(map[string]struct{})
+	var urls []string
+	for _, re := range []*regexp.Regexp{esmStaticImportRegex, esmDynamicImportRegex} {
+		for _, m := range re.FindAllStringSubmatch(content, -1) {
+			spec := strings.TrimSpace(m[1])
+			if !moduleSpecifier(spec) {
+				continue
+			}
+			u := resolveAgainst(base, spec, false)
+			if _, ok := seen[u]; !ok {
+				seen[u] = struct{}{}
+				urls = append(urls, u)
+			}
+		}
+	}
+	return urls
+}
+
+type esmExtractor struct{}
+
+func (esmExtractor) Extract(source, contentType string, body []byte) []Finding {
+	if isHTML(contentType, body) {
+		return nil
+	}
+	content := string(body)
+	if lang := sourceLang(source); lang == langTS || lang == langTSX {
+		content = stripTypeOnly(content)
+	}
+	findings := make([]Finding, 0)
+	for _, u := range esModules(source, content) {
+		findings = append(findings, Finding{Source: source, Value: u, Category: categoryModule})
+	}
+	return findings
+}
+
+// moduleInScope keeps import following on the importing script's origin,
+// or the crawled origins with -crawl; modules pulled from public CDNs are
+// vendor code.
+func (s *scanSession) moduleInScope(base *url.URL, module string) bool {
+	u, err := url.Parse(module)
+	if err != nil || base == nil {
+		return false
+	}
+	if u.Scheme == base.Scheme && u.Host == base.Host {
+		return true
+	}
+	_, ok := s.scope[u.Scheme+"://"+u.Host]
+	return ok
+}
@@ -0,0 +1,363 @@
+package golinkfinder
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// endpointRegex matches quoted paths, including template literals with
+// ${...} substitutions, which templateParams turns into placeholders.
+const endpointRegex = "(?i)([\"'`])(\\/(?:[a-zA-Z0-9_?%&=\\/\\-\\#\\.\\(\\)]|\\$\\{[^}\"'`\\n]{1,60}\\})+)([\"'`])"
+
+var (
+	templateSubst = regexp.MustCompile(`\$\{[^}]*\}`)
+	jsIdentifier  = regexp.MustCompile(`[A-Za-z_$][\w$]*`)
+)
+
+// templateParams replaces the ${...} substitutions of an endpoint with
+// placeholders named after the last identifier substituted, so
+// `/api/orders/${order.id}` is reported as /api/orders/{id}.
+func templateParams(value string) string {
+	if !strings.Contains(value, "${") {
+		return value
+	}
+	return templateSubst.ReplaceAllStringFunc(value, func(subst string) string {
+		names := jsIdentifier.FindAllString(subst[2:len(subst)-1], -1)
+		if len(names) == 0 {
+			return "{param}"
+		}
+		return "{" + names[len(names)-1] + "}"
+	})
+}
+
+const (
+	categoryEndpoint = "endpoint"
+	categoryRealtime = "realtime"
+	categoryEmail    = "email"
+	categoryIPv4     = "ipv4"
+	categoryIPv6     = "ipv6"
+	categoryInternal = "internal-host"
+	categorySpec     = "spec"
+)
+
+type Finding struct {
+	Source   string `json:"source"`
+	Value    string `json:"value"`
+	Category string `json:"category"`
+	// Method lists the HTTP methods for findings that know them, such as
+	// paths parsed from an API specification.
+	Method string `json:"method,omitempty"`
+	// Line (1-based) and Offset locate the first occurrence in the body;
+	// Line is 0 when the position is unknown.
+	Line   int `json:"line,omitempty"`
+	Offset int `json:"offset"`
+	// Note is shown next to the value, e.g. a secret's kind and whether
+	// it was verified as active.
+	Note string `json:"note,omitempty"`
+	// Sink is the call the value is passed to, such as fetch or
+	// axios.post; for an API base, the client it configures.
+	Sink string `json:"sink,omitempty"`
+	// Base is the API base a relative endpoint was resolved under.
+	Base string `json:"base,omitempty"`
+	// Confidence is low, medium or high: how likely the value is real.
+	Confidence string `json:"confidence,omitempty"`
+	// HostInfo describes the host the value names, with -resolve-hosts.
+	HostInfo *hostInfo `json:"host_info,omitempty"`
+	// Context is the code around the value, with -context.
+	Context string `json:"context,omitempty"`
+	// Labels are the -label pairs of the scan, shared by every finding.
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+// lineIndex maps byte offsets of a body to 1-based line numbers.
+type lineIndex []int
+
+func newLineIndex(content string) lineIndex {
+	idx := lineIndex{}
+	for i := 0; i < len(content); i++ {
+		if content[i] == '\n' {
+			idx = append(idx, i)
+		}
+	}
+	return idx
+}
+
+func (idx lineIndex) line(offset int) int {
+	return sort.SearchInts(idx, offset) + 1
+}
+
+type extractionRule struct {
+	category string
+	re       *regexp.Regexp
+	group    int
+	// absolute rules always report URLs resolved against the source, with
+	// websocket rules mapping http/https onto ws/wss.
+	absolute  bool
+	websocket bool
+	// sinks, when set, records the HTTP call each match is passed to.
+	sinks *sinkMatcher
+	// valid, when set, drops matches that the pattern alone can't rule out.
+	valid func(string) bool
+	// name identifies custom rules in warnings and benchmarks.
+	name string
+}
+
+func defaultRules() []extractionRule {
+	return []extractionRule{
+		{category: categoryEndpoint, re: regexp.MustCompile(endpointRegex), group: 2},
+		{category: categoryRealtime, re: regexp.MustCompile("(?i)[\"'`](wss?://[^\"'`\\s]+)[\"'`]"), group: 1, absolute: true},
+		{category: categoryRealtime, re: regexp.MustCompile("new\\s+WebSocket\\(\\s*[\"'`]([^\"'`\\s]+)[\"'`]"), group: 1, absolute: true, websocket: true},
+		{category: categoryRealtime, re: regexp.MustCompile("new\\s+EventSource\\(\\s*[\"'`]([^\"'`\\s]+)[\"'`]"), group: 1, absolute: true},
+	}
+}
+
+var assetSuffixes = []string{".png", ".jpg", ".jpeg", ".gif", ".svg", ".webp", ".css", ".js"}
+
+func validEmail(value string) bool {
+	lower := strings.ToLower(value)
+	for _, suffix := range assetSuffixes {
+		if strings.HasSuffix(lower, suffix) {
+			return false
+		}
+	}
+	return true
+}
+
+func validIPv4(value string) bool {
+	ip := net.ParseIP(value)
+	return ip != nil && ip.To4() != nil
+}
+
+// validIPv6 also wants two hex groups, so the colon runs of CSS
+// (::before, a::after) and similar text aren't read as addresses.
+func validIPv6(value string) bool {
+	groups := 0
+	for _, g := range strings.Split(value, ":") {
+		if g != "" {
+			groups++
+		}
+	}
+	ip := net.ParseIP(value)
+	return groups >= 2 && ip != nil && ip.To4() == nil
+}
+
+// optionalRules maps the names accepted by -extract to the rules they enable.
+var optionalRules = map[string]func() []extractionRule{
+	"email": func() []extractionRule {
+		return []extractionRule{
+			{category: categoryEmail, re: regexp.MustCompile(`[a-zA-Z0-9._%+\-]+@[a-zA-Z0-9.\-]+\.[a-zA-Z]{2,}`), valid: validEmail},
+		}
+	},
+	"ip": func() []extractionRule {
+		return []extractionRule{
+			{category: categoryIPv4, re: regexp.MustCompile(`\b(?:\d{1,3}\.){3}\d{1,3}\b`), valid: validIPv4},
+			{category: categoryIPv6, re: regexp.MustCompile(`(?i)(?:^|[^0-9a-z:.\-])([0-9a-f]{0,4}(?::[0-9a-f]{0,4}){2,7})(?:[^0-9a-z:.\-]|$)`), group: 1, valid: validIPv6},
+		}
+	},
+	// Secrets, JWTs and backend configs need more than one pattern per
+	// match or decoding, so they come from their own extractors instead of
+	// rules; see wantsExtract.
+	"secrets":  func() []extractionRule { return nil },
+	"jwt":      func() []extractionRule { return nil },
+	"backends": func() []extractionRule { return nil },
+	"host": func() []extractionRule {
+		return []extractionRule{
+			{category: categoryInternal, re: regexp.MustCompile(internalHostPattern()), group: 1},
+		}
+	},
+}
+
+// internalSuffixes are the non-public suffixes of internal hostnames.
+var internalSuffixes = []string{"internal", "local", "localdomain", "corp", "lan", "intranet", "intra", "private", "home.arpa"}
+
+// internalHostPattern matches a hostname under an internal suffix where a
+// hostname stands: after a quote, // or @, and before a quote, port, path,
+// query or fragment. Property chains such as config.internal.enabled and
+// public names such as corp.example.com don't match.
+func internalHostPattern() string {
+	suffixes := make([]string, len(internalSuffixes))
+	for i, suffix := range internalSuffixes {
+		suffixes[i] = regexp.QuoteMeta(suffix)
+	}
+	return "(?i)(?:^|[\"'`@]|//)((?:[a-z0-9](?:[a-z0-9\\-]{0,61}[a-z0-9])?\\.)+(?:" + strings.Join(suffixes, "|") + "))(?:[\"'`/:?#]|$)"
+}
+
+// buildRules returns the default rules plus the optional ones named in the
+// comma-separated extract list ("all" enables every optional rule), with
+// endpoint matches recording their sinks.
+func buildRules(extractList string, sinks *sinkMatcher) ([]extractionRule, error) {
+	rules := defaultRules()
+	for i := range rules {
+		if rules[i].category == categoryEndpoint {
+			rules[i].sinks = sinks
+		}
+	}
+	if extractList == "" {
+		return rules, nil
+	}
+	for _, name := range strings.Split(extractList, ",") {
+		name = strings.TrimSpace(strings.ToLower(name))
+		if name == "all" {
+			for _, key := range []string{"email", "ip", "host", "secrets", "jwt", "backends"} {
+				rules = append(rules, optionalRules[key]()...)
+			}
+			continue
+		}
+		build, ok := optionalRules[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown -extract type '%s' (valid: email, ip, host, secrets, jwt, backends, all)", name)
+		}
+		rules = append(rules, build()...)
+	}
+	return rules, nil
+}
+
+// wantsExtract reports whether the extract list enables the named type.
+func wantsExtract(extractList, want string) bool {
+	for _, name := range strings.Split(extractList, ",") {
+		if name = strings.TrimSpace(strings.ToLower(name)); name == want || name == "all" {
+			return true
+		}
+	}
+	return false
+}
+
+// resolvableCategory reports categories holding paths that -r should turn
+// into full URLs.
+func resolvableCategory(category string) bool {
+	switch category {
+	case categoryEndpoint, categorySpec, categoryGRPC, categoryFeed, categoryBase:
+		return true
+	}
+	return false
+}
+
+func resolveAgainst(base *url.URL, value string, websocket bool) string {
+	if base == nil {
+		return value
+	}
+	ref, err := url.Parse(value)
+	if err != nil {
+		return value
+	}
+	resolved := base.ResolveReference(ref)
+	if websocket && !ref.IsAbs() {
+		switch resolved.Scheme {
+		case "http":
+			resolved.Scheme = "ws"
+		case "https":
+			resolved.Scheme = "wss"
+		}
+	}
+	return resolved.String()
+}
+
+// extract applies every rule to body and returns one finding per distinct
+// category and value. HTML bodies additionally yield the links carried by
+// their href, src, action and similar attributes. With stringsOnly, matches
+// in scripts must lie inside a single string or template literal.
+func extract(source, contentType string, body []byte, rules []extractionRule, stringsOnly bool) []Finding {
+	if sourceLang(source) == langVue {
+		if findings, ok := extractVue(source, body, rules, stringsOnly); ok {
+			return findings
+		}
+	}
+	if !isHTML(contentType, body) {
+		return matchRules(source, body, rules, stringsOnly)
+	}
+	return extractHTML(source, body, rules)
+}
+
+func extractHTML(source string, body []byte, rules []extractionRule) []Finding {
+	findings := make([]Finding, 0)
+	seen := make(map[[2]string]struct{})
+	for _, link := range htmlLinks(body) {
+		key := [2]string{categoryEndpoint, link}
+		if _, ok := seen[key]; !ok {
+			seen[key] = struct{}{}
+			findings = append(findings, Finding{Source: source, Value: link, Category: categoryEndpoint})
+		}
+	}
+	for _, f := range matchRules(source, body, rules, false) {
+		if _, ok := seen[[2]string{f.Category, f.Value}]; !ok {
+			findings = append(findings, f)
+		}
+	}
+	return findings
+}
+
+// matchRules runs rules over body, one finding per distinct category and
+// value.
+func matchRules(source string, body []byte, rules []extractionRule, stringsOnly bool) []Finding {
+	return matchLang(source, body, rules, stringsOnly, sourceLang(source))
+}
+
+// matchLang is matchRules for source in lang: TypeScript has its type-only
+// imports blanked, and JSX elements are lexed for -strings-only.
+func matchLang(source string, body []byte, rules []extractionRule, stringsOnly bool, lang string) []Finding {
+	content := string(body)
+	if lang == langTS || lang == langTSX {
+		content = stripTypeOnly(content)
+	}
+	base, _ := url.Parse(source)
+	lines := newLineIndex(content)
+	seen := make(map[[2]string]int)
+	findings := make([]Finding, 0)
+	add := func(f Finding) {
+		key := [2]string{f.Category, f.Value}
+		i, ok := seen[key]
+		if !ok {
+			seen[key] = len(findings)
+			findings = append(findings, f)
+			return
+		}
+		// A value used as a literal first and in a call later still gets
+		// the call's sink.
+		if findings[i].Sink == "" && f.Sink != "" {
+			findings[i].Sink, findings[i].Method = f.Sink, f.Method
+		}
+	}
+	var literals []textSpan
+	restrict := false
+	if stringsOnly {
+		literals, restrict = jsStringSpans(content, isJSXLang(lang)), true
+	}
+	// Rules match the decoded text so escaped endpoints are found and
+	// reported in their plain form; positions still point into the body.
+	decoded, offsets := decodeEscapes(content)
+	instances := axiosInstances(decoded)
+	for _, rule := range rules {
+		for _, loc := range findAllSubmatchIndex(rule.re, decoded) {
+			if len(loc) <= 2*rule.group+1 || loc[2*rule.group] < 0 {
+				continue
+			}
+			start, end := loc[2*rule.group], loc[2*rule.group+1]
+			value := decoded[start:end]
+			sink, method := "", ""
+			if rule.sinks != nil && start > 0 {
+				sink, method = rule.sinks.match(decoded, start-1, instances)
+			}
+			if offsets != nil {
+				start, end = offsets[start], offsets[end-1]+1
+			}
+			if restrict && !within(literals, start, end) {
+				continue
+			}
+			if rule.valid != nil && !rule.valid(value) {
+				continue
+			}
+			if rule.absolute {
+				value = resolveAgainst(base, value, rule.websocket)
+			}
+			if rule.category == categoryEndpoint {
+				value = templateParams(value)
+			}
+			add(Finding{Source: source, Value: value, Category: rule.category, Method: method, Sink: sink, Line: lines.line(start), Offset: start})
+		}
+	}
+	return findings
+}
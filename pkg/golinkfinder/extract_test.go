@@ -0,0 +1,90 @@
+package golinkfinder
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestValidIPv6(t *testing.T) {
+	tests := []struct {
+		value string
+		want  bool
+	}{
+		{"2001:db8::1", true},
+		{"fe80::1ff:fe23:4567:890a", true},
+		{"2001:0db8:0000:0000:0000:ff00:0042:8329", true},
+		{"::1", false},
+		{"::", false},
+		{"a::", false},
+		{"::before", false},
+		{"a::after", false},
+		{"12:34", false},
+		{"1:2:3:4:5:6:7:8:9", false},
+		{"::ffff:192.0.2.1", false},
+		{"192.0.2.1", false},
+	}
+	for _, tt := range tests {
+		if got := validIPv6(tt.value); got != tt.want {
+			t.Errorf("validIPv6(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestInternalHostRule(t *testing.T) {
+	tests := []struct {
+		body string
+		want string
+	}{
+		{`fetch("https://api.internal/v1/users")`, "api.internal"},
+		{`const db = 'db01.prod.corp:5432';`, "db01.prod.corp"},
+		{"ws://chat.intranet/socket", "chat.intranet"},
+		{`"admin@mail.local"`, "mail.local"},
+		{`"router.home.arpa"`, "router.home.arpa"},
+		{`"https://corp.example.com/login"`, ""},
+		{`"https://api.internal.example.com/"`, ""},
+		{`if (config.internal.enabled) {}`, ""},
+		{`return settings.local;`, ""},
+		{`"/assets/app.local"`, ""},
+	}
+	rules := optionalRules["host"]()
+	for _, tt := range tests {
+		var got []string
+		for _, f := range extract("https://example.com/app.js", "application/javascript", []byte(tt.body), rules, false) {
+			got = append(got, f.Value)
+		}
+		if want := []string{tt.want}; tt.want == "" && len(got) > 0 || tt.want != "" && !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: got %q, want %q", tt.body, got, tt.want)
+		}
+	}
+}
+
+func TestTemplateLiteralEndpoints(t *testing.T) {
+	sinks, err := newSinkMatcher(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rules, err := buildRules("", sinks)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body := "fetch(`/api/orders/${id}`);\n" +
+		"axios.get(`/api/users/${user.id}/posts?page=${ encodeURIComponent(page) }`);\n" +
+		"const u = `/api/items/${2 * 3}`;\n" +
+		"const v = '/api/plain';\n"
+	want := map[string]string{
+		"/api/orders/{id}":                  "fetch",
+		"/api/users/{id}/posts?page={page}": "axios.get",
+		"/api/items/{param}":                "",
+		"/api/plain":                        "",
+	}
+	findings := extract("https://example.com/app.js", "application/javascript", []byte(body), rules, false)
+	got := make(map[string]string)
+	for _, f := range findings {
+		if f.Category == categoryEndpoint {
+			got[f.Value] = f.Sink
+		}
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
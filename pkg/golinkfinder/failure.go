@@ -0,0 +1,154 @@
+package golinkfinder
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+	"syscall"
+)
+
+// Error classes of a source that could not be scanned, reported as
+// error_class in JSON output and counted in the summary.
+const (
+	classDNS         = "dns"
+	classTLS         = "tls"
+	classTimeout     = "timeout"
+	classConnection  = "connection"
+	classHTTP4xx     = "http_4xx"
+	classHTTP5xx     = "http_5xx"
+	classRateLimited = "rate_limited"
+	classBlocked     = "blocked"
+	classAuth        = "auth"
+	classTooLarge    = "too_large"
+	classNonText     = "non_text"
+	classOther       = "other"
+)
+
+// retryableClasses are the failures a later attempt may get past.
+var retryableClasses = map[string]bool{classTimeout: true, classConnection: true, classHTTP5xx: true, classRateLimited: true}
+
+// errorClass sorts a fetch error into one of the classes above.
+func errorClass(err error) string {
+	var (
+		statusErr  *statusError
+		authErr    *authRequiredError
+		skipped    *skipError
+		tooLarge   *tooLargeError
+		dnsErr     *net.DNSError
+		netErr     net.Error
+		recordErr  tls.RecordHeaderError
+		certErr    *tls.CertificateVerificationError
+		unknownCA  x509.UnknownAuthorityError
+		hostErr    x509.HostnameError
+		invalidErr x509.CertificateInvalidError
+	)
+	switch {
+	case err == nil:
+		return ""
+	case errors.As(err, &statusErr):
+		switch {
+		case statusErr.challenge != "":
+			return classBlocked
+		case statusErr.code == http.StatusTooManyRequests:
+			return classRateLimited
+		case statusErr.code >= 500:
+			return classHTTP5xx
+		case statusErr.code >= 400:
+			return classHTTP4xx
+		}
+		return classOther
+	case errors.As(err, &authErr):
+		return classAuth
+	case errors.As(err, &skipped):
+		return classNonText
+	case errors.As(err, &tooLarge):
+		return classTooLarge
+	case errors.As(err, &dnsErr):
+		if dnsErr.IsTimeout || dnsErr.IsTemporary {
+			return classTimeout
+		}
+		return classDNS
+	case errors.As(err, &recordErr), errors.As(err, &certErr), errors.As(err, &unknownCA), errors.As(err, &hostErr), errors.As(err, &invalidErr):
+		return classTLS
+	case errors.As(err, &netErr) && netErr.Timeout():
+		return classTimeout
+	case errors.Is(err, syscall.ECONNREFUSED), errors.Is(err, syscall.ECONNRESET), errors.Is(err, io.ErrUnexpectedEOF), errors.Is(err, io.EOF):
+		return classConnection
+	case strings.Contains(err.Error(), "tls: "):
+		return classTLS
+	}
+	return classOther
+}
+
+// tooLargeError marks a body over -max-size.
+type tooLargeError struct {
+	size, limit int64
+}
+
+func (e *tooLargeError) Error() string {
+	if e.size > 0 {
+		return fmt.Sprintf("body of %s exceeds -max-size %s", formatBytes(e.size), formatBytes(e.limit))
+	}
+	return fmt.Sprintf("body exceeds -max-size %s", formatBytes(e.limit))
+}
+
+// challengeBodyRegex matches the interstitials of common WAFs and bot
+// managers.
+var challengeBodyRegex = regexp.MustCompile(`(?i)cf-chl-|cf_chl_|<title>(?:attention required|just a moment)|_Incapsula_Resource|px-captcha|captcha-delivery\.com|/_sec/cp_challenge|awswaf|Request unsuccessful\. Incapsula`)
+
+// blockingChallenge returns which bot protection answered a non-200
+// response, or "". It reads at most 16 KiB of the body.
+func blockingChallenge(resp *http.Response) string {
+	switch {
+	case resp.Header.Get("Cf-Mitigated") == "challenge":
+		return "cloudflare"
+	case resp.Header.Get("X-Amzn-Waf-Action") != "":
+		return "aws waf"
+	case resp.Header.Get("X-Datadome") != "":
+		return "datadome"
+	}
+	if resp.StatusCode != http.StatusForbidden && resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode != http.StatusServiceUnavailable {
+		return ""
+	}
+	head, _ := io.ReadAll(io.LimitReader(resp.Body, 16<<10))
+	if m := challengeBodyRegex.Find(head); m != nil {
+		lower := strings.ToLower(string(m))
+		switch {
+		case strings.Contains(lower, "incapsula"):
+			return "imperva"
+		case strings.Contains(lower, "px-"):
+			return "perimeterx"
+		case strings.Contains(lower, "captcha-delivery"):
+			return "datadome"
+		case strings.Contains(lower, "awswaf"):
+			return "aws waf"
+		case strings.Contains(lower, "_sec/"):
+			return "akamai"
+		}
+		return "cloudflare"
+	}
+	return ""
+}
+
+// writeRetryable saves the targets whose failure class is retryable, one
+// per line.
+func writeRetryable(path string, sources []sourceMeta) error {
+	var b bytes.Buffer
+	for _, m := range sources {
+		if m.Retryable {
+			b.WriteString(m.URL + "\n")
+		}
+	}
+	if err := os.WriteFile(path, b.Bytes(), 0o644); err != nil {
+		return fmt.Errorf("could not write retry list: %v", err)
+	}
+	return nil
+}
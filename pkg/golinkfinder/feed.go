@@ -0,0 +1,107 @@
+package golinkfinder
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"strings"
+)
+
+const categoryFeed = "feed"
+
+// feedRoots maps the root element of a sitemap or feed to the note its
+// URLs are reported with.
+var feedRoots = map[string]string{
+	"urlset":       "sitemap",
+	"sitemapindex": "sitemap index",
+	"rss":          "rss",
+	"RDF":          "rss",
+	"feed":         "atom",
+}
+
+// feedExtractor parses sitemaps, sitemap indexes and RSS/Atom feeds as XML
+// and reports the URLs they list: <loc> entries, <link> elements and Atom
+// link/enclosure hrefs. The regex rules miss these because they aren't
+// quoted. Entries of a sitemap index are themselves sitemaps, which
+// -follow-sitemaps fetches.
+type feedExtractor struct{}
+
+func (feedExtractor) Extract(source, contentType string, body []byte) []Finding {
+	trimmed := bytes.TrimLeft(bytes.TrimPrefix(body, []byte("\xef\xbb\xbf")), " \t\r\n")
+	if !bytes.HasPrefix(trimmed, []byte("<")) || strings.Contains(strings.ToLower(contentType), "javascript") {
+		return nil
+	}
+	lines := newLineIndex(string(body))
+	dec := xml.NewDecoder(bytes.NewReader(body))
+	dec.Strict = false
+
+	note := ""
+	seen := make(map[string]struct{})
+	findings := make([]Finding, 0)
+	add := func(value string, offset int) {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			return
+		}
+		if _, ok := seen[value]; ok {
+			return
+		}
+		seen[value] = struct{}{}
+		findings = append(findings, Finding{Source: source, Value: value, Category: categoryFeed, Line: lines.line(offset), Offset: offset, Note: note})
+	}
+
+	// text collects the character data of the <loc> or <link> being read.
+	var text strings.Builder
+	reading, start := false, 0
+	for {
+		offset := int(dec.InputOffset())
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			// A feed that breaks halfway still yields what came before.
+			break
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			if note == "" {
+				if note = feedRoots[t.Name.Local]; note == "" {
+					return nil
+				}
+				continue
+			}
+			switch t.Name.Local {
+			case "loc", "link":
+				href := ""
+				for _, a := range t.Attr {
+					if a.Name.Local == "href" {
+						href = a.Value
+					}
+				}
+				if href != "" {
+					add(href, offset)
+					continue
+				}
+				reading, start = true, offset
+				text.Reset()
+			case "enclosure", "content":
+				for _, a := range t.Attr {
+					if a.Name.Local == "url" || a.Name.Local == "src" {
+						add(a.Value, offset)
+					}
+				}
+			}
+		case xml.CharData:
+			if reading {
+				text.Write(t)
+			}
+		case xml.EndElement:
+			if reading && (t.Name.Local == "loc" || t.Name.Local == "link") {
+				add(text.String(), start)
+				reading = false
+			}
+		}
+	}
+	return findings
+}
@@ -0,0 +1,113 @@
+package golinkfinder
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+func isHTTPURL(raw string) bool {
+	lower := strings.ToLower(raw)
+	return strings.HasPrefix(lower, "http://") || strings.HasPrefix(lower, "https://")
+}
+
+func isFileURL(raw string) bool {
+	return len(raw) >= 5 && strings.EqualFold(raw[:5], "file:")
+}
+
+// fileURLPath returns the local path of a file:// URL. Only local files
+// are read: the host must be empty or localhost.
+func fileURLPath(raw string) (string, error) {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return "", fmt.Errorf("invalid file URL: %v", err)
+	}
+	if u.Host != "" && !strings.EqualFold(u.Host, "localhost") {
+		return "", fmt.Errorf("file URL on remote host '%s' is not supported", u.Host)
+	}
+	p := u.Path
+	if p == "" {
+		// file:relative/path
+		p = u.Opaque
+	}
+	if p == "" {
+		return "", fmt.Errorf("file URL has no path")
+	}
+	// file:///C:/dir/app.js
+	if len(p) >= 3 && p[0] == '/' && p[2] == ':' {
+		p = p[1:]
+	}
+	return filepath.FromSlash(p), nil
+}
+
+// processFile scans a file:// target from disk, with the same size and
+// content type limits as a fetched one. Its findings are attributed to the
+// file's path.
+func (s *scanSession) processFile(ctx context.Context, job scanJob) linkFinderResult {
+	name, err := fileURLPath(job.url)
+	if err != nil {
+		return linkFinderResult{job: job, sourceURL: job.url, meta: sourceMeta{URL: job.url}, err: err}
+	}
+	findings, meta, err := readAndFindLinks(ctx, name, s.allowedTypes, s.opts.maxSize<<20, s.extract)
+	findings = s.capFindings(findings, &meta)
+	return linkFinderResult{job: job, sourceURL: name, findings: s.annotate(ctx, findings), meta: meta, err: err}
+}
+
+func readAndFindLinks(ctx context.Context, name string, allowedTypes []string, maxSize int64, extract func(source, contentType string, body []byte) []Finding) ([]Finding, sourceMeta, error) {
+	meta := sourceMeta{URL: name}
+	start := time.Now()
+	file, err := os.Open(name)
+	if err != nil {
+		return nil, meta, fmt.Errorf("could not open file: %w", err)
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return nil, meta, fmt.Errorf("could not open file: %w", err)
+	}
+	if info.IsDir() {
+		return nil, meta, &skipError{reason: "is a directory"}
+	}
+	if maxSize > 0 && info.Size() > maxSize {
+		return nil, meta, &tooLargeError{size: info.Size(), limit: maxSize}
+	}
+
+	var r io.Reader = file
+	if maxSize > 0 {
+		r = io.LimitReader(file, maxSize+1)
+	}
+	body, err := io.ReadAll(r)
+	meta.Duration = time.Since(start)
+	meta.Bytes = int64(len(body))
+	if err != nil {
+		return nil, meta, fmt.Errorf("could not read file: %w", err)
+	}
+	if maxSize > 0 && int64(len(body)) > maxSize {
+		return nil, meta, &tooLargeError{limit: maxSize}
+	}
+	meta.Hash = contentHash(body)
+
+	contentType, known := gitExtensions[strings.ToLower(path.Ext(filepath.ToSlash(name)))]
+	if !known {
+		contentType = http.DetectContentType(body)
+		if !strings.HasPrefix(contentType, "text/") {
+			meta.ContentType = contentType
+			return nil, meta, &skipError{reason: "binary file (" + contentType + ")"}
+		}
+	}
+	meta.ContentType = contentType
+	if err := contentTypeAllowed(allowedTypes, contentType); err != nil {
+		return nil, meta, err
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, meta, err
+	}
+	return extract(name, contentType, body), meta, nil
+}
@@ -0,0 +1,88 @@
+package golinkfinder
+
+import (
+	"fmt"
+	"mime"
+	"net/url"
+	"path"
+	"strings"
+)
+
+// skipError marks a target that was deliberately not scanned, as opposed
+// to one that failed.
+type skipError struct {
+	reason string
+}
+
+func (e *skipError) Error() string {
+	return e.reason
+}
+
+// splitList turns "js, .MJS,json" into {"js", "mjs", "json"}.
+func splitList(list string) []string {
+	var items []string
+	for _, item := range strings.Split(list, ",") {
+		item = strings.TrimPrefix(strings.ToLower(strings.TrimSpace(item)), ".")
+		if item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// urlExtension returns the lower-cased extension of u's path, without the
+// dot.
+func urlExtension(u string) string {
+	parsed, err := url.Parse(u)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimPrefix(strings.ToLower(path.Ext(parsed.Path)), ".")
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// filterByExtension drops the URL jobs whose extension isn't in include
+// (when given) or is in exclude, returning what is left and how many were
+// dropped. Supplied content is always kept.
+func filterByExtension(jobs []scanJob, include, exclude []string) ([]scanJob, int) {
+	if len(include) == 0 && len(exclude) == 0 {
+		return jobs, 0
+	}
+	kept := jobs[:0]
+	for _, job := range jobs {
+		ext := urlExtension(job.url)
+		if job.body == nil && ((len(include) > 0 && !containsString(include, ext)) || containsString(exclude, ext)) {
+			continue
+		}
+		kept = append(kept, job)
+	}
+	return kept, len(jobs) - len(kept)
+}
+
+// contentTypeAllowed reports whether contentType matches the -content-type
+// allowlist, whose entries match anywhere in the media type ("javascript"
+// allows application/javascript and text/javascript). An empty list or a
+// missing header allows everything.
+func contentTypeAllowed(allowed []string, contentType string) error {
+	if len(allowed) == 0 || contentType == "" {
+		return nil
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType = strings.ToLower(contentType)
+	}
+	for _, a := range allowed {
+		if strings.Contains(mediaType, a) {
+			return nil
+		}
+	}
+	return &skipError{reason: fmt.Sprintf("content type %s not allowed", mediaType)}
+}
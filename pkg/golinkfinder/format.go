@@ -0,0 +1,109 @@
+package golinkfinder
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+)
+
+// formatRecord is what a -format template sees for each finding, or for
+// each probed endpoint with the probe's answer filled in.
+type formatRecord struct {
+	Source   string `json:"source,omitempty"`
+	Endpoint string `json:"endpoint"`
+	Value    string `json:"-"`
+	// Template is Endpoint with parameter-looking segments collapsed.
+	Template string `json:"template"`
+	Category string `json:"category,omitempty"`
+	Method   string `json:"method,omitempty"`
+	Line     int    `json:"line,omitempty"`
+	Offset   int    `json:"offset,omitempty"`
+	Note     string `json:"note,omitempty"`
+	Base     string `json:"base,omitempty"`
+	// Sink is the call the endpoint is passed to, e.g. fetch.
+	Sink string `json:"sink,omitempty"`
+	// Confidence is low, medium or high.
+	Confidence string `json:"confidence,omitempty"`
+	// HostInfo holds the addresses and AS of the host, with -resolve-hosts.
+	HostInfo *hostInfo `json:"host_info,omitempty"`
+	// Context is the code around the value, with -context.
+	Context string `json:"context,omitempty"`
+	// Labels are the -label pairs of the scan.
+	Labels map[string]string `json:"labels,omitempty"`
+	// Status and Length are only set when probing; Status is 0 otherwise.
+	Status int    `json:"status,omitempty"`
+	Length int64  `json:"length,omitempty"`
+	Allow  string `json:"allow,omitempty"`
+	Error  string `json:"error,omitempty"`
+	// ErrorClass sorts Error, e.g. dns, tls, timeout or connection.
+	ErrorClass string `json:"error_class,omitempty"`
+	// The Source* fields describe how the finding's source was fetched.
+	SourceStatus int    `json:"source_status,omitempty"`
+	SourceType   string `json:"source_content_type,omitempty"`
+	SourceBytes  int64  `json:"source_bytes,omitempty"`
+	SourceMillis int64  `json:"source_ms,omitempty"`
+}
+
+func findingRecord(f Finding) formatRecord {
+	return formatRecord{
+		Source:     f.Source,
+		Endpoint:   f.Value,
+		Value:      f.Value,
+		Template:   templatePath(f.Value),
+		Category:   f.Category,
+		Method:     f.Method,
+		Line:       f.Line,
+		Offset:     f.Offset,
+		Note:       f.Note,
+		Base:       f.Base,
+		Sink:       f.Sink,
+		Confidence: f.Confidence,
+		HostInfo:   f.HostInfo,
+		Context:    f.Context,
+		Labels:     f.Labels,
+	}
+}
+
+func (rec formatRecord) withSource(m sourceMeta) formatRecord {
+	rec.SourceStatus = m.Status
+	rec.SourceType = m.ContentType
+	rec.SourceBytes = m.Bytes
+	rec.SourceMillis = m.Duration.Milliseconds()
+	return rec
+}
+
+// parseFormat compiles a -format template. Every record is one line, so a
+// trailing newline is added when the template lacks one.
+func parseFormat(text string) (*template.Template, error) {
+	text = strings.NewReplacer(`\t`, "\t", `\n`, "\n").Replace(text)
+	if !strings.HasSuffix(text, "\n") {
+		text += "\n"
+	}
+	t, err := template.New("format").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid -format template: %v", err)
+	}
+	return t, nil
+}
+
+func printFormatted(w io.Writer, t *template.Template, rec formatRecord) {
+	if err := t.Execute(w, rec); err != nil {
+		fatal(fmt.Errorf("could not execute -format template: %v", err))
+	}
+}
+
+// printRecord writes rec to the findings stream as a -format line, a JSON
+// line with -jsonl, or its bare value.
+func printRecord(t *template.Template, jsonl bool, rec formatRecord) {
+	switch {
+	case t != nil:
+		printFormatted(findingsOut, t, rec)
+	case jsonl:
+		data, _ := json.Marshal(rec)
+		fmt.Fprintf(findingsOut, "%s\n", data)
+	default:
+		fmt.Fprintln(findingsOut, rec.Value)
+	}
+}
@@ -0,0 +1,209 @@
+package golinkfinder
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path"
+	"strings"
+)
+
+// gitExtensions are the files scanned in a -git repository.
+var gitExtensions = map[string]string{
+	".js":   "application/javascript",
+	".mjs":  "application/javascript",
+	".cjs":  "application/javascript",
+	".jsx":  "application/javascript",
+	".ts":   "application/javascript",
+	".tsx":  "application/javascript",
+	".vue":  "text/html",
+	".html": "text/html",
+	".htm":  "text/html",
+	".json": "application/json",
+}
+
+// gitMaxFileSize skips committed build artifacts and fixtures too large to
+// be hand-written source.
+const gitMaxFileSize = 10 << 20
+
+func gitCommand(dir string, args ...string) ([]byte, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git %s: %v: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return out, nil
+}
+
+func isLocalRepo(repo string) bool {
+	info, err := os.Stat(repo)
+	return err == nil && info.IsDir()
+}
+
+// gitJobs turns the files of a repository at HEAD into scan jobs. Remote
+// repositories are cloned into a temporary directory, shallowly unless
+// history is wanted. With history, the lines each commit removed from
+// those files become extra jobs so deleted endpoints are reported too.
+func gitJobs(repo string, history bool) ([]scanJob, error) {
+	dir := repo
+	if !isLocalRepo(repo) {
+		tmp, err := os.MkdirTemp("", "golinkfinder-git-")
+		if err != nil {
+			return nil, err
+		}
+		defer os.RemoveAll(tmp)
+		args := []string{"clone", "--quiet", "--no-checkout"}
+		if !history {
+			args = append(args, "--depth", "1")
+		}
+		if _, err := gitCommand("", append(args, "--", repo, tmp)...); err != nil {
+			return nil, err
+		}
+		dir = tmp
+	}
+
+	rev, err := gitCommand(dir, "rev-parse", "--short", "HEAD")
+	if err != nil {
+		return nil, err
+	}
+	head := strings.TrimSpace(string(rev))
+
+	// Reading blobs through git keeps uncommitted changes out of a local
+	// repository's results and needs no checkout for a clone.
+	list, err := gitCommand(dir, "ls-tree", "-r", "-z", "--long", "HEAD")
+	if err != nil {
+		return nil, err
+	}
+	jobs := make([]scanJob, 0)
+	for _, entry := range bytes.Split(list, []byte{0}) {
+		// <mode> SP <type> SP <object> SP+ <size> TAB <path>
+		meta, file, ok := strings.Cut(string(entry), "\t")
+		fields := strings.Fields(meta)
+		if !ok || len(fields) != 4 || fields[1] != "blob" {
+			continue
+		}
+		contentType, wanted := gitExtensions[strings.ToLower(path.Ext(file))]
+		if !wanted || fields[3] == "-" || len(fields[3]) > 8 {
+			continue
+		}
+		var size int
+		fmt.Sscan(fields[3], &size)
+		if size > gitMaxFileSize {
+			continue
+		}
+		body, err := gitCommand(dir, "cat-file", "blob", fields[2])
+		if err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, scanJob{url: fmt.Sprintf("%s@%s:%s", repo, head, file), contentType: contentType, body: body})
+	}
+
+	if history {
+		removed, err := gitRemovedLines(dir, repo)
+		if err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, removed...)
+	}
+	return jobs, nil
+}
+
+// gitRemovedLines returns one job per commit and file holding the lines
+// that commit deleted from a scanned file type. The log is read as git
+// writes it, as the history of a large repository can run into
+// gigabytes; a line longer than gitMaxFileSize is skipped.
+func gitRemovedLines(dir, repo string) ([]scanJob, error) {
+	args := []string{"log", "--all", "-p", "--no-color", "--no-renames", "--format=commit %h", "--"}
+	for ext := range gitExtensions {
+		args = append(args, "*"+ext)
+	}
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("git log: %v", err)
+	}
+
+	jobs := make([]scanJob, 0)
+	var commit, file string
+	var removed bytes.Buffer
+	flush := func() {
+		if removed.Len() > 0 && file != "" {
+			jobs = append(jobs, scanJob{
+				url:         fmt.Sprintf("%s@%s:%s (removed)", repo, commit, file),
+				contentType: gitExtensions[strings.ToLower(path.Ext(file))],
+				body:        append([]byte(nil), removed.Bytes()...),
+			})
+		}
+		removed.Reset()
+	}
+	reader := bufio.NewReaderSize(out, 64<<10)
+	var buf []byte
+	for {
+		var ok bool
+		buf, ok, err = readGitLine(reader, buf, gitMaxFileSize)
+		if err != nil {
+			break
+		}
+		if !ok {
+			continue
+		}
+		line := string(buf)
+		switch {
+		case strings.HasPrefix(line, "commit "):
+			flush()
+			commit = strings.TrimPrefix(line, "commit ")
+			file = ""
+		case strings.HasPrefix(line, "--- "):
+			flush()
+			file = strings.TrimPrefix(strings.TrimPrefix(line, "--- "), "a/")
+			if file == "/dev/null" {
+				file = ""
+			}
+		case strings.HasPrefix(line, "-") && file != "":
+			removed.WriteString(line[1:])
+			removed.WriteByte('\n')
+		}
+	}
+	flush()
+	if err != io.EOF {
+		cmd.Process.Kill()
+		cmd.Wait()
+		return nil, fmt.Errorf("git log: %v", err)
+	}
+	if err := cmd.Wait(); err != nil {
+		return nil, fmt.Errorf("git log: %v: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return jobs, nil
+}
+
+// readGitLine reads the next line of r into buf. ok is false for a line
+// longer than max, which is read past but not kept.
+func readGitLine(r *bufio.Reader, buf []byte, max int) (line []byte, ok bool, err error) {
+	buf, ok = buf[:0], true
+	for {
+		chunk, more, err := r.ReadLine()
+		if err != nil {
+			return buf, false, err
+		}
+		if ok && len(buf)+len(chunk) <= max {
+			buf = append(buf, chunk...)
+		} else {
+			ok, buf = false, buf[:0]
+		}
+		if !more {
+			return buf, ok, nil
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package golinkfinder
+
+import (
+	"bufio"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestReadGitLine(t *testing.T) {
+	long := strings.Repeat("x", 100)
+	r := bufio.NewReaderSize(strings.NewReader("short\n"+long+"\n-after\nlast"), 16)
+	var got []string
+	var buf []byte
+	for {
+		var ok bool
+		var err error
+		buf, ok, err = readGitLine(r, buf, 64)
+		if err != nil {
+			break
+		}
+		if ok {
+			got = append(got, string(buf))
+		}
+	}
+	if want := "short|-after|last"; strings.Join(got, "|") != want {
+		t.Errorf("got lines %q, want %q", strings.Join(got, "|"), want)
+	}
+}
+
+func TestGitJobsHistory(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	dir := t.TempDir()
+	git := func(args ...string) {
+		cmd := exec.Command("git", append([]string{"-c", "user.name=t", "-c", "user.email=t@example.com"}, args...)...)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %s: %v: %s", args[0], err, out)
+		}
+	}
+	write := func(content string) {
+		if err := os.WriteFile(filepath.Join(dir, "app.js"), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	git("init", "-q")
+	write("fetch('/api/old');\nfetch('/api/kept');\n")
+	git("add", "app.js")
+	git("commit", "-q", "-m", "add")
+	write("fetch('/api/kept');\n")
+	git("commit", "-q", "-am", "remove")
+
+	jobs, err := gitJobs(dir, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var head, removed string
+	for _, job := range jobs {
+		if strings.HasSuffix(job.url, "(removed)") {
+			removed += string(job.body)
+		} else {
+			head += string(job.body)
+		}
+	}
+	if head != "fetch('/api/kept');\n" || removed != "fetch('/api/old');\n" {
+		t.Errorf("got HEAD %q and removed %q", head, removed)
+	}
+}
+
+func TestGitJobsOptionLikeRepo(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	// git must look for a repository by that name rather than take it as
+	// an option and clone the temporary directory.
+	repo := "--upload-pack=touch " + filepath.Join(t.TempDir(), "ran")
+	_, err := gitJobs(repo, false)
+	if err == nil || !strings.Contains(err.Error(), repo) {
+		t.Errorf("gitJobs(%q) = %v, want an error about that repository", repo, err)
+	}
+}
@@ -0,0 +1,76 @@
+package golinkfinder
+
+import (
+	"regexp"
+	"strings"
+)
+
+const categoryGRPC = "grpc"
+
+var (
+	// '/helloworld.Greeter/SayHello' as emitted by protoc-gen-grpc-web.
+	grpcPathRegex = regexp.MustCompile(`["'` + "`" + `]/([a-zA-Z_]\w*(?:\.[a-zA-Z_]\w*)+)/([A-Z]\w*)["'` + "`" + `]`)
+	// improbable-eng/grpc-web: Greeter.serviceName = "helloworld.Greeter" and
+	// Greeter.SayHello = { methodName: "SayHello", service: Greeter, ... }.
+	grpcServiceNameRegex = regexp.MustCompile(`(\w+)\.serviceName\s*=\s*["']([\w.]+)["']`)
+	grpcMethodNameRegex  = regexp.MustCompile(`methodName\s*:\s*["'](\w+)["']\s*,\s*service\s*:\s*(\w+)`)
+	// Connect-ES and protobuf-ts service descriptors: typeName: "acme.v1.ElizaService",
+	// methods: { say: { name: "Say", ... } }.
+	grpcTypeNameRegex   = regexp.MustCompile(`typeName\s*:\s*["']([\w.]+)["']`)
+	grpcDescMethodRegex = regexp.MustCompile(`\bname\s*:\s*["']([A-Z]\w*)["']`)
+)
+
+// grpcWindow bounds how far after a typeName the method names are looked for.
+const grpcWindow = 4096
+
+type grpcExtractor struct{}
+
+func (grpcExtractor) Extract(source, contentType string, body []byte) []Finding {
+	content := string(body)
+	seen := make(map[string]struct{})
+	findings := make([]Finding, 0)
+	add := func(service, method string) {
+		value := "/" + service + "/" + method
+		if _, ok := seen[value]; ok {
+			return
+		}
+		seen[value] = struct{}{}
+		findings = append(findings, Finding{Source: source, Value: value, Category: categoryGRPC, Method: "POST"})
+	}
+
+	for _, m := range grpcPathRegex.FindAllStringSubmatch(content, -1) {
+		add(m[1], m[2])
+	}
+
+	services := make(map[string]string)
+	for _, m := range grpcServiceNameRegex.FindAllStringSubmatch(content, -1) {
+		services[m[1]] = m[2]
+	}
+	for _, m := range grpcMethodNameRegex.FindAllStringSubmatch(content, -1) {
+		if service, ok := services[m[2]]; ok {
+			add(service, m[1])
+		}
+	}
+
+	locs := grpcTypeNameRegex.FindAllStringSubmatchIndex(content, -1)
+	for i, loc := range locs {
+		end := loc[1] + grpcWindow
+		if i+1 < len(locs) && locs[i+1][0] < end {
+			end = locs[i+1][0]
+		}
+		if end > len(content) {
+			end = len(content)
+		}
+		block := content[loc[1]:end]
+		// Message descriptors share the typeName shape; only service
+		// descriptors carry a methods table.
+		if !strings.Contains(block, "methods") {
+			continue
+		}
+		service := content[loc[2]:loc[3]]
+		for _, m := range grpcDescMethodRegex.FindAllStringSubmatch(block, -1) {
+			add(service, m[1])
+		}
+	}
+	return findings
+}
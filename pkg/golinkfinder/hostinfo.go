@@ -0,0 +1,211 @@
+package golinkfinder
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// hostInfo is what -resolve-hosts learns about the host of a finding: its
+// addresses, the AS announcing the first one, and whether any is private
+// (RFC 1918, loopback, link-local, ULA), which usually means an internal
+// service leaked into a public bundle.
+type hostInfo struct {
+	Host    string   `json:"host"`
+	IPs     []string `json:"ips,omitempty"`
+	ASN     string   `json:"asn,omitempty"`
+	Org     string   `json:"org,omitempty"`
+	Private bool     `json:"private,omitempty"`
+	Error   string   `json:"error,omitempty"`
+}
+
+// findingHost returns the hostname a finding names, or "".
+func findingHost(f Finding) string {
+	if f.Category == categoryInternal {
+		return strings.ToLower(f.Value)
+	}
+	if !strings.Contains(f.Value, "://") {
+		return ""
+	}
+	u, err := url.Parse(f.Value)
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(u.Hostname())
+}
+
+// asnRange is one row of an ip2asn table.
+type asnRange struct {
+	start, end *big.Int
+	asn, org   string
+}
+
+// asnDB is an offline IP-to-ASN table in the ip2asn TSV format (iptoasn.com):
+// range_start, range_end, AS number, country, AS description.
+type asnDB struct {
+	ranges []asnRange
+}
+
+func loadASNDB(path string) (*asnDB, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not open ASN database: %v", err)
+	}
+	defer file.Close()
+	db := &asnDB{}
+	scanner := bufio.NewScanner(file)
+	for n := 1; scanner.Scan(); n++ {
+		fields := strings.Split(scanner.Text(), "\t")
+		if len(fields) < 5 || fields[2] == "0" {
+			continue
+		}
+		start, end := net.ParseIP(fields[0]), net.ParseIP(fields[1])
+		if start == nil || end == nil {
+			return nil, fmt.Errorf("%s:%d: not an ip2asn row", path, n)
+		}
+		db.ranges = append(db.ranges, asnRange{start: ipInt(start), end: ipInt(end), asn: "AS" + fields[2], org: fields[4]})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("could not read ASN database: %v", err)
+	}
+	sort.Slice(db.ranges, func(i, j int) bool { return db.ranges[i].start.Cmp(db.ranges[j].start) < 0 })
+	return db, nil
+}
+
+func ipInt(ip net.IP) *big.Int {
+	if v4 := ip.To4(); v4 != nil {
+		ip = v4.To16()
+	}
+	return new(big.Int).SetBytes(ip.To16())
+}
+
+func (db *asnDB) lookup(ip net.IP) (string, string) {
+	v := ipInt(ip)
+	i := sort.Search(len(db.ranges), func(i int) bool { return db.ranges[i].start.Cmp(v) > 0 })
+	if i == 0 {
+		return "", ""
+	}
+	r := db.ranges[i-1]
+	if v.Cmp(r.end) > 0 {
+		return "", ""
+	}
+	return r.asn, r.org
+}
+
+// hostEnricher resolves hosts for -resolve-hosts, with -asn-db or -asn-api
+// for the AS of each address.
+type hostEnricher struct {
+	resolver *net.Resolver
+	asn      *asnDB
+	// api is a URL with {ip}, answering JSON with "asn" and "org" fields.
+	api    string
+	client *http.Client
+}
+
+func newHostEnricher(o *scanOptions, client *http.Client) (*hostEnricher, error) {
+	if !o.resolveHosts {
+		return nil, nil
+	}
+	e := &hostEnricher{resolver: net.DefaultResolver, api: o.asnAPI, client: client}
+	if o.asnAPI != "" && !strings.Contains(o.asnAPI, "{ip}") {
+		return nil, fmt.Errorf("-asn-api must contain {ip}")
+	}
+	if o.asnDB != "" {
+		db, err := loadASNDB(o.asnDB)
+		if err != nil {
+			return nil, err
+		}
+		e.asn = db
+	}
+	return e, nil
+}
+
+func (e *hostEnricher) lookup(ctx context.Context, host string) *hostInfo {
+	info := &hostInfo{Host: host}
+	var ips []net.IP
+	if ip := net.ParseIP(host); ip != nil {
+		ips = []net.IP{ip}
+	} else {
+		ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		addrs, err := e.resolver.LookupIPAddr(ctx, host)
+		cancel()
+		if err != nil {
+			info.Error = errorClass(err)
+			return info
+		}
+		for _, a := range addrs {
+			ips = append(ips, a.IP)
+		}
+	}
+	for _, ip := range ips {
+		info.IPs = append(info.IPs, ip.String())
+		if ip.IsPrivate() || ip.IsLoopback() || ip.IsLinkLocalUnicast() {
+			info.Private = true
+		}
+	}
+	if len(ips) == 0 || info.Private {
+		return info
+	}
+	switch {
+	case e.asn != nil:
+		info.ASN, info.Org = e.asn.lookup(ips[0])
+	case e.api != "":
+		info.ASN, info.Org = e.lookupAPI(ctx, ips[0])
+	}
+	return info
+}
+
+func (e *hostEnricher) lookupAPI(ctx context.Context, ip net.IP) (string, string) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.ReplaceAll(e.api, "{ip}", ip.String()), nil)
+	if err != nil {
+		return "", ""
+	}
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return "", ""
+	}
+	defer resp.Body.Close()
+	var answer struct {
+		ASN interface{} `json:"asn"`
+		Org string      `json:"org"`
+	}
+	if resp.StatusCode != http.StatusOK || json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&answer) != nil {
+		return "", ""
+	}
+	asn := ""
+	switch v := answer.ASN.(type) {
+	case string:
+		asn = v
+	case float64:
+		asn = fmt.Sprintf("AS%d", int64(v))
+	}
+	return asn, answer.Org
+}
+
+// enrichHosts attaches the host info of every finding naming a host,
+// looking each host up once per session.
+func (s *scanSession) enrichHosts(ctx context.Context, findings []Finding) {
+	for i, f := range findings {
+		host := findingHost(f)
+		if host == "" {
+			continue
+		}
+		if info, ok := s.hostInfos.Load(host); ok {
+			findings[i].HostInfo = info.(*hostInfo)
+			continue
+		}
+		info := s.hosts.lookup(ctx, host)
+		s.hostInfos.Store(host, info)
+		findings[i].HostInfo = info
+	}
+}
@@ -0,0 +1,77 @@
+package golinkfinder
+
+import (
+	"bytes"
+	"net/http"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+var linkAttributes = map[string]bool{
+	"href":       true,
+	"src":        true,
+	"action":     true,
+	"formaction": true,
+	"data-url":   true,
+	"srcset":     true,
+}
+
+func isHTML(contentType string, body []byte) bool {
+	if contentType == "" {
+		contentType = http.DetectContentType(body)
+	}
+	return strings.Contains(strings.ToLower(contentType), "html")
+}
+
+// linkValue filters out attribute values that never point at an endpoint.
+func linkValue(value string) (string, bool) {
+	value = strings.TrimSpace(value)
+	lower := strings.ToLower(value)
+	if value == "" || strings.HasPrefix(value, "#") {
+		return "", false
+	}
+	for _, scheme := range []string{"javascript:", "mailto:", "tel:", "data:", "blob:"} {
+		if strings.HasPrefix(lower, scheme) {
+			return "", false
+		}
+	}
+	return value, true
+}
+
+// htmlLinks tokenizes an HTML document and returns the link-bearing
+// attribute values in document order, expanding srcset candidates.
+func htmlLinks(body []byte) []string {
+	links := make([]string, 0)
+	z := html.NewTokenizer(bytes.NewReader(body))
+	for {
+		switch z.Next() {
+		case html.ErrorToken:
+			return links
+		case html.StartTagToken, html.SelfClosingTagToken:
+			_, hasAttr := z.TagName()
+			for hasAttr {
+				var key, val []byte
+				key, val, hasAttr = z.TagAttr()
+				name := string(key)
+				if !linkAttributes[name] {
+					continue
+				}
+				candidates := []string{string(val)}
+				if name == "srcset" {
+					candidates = candidates[:0]
+					for _, candidate := range strings.Split(string(val), ",") {
+						if fields := strings.Fields(candidate); len(fields) > 0 {
+							candidates = append(candidates, fields[0])
+						}
+					}
+				}
+				for _, candidate := range candidates {
+					if link, ok := linkValue(candidate); ok {
+						links = append(links, link)
+					}
+				}
+			}
+		}
+	}
+}
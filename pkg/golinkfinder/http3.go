@@ -0,0 +1,60 @@
+package golinkfinder
+
+import (
+	"crypto/tls"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/quic-go/quic-go"
+	"github.com/quic-go/quic-go/http3"
+)
+
+// quicHandshakeTimeout bounds how long a host that doesn't speak QUIC can
+// hold up the fallback.
+const quicHandshakeTimeout = 3 * time.Second
+
+// http3Transport tries HTTPS requests over HTTP/3 first and falls back to
+// the regular transport (HTTP/2 or HTTP/1.1) when QUIC fails. Hosts that
+// failed once go straight to the fallback afterwards.
+type http3Transport struct {
+	h3       *http3.Transport
+	fallback http.RoundTripper
+	broken   sync.Map
+}
+
+func newHTTP3Transport(tlsConfig *tls.Config, fallback http.RoundTripper) *http3Transport {
+	return &http3Transport{
+		h3: &http3.Transport{
+			TLSClientConfig: tlsConfig.Clone(),
+			QUICConfig:      &quic.Config{HandshakeIdleTimeout: quicHandshakeTimeout},
+		},
+		fallback: fallback,
+	}
+}
+
+func (t *http3Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// A request body can only be replayed on the fallback if it can be
+	// recreated.
+	replayable := req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+	if req.URL.Scheme != "https" || !replayable {
+		return t.fallback.RoundTrip(req)
+	}
+	if _, broken := t.broken.Load(req.URL.Host); broken {
+		return t.fallback.RoundTrip(req)
+	}
+	resp, err := t.h3.RoundTrip(req)
+	if err == nil {
+		return resp, nil
+	}
+	t.broken.Store(req.URL.Host, struct{}{})
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		req = req.Clone(req.Context())
+		req.Body = body
+	}
+	return t.fallback.RoundTrip(req)
+}
@@ -0,0 +1,229 @@
+package golinkfinder
+
+import (
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptrace"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// timing accumulates one phase of the requests -debug-http traced.
+type timing struct {
+	n     int
+	total time.Duration
+	max   time.Duration
+}
+
+func (t *timing) add(d time.Duration) {
+	t.n++
+	t.total += d
+	if d > t.max {
+		t.max = d
+	}
+}
+
+// roundTime keeps loopback and LAN timings readable.
+func roundTime(d time.Duration) time.Duration {
+	if d < time.Millisecond {
+		return d.Round(time.Microsecond)
+	}
+	return d.Round(time.Millisecond)
+}
+
+func (t timing) String() string {
+	if t.n == 0 {
+		return "none"
+	}
+	return fmt.Sprintf("%d, avg %s, max %s", t.n, roundTime(t.total/time.Duration(t.n)), roundTime(t.max))
+}
+
+type hostTiming struct {
+	requests, conns int
+	ttfb            time.Duration
+}
+
+// httpDebug traces every request of a session for -debug-http: whether it
+// reused a connection, its DNS, connect, TLS and first-byte times and the
+// protocol answering, logged as it completes and totalled by report.
+type httpDebug struct {
+	mu                sync.Mutex
+	out               io.Writer
+	requests, errors  int
+	reused, conns     int
+	dns, connect, tls timing
+	ttfb              timing
+	protocols         map[string]int
+	hosts             map[string]*hostTiming
+	tlsVersions       map[string]int
+}
+
+func newHTTPDebug(out io.Writer) *httpDebug {
+	return &httpDebug{out: out, protocols: make(map[string]int), hosts: make(map[string]*hostTiming), tlsVersions: make(map[string]int)}
+}
+
+// requestTrace is what one request's trace hooks saw.
+type requestTrace struct {
+	mu                                   sync.Mutex
+	start, dnsStart, connStart, tlsStart time.Time
+	dns, connect, tls, ttfb              time.Duration
+	gotConn, reused                      bool
+	idle                                 time.Duration
+	tlsVersion                           uint16
+}
+
+func (rt *requestTrace) hooks() *httptrace.ClientTrace {
+	lock := func(f func()) {
+		rt.mu.Lock()
+		f()
+		rt.mu.Unlock()
+	}
+	return &httptrace.ClientTrace{
+		DNSStart: func(httptrace.DNSStartInfo) { lock(func() { rt.dnsStart = time.Now() }) },
+		DNSDone:  func(httptrace.DNSDoneInfo) { lock(func() { rt.dns = time.Since(rt.dnsStart) }) },
+		// Happy eyeballs may dial several addresses; the last to finish
+		// is the one kept.
+		ConnectStart:      func(string, string) { lock(func() { rt.connStart = time.Now() }) },
+		ConnectDone:       func(string, string, error) { lock(func() { rt.connect = time.Since(rt.connStart) }) },
+		TLSHandshakeStart: func() { lock(func() { rt.tlsStart = time.Now() }) },
+		TLSHandshakeDone: func(state tls.ConnectionState, _ error) {
+			lock(func() { rt.tls, rt.tlsVersion = time.Since(rt.tlsStart), state.Version })
+		},
+		GotConn: func(info httptrace.GotConnInfo) {
+			lock(func() { rt.gotConn, rt.reused, rt.idle = true, info.Reused, info.IdleTime })
+		},
+		GotFirstResponseByte: func() { lock(func() { rt.ttfb = time.Since(rt.start) }) },
+	}
+}
+
+// debugTransport traces every request sent, retries included.
+type debugTransport struct {
+	next  http.RoundTripper
+	debug *httpDebug
+}
+
+func (t *debugTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	rt := &requestTrace{start: time.Now()}
+	req = req.WithContext(httptrace.WithClientTrace(req.Context(), rt.hooks()))
+	resp, err := t.next.RoundTrip(req)
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+	t.debug.record(req, resp, err, rt)
+	return resp, err
+}
+
+func (d *httpDebug) record(req *http.Request, resp *http.Response, err error, rt *requestTrace) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.requests++
+	host := d.hosts[req.URL.Host]
+	if host == nil {
+		host = &hostTiming{}
+		d.hosts[req.URL.Host] = host
+	}
+	host.requests++
+
+	var line strings.Builder
+	fmt.Fprintf(&line, "%s %s", req.Method, req.URL.Redacted())
+	if err != nil {
+		d.errors++
+		fmt.Fprintf(&line, " error: %s", errorClass(err))
+	} else {
+		d.protocols[resp.Proto]++
+		fmt.Fprintf(&line, " %s %d", resp.Proto, resp.StatusCode)
+	}
+	switch {
+	case rt.reused:
+		d.reused++
+		fmt.Fprintf(&line, " reused (idle %s)", roundTime(rt.idle))
+	case rt.gotConn:
+		d.conns++
+		host.conns++
+		line.WriteString(" new conn")
+	}
+	if rt.dns > 0 {
+		d.dns.add(rt.dns)
+		fmt.Fprintf(&line, " dns=%s", roundTime(rt.dns))
+	}
+	if rt.connect > 0 {
+		d.connect.add(rt.connect)
+		fmt.Fprintf(&line, " connect=%s", roundTime(rt.connect))
+	}
+	if rt.tls > 0 {
+		d.tls.add(rt.tls)
+		d.tlsVersions[tls.VersionName(rt.tlsVersion)]++
+		fmt.Fprintf(&line, " tls=%s", roundTime(rt.tls))
+	}
+	if rt.ttfb > 0 {
+		d.ttfb.add(rt.ttfb)
+		host.ttfb += rt.ttfb
+		fmt.Fprintf(&line, " ttfb=%s", roundTime(rt.ttfb))
+	}
+	fmt.Fprintf(d.out, "%s[debug]%s %s\n", c.Blue, c.End, line.String())
+}
+
+// report prints the totals of every traced request and the hosts slowest
+// to answer.
+func (d *httpDebug) report() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.requests == 0 {
+		return
+	}
+	w := d.out
+	fmt.Fprintf(w, "\n%s%sHTTP diagnostics:%s\n", c.Bold, c.Yellow, c.End)
+	fmt.Fprintf(w, "  Requests:    %d (%d failed)\n", d.requests, d.errors)
+	fmt.Fprintf(w, "  Connections: %d new, %d reused (%.0f%% reuse)\n", d.conns, d.reused, 100*float64(d.reused)/float64(d.requests))
+	fmt.Fprintf(w, "  Protocols:   %s\n", countList(d.protocols))
+	if len(d.tlsVersions) > 0 {
+		fmt.Fprintf(w, "  TLS:         %s\n", countList(d.tlsVersions))
+	}
+	fmt.Fprintf(w, "  DNS:         %s\n", d.dns)
+	fmt.Fprintf(w, "  Connect:     %s\n", d.connect)
+	fmt.Fprintf(w, "  Handshake:   %s\n", d.tls)
+	fmt.Fprintf(w, "  First byte:  %s\n", d.ttfb)
+
+	hosts := make([]string, 0, len(d.hosts))
+	for host := range d.hosts {
+		hosts = append(hosts, host)
+	}
+	avg := func(host string) time.Duration {
+		h := d.hosts[host]
+		return h.ttfb / time.Duration(h.requests)
+	}
+	sort.Slice(hosts, func(i, j int) bool { return avg(hosts[i]) > avg(hosts[j]) })
+	if len(hosts) > 5 {
+		hosts = hosts[:5]
+	}
+	fmt.Fprintf(w, "  Slowest hosts (avg first byte):\n")
+	for _, host := range hosts {
+		h := d.hosts[host]
+		fmt.Fprintf(w, "    %-40s %8s  %d requests, %d connections\n", host, roundTime(avg(host)), h.requests, h.conns)
+	}
+}
+
+// countList formats counts as "key n, key n", most frequent first.
+func countList(counts map[string]int) string {
+	keys := make([]string, 0, len(counts))
+	for k := range counts {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if counts[keys[i]] != counts[keys[j]] {
+			return counts[keys[i]] > counts[keys[j]]
+		}
+		return keys[i] < keys[j]
+	})
+	parts := make([]string, len(keys))
+	for i, k := range keys {
+		parts[i] = fmt.Sprintf("%s %d", k, counts[k])
+	}
+	if len(parts) == 0 {
+		return "none"
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,109 @@
+package golinkfinder
+
+import (
+	"bufio"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"os"
+	"strings"
+)
+
+var (
+	acceptLanguages = []string{
+		"en-US,en;q=0.9",
+		"en-GB,en;q=0.9",
+		"en-US,en;q=0.8,de;q=0.6",
+		"fr-FR,fr;q=0.9,en-US;q=0.8,en;q=0.7",
+		"de-DE,de;q=0.9,en;q=0.8",
+		"es-ES,es;q=0.9,en;q=0.8",
+		"pt-BR,pt;q=0.9,en-US;q=0.8,en;q=0.7",
+		"ja-JP,ja;q=0.9,en;q=0.8",
+		"nl-NL,nl;q=0.9,en;q=0.8",
+		"it-IT,it;q=0.9,en;q=0.8",
+	}
+	searchReferers = []string{
+		"https://www.google.com/",
+		"https://www.bing.com/",
+		"https://duckduckgo.com/",
+	}
+)
+
+// identity decides the User-Agent, and with -random-headers the
+// Accept-Language and Referer, of each request.
+type identity struct {
+	agents        []string
+	randomHeaders bool
+}
+
+func newIdentity(o *scanOptions) (*identity, error) {
+	id := &identity{agents: []string{userAgent}, randomHeaders: o.randomHeaders}
+	if o.ua != "" {
+		id.agents = []string{o.ua}
+	}
+	if o.uaRotate != "" {
+		agents, err := readUserAgents(o.uaRotate)
+		if err != nil {
+			return nil, err
+		}
+		id.agents = agents
+	}
+	return id, nil
+}
+
+// readUserAgents reads one agent per line, skipping blanks and # comments.
+func readUserAgents(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read user agents: %v", err)
+	}
+	defer file.Close()
+	var agents []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line != "" && !strings.HasPrefix(line, "#") {
+			agents = append(agents, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("could not read user agents: %v", err)
+	}
+	if len(agents) == 0 {
+		return nil, fmt.Errorf("no user agents in %s", path)
+	}
+	return agents, nil
+}
+
+func (id *identity) agent() string {
+	return id.agents[rand.Intn(len(id.agents))]
+}
+
+// identityTransport fills in the headers a request doesn't set itself, so
+// per-target headers always win.
+type identityTransport struct {
+	next http.RoundTripper
+	id   *identity
+}
+
+func (t *identityTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	if req.Header.Get("User-Agent") == "" {
+		req.Header.Set("User-Agent", t.id.agent())
+	}
+	if t.id.randomHeaders {
+		if req.Header.Get("Accept-Language") == "" {
+			req.Header.Set("Accept-Language", acceptLanguages[rand.Intn(len(acceptLanguages))])
+		}
+		if req.Header.Get("Referer") == "" {
+			// Half the requests look like they came from the site itself,
+			// the rest from a search engine.
+			if rand.Intn(2) == 0 {
+				req.Header.Set("Referer", req.URL.Scheme+"://"+req.URL.Host+"/")
+			} else {
+				req.Header.Set("Referer", searchReferers[rand.Intn(len(searchReferers))])
+			}
+		}
+	}
+	return t.next.RoundTrip(req)
+}
@@ -0,0 +1,149 @@
+package golinkfinder
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"regexp"
+	"strings"
+)
+
+const defaultIgnoreFile = ".golinkfinderignore"
+
+// ignoreList is a suppression file of findings already triaged, kept next
+// to the scans (.golinkfinderignore in the working directory by default)
+// so every run and diff leaves them out. One pattern per line:
+//
+//	/api/health                      exact value
+//	https://cdn.example.com/*        * matches any run of characters
+//	re:^/static/v\d+/                regular expression
+//	[secret] AIzaSyD* @accepted      only findings of that category
+//
+// Words starting with @ tag an entry (false-positive, accepted...) and the
+// summary counts suppressions per tag; "#" starts a comment.
+type ignoreList struct {
+	entries []ignoreEntry
+}
+
+type ignoreEntry struct {
+	category string
+	re       *regexp.Regexp
+	tag      string
+}
+
+// loadIgnoreList reads path; a missing file is only an error when it was
+// asked for explicitly.
+func loadIgnoreList(path string, explicit bool) (*ignoreList, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		if !explicit && errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("could not open ignore file: %v", err)
+	}
+	defer file.Close()
+	return parseIgnoreList(path, file)
+}
+
+// parseIgnoreList reads ignore-file lines from r; name is used in errors.
+func parseIgnoreList(name string, r io.Reader) (*ignoreList, error) {
+	list := &ignoreList{}
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if i := strings.Index(line, " #"); i >= 0 {
+			line = strings.TrimSpace(line[:i])
+		}
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		var entry ignoreEntry
+		if strings.HasPrefix(line, "[") {
+			end := strings.Index(line, "]")
+			if end < 0 {
+				return nil, fmt.Errorf("%s:%d: unclosed category", name, n)
+			}
+			entry.category, line = strings.TrimSpace(line[1:end]), strings.TrimSpace(line[end+1:])
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			return nil, fmt.Errorf("%s:%d: no pattern", name, n)
+		}
+		var tags []string
+		for _, field := range fields[1:] {
+			if !strings.HasPrefix(field, "@") {
+				return nil, fmt.Errorf("%s:%d: unexpected '%s' (tags start with @)", name, n, field)
+			}
+			tags = append(tags, field[1:])
+		}
+		entry.tag = strings.Join(tags, ",")
+		var err error
+		if entry.re, err = ignorePattern(fields[0]); err != nil {
+			return nil, fmt.Errorf("%s:%d: %v", name, n, err)
+		}
+		list.entries = append(list.entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("could not read ignore file: %v", err)
+	}
+	return list, nil
+}
+
+// openIgnoreList loads the -ignore-file of a command, or ./.golinkfinderignore
+// when there is one, unless -no-ignore.
+func openIgnoreList(path string, disabled bool) (*ignoreList, error) {
+	if disabled {
+		return nil, nil
+	}
+	if path == "" {
+		return loadIgnoreList(defaultIgnoreFile, false)
+	}
+	return loadIgnoreList(path, true)
+}
+
+func ignorePattern(pattern string) (*regexp.Regexp, error) {
+	if strings.HasPrefix(pattern, "re:") {
+		re, err := regexp.Compile(pattern[3:])
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern: %v", err)
+		}
+		return re, nil
+	}
+	parts := strings.Split(pattern, "*")
+	for i := range parts {
+		parts[i] = regexp.QuoteMeta(parts[i])
+	}
+	return regexp.MustCompile("^" + strings.Join(parts, ".*") + "$"), nil
+}
+
+// match returns the entry suppressing value, or nil. An empty category
+// matches entries of every category.
+func (l *ignoreList) match(category string, values ...string) *ignoreEntry {
+	if l == nil {
+		return nil
+	}
+	for i := range l.entries {
+		e := &l.entries[i]
+		if e.category != "" && category != "" && e.category != category {
+			continue
+		}
+		for _, value := range values {
+			if e.re.MatchString(value) {
+				return e
+			}
+		}
+	}
+	return nil
+}
+
+// drop removes the suppressed values from a set read by diff.
+func (l *ignoreList) drop(set map[string]struct{}) {
+	for value := range set {
+		if l.match("", value) != nil {
+			delete(set, value)
+		}
+	}
+}
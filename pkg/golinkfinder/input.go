@@ -0,0 +1,110 @@
+package golinkfinder
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// jsonTarget is one line of JSONL input:
+// {"url": "...", "method": "GET", "host": "vhost", "headers": {"Authorization": "..."}, "cookies": "a=b; c=d"}
+type jsonTarget struct {
+	URL     string            `json:"url"`
+	Method  string            `json:"method"`
+	Host    string            `json:"host"`
+	Headers map[string]string `json:"headers"`
+	Cookies string            `json:"cookies"`
+}
+
+func (t jsonTarget) job() scanJob {
+	job := scanJob{url: t.URL, method: strings.ToUpper(t.Method), host: t.Host}
+	if len(t.Headers) > 0 || t.Cookies != "" {
+		job.headers = make(http.Header)
+		for k, v := range t.Headers {
+			// net/http ignores a Host entry in the header map.
+			if strings.EqualFold(k, "Host") {
+				job.host = v
+				continue
+			}
+			job.headers.Set(k, v)
+		}
+		if t.Cookies != "" {
+			job.headers.Set("Cookie", t.Cookies)
+		}
+	}
+	return job
+}
+
+// parseTargets turns input lines into jobs. Besides plain URLs it accepts
+// JSONL lines (starting with '{') and CSV with a header row starting with
+// "url", whose other columns may be method, host, cookies or header:<Name>.
+func parseTargets(lines []string) ([]scanJob, error) {
+	if len(lines) > 0 && strings.HasPrefix(strings.ToLower(lines[0]), "url,") {
+		return parseCSVTargets(lines)
+	}
+	jobs := make([]scanJob, 0, len(lines))
+	for i, line := range lines {
+		job, err := parseTarget(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", i+1, err)
+		}
+		jobs = append(jobs, job)
+	}
+	return jobs, nil
+}
+
+// parseTarget reads one line of a target list: a URL or a JSON target.
+func parseTarget(line string) (scanJob, error) {
+	if !strings.HasPrefix(line, "{") {
+		return scanJob{url: line}, nil
+	}
+	var t jsonTarget
+	if err := json.Unmarshal([]byte(line), &t); err != nil {
+		return scanJob{}, fmt.Errorf("invalid JSON target: %v", err)
+	}
+	if t.URL == "" {
+		return scanJob{}, fmt.Errorf("JSON target has no url")
+	}
+	return t.job(), nil
+}
+
+func parseCSVTargets(lines []string) ([]scanJob, error) {
+	r := csv.NewReader(strings.NewReader(strings.Join(lines, "\n")))
+	r.FieldsPerRecord = -1
+	records, err := r.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("invalid CSV targets: %v", err)
+	}
+
+	columns := records[0]
+	jobs := make([]scanJob, 0, len(records)-1)
+	for _, record := range records[1:] {
+		t := jsonTarget{Headers: make(map[string]string)}
+		for i, value := range record {
+			if i >= len(columns) || value == "" {
+				continue
+			}
+			column := strings.TrimSpace(columns[i])
+			switch lower := strings.ToLower(column); {
+			case lower == "url":
+				t.URL = value
+			case lower == "method":
+				t.Method = value
+			case lower == "host":
+				t.Host = value
+			case lower == "cookies" || lower == "cookie":
+				t.Cookies = value
+			case strings.HasPrefix(lower, "header:"):
+				t.Headers[strings.TrimSpace(column[len("header:"):])] = value
+			default:
+				return nil, fmt.Errorf("unknown CSV column '%s'", column)
+			}
+		}
+		if t.URL != "" {
+			jobs = append(jobs, t.job())
+		}
+	}
+	return jobs, nil
+}
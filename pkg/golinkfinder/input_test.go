@@ -0,0 +1,50 @@
+package golinkfinder
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestParseTargetsCSV(t *testing.T) {
+	lines := []string{
+		"url,method,host,cookies,header:Authorization,header:X-Api-Key",
+		"https://example.com/a.js,post,vhost.internal,a=b; c=d,Bearer t0ken,",
+		`"https://example.com/b.js?x=1,2",,,,,k3y`,
+		"https://example.com/c.js",
+		",GET,,,,",
+	}
+	jobs, err := parseTargets(lines)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []scanJob{
+		{url: "https://example.com/a.js", method: "POST", host: "vhost.internal", headers: http.Header{"Cookie": {"a=b; c=d"}, "Authorization": {"Bearer t0ken"}}},
+		{url: "https://example.com/b.js?x=1,2", headers: http.Header{"X-Api-Key": {"k3y"}}},
+		{url: "https://example.com/c.js"},
+	}
+	if !reflect.DeepEqual(jobs, want) {
+		t.Errorf("parseTargets:\n got %+v\nwant %+v", jobs, want)
+	}
+}
+
+func TestParseTargetsCSVErrors(t *testing.T) {
+	for _, lines := range [][]string{
+		{"url,referer", "https://example.com/a.js,https://example.com/"},
+		{"url,method", `"https://example.com/a.js,GET`},
+	} {
+		if _, err := parseTargets(lines); err == nil {
+			t.Errorf("parseTargets(%q): want an error", lines)
+		}
+	}
+}
+
+func TestParseTargetsCSVHostHeader(t *testing.T) {
+	jobs, err := parseTargets([]string{"URL,Header:Host", "https://10.0.0.1/app.js,app.example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(jobs) != 1 || jobs[0].host != "app.example.com" || jobs[0].headers.Get("Host") != "" {
+		t.Errorf("a Host header column should set the job's host: got %+v", jobs)
+	}
+}
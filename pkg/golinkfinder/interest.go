@@ -0,0 +1,101 @@
+package golinkfinder
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// interestThreshold is the score from which an endpoint counts as high-interest.
+const interestThreshold = 3
+
+type interestKeyword struct {
+	keyword string
+	score   int
+}
+
+// interestKeywords are matched case-insensitively anywhere in a value. The
+// scores are rough: anything that can leak data or grant access outranks
+// things that merely widen the attack surface.
+var interestKeywords = []interestKeyword{
+	{".env", 5},
+	{"actuator", 5},
+	{"backup", 5},
+	{".git", 5},
+	{"phpinfo", 5},
+	{"debug", 4},
+	{"admin", 4},
+	{"secret", 4},
+	{"token", 4},
+	{"password", 4},
+	{"credential", 4},
+	{".sql", 4},
+	{"dump", 3},
+	{"internal", 3},
+	{"swagger", 3},
+	{"api-docs", 3},
+	{"openapi", 3},
+	{"graphql", 3},
+	{"upload", 3},
+	{"config", 3},
+	{"private", 3},
+	{"console", 3},
+	{"metrics", 2},
+	{"health", 1},
+	{"test", 1},
+	{"staging", 2},
+	{"export", 2},
+	{"import", 1},
+	{"auth", 2},
+	{"login", 1},
+	{"oauth", 2},
+	{"users", 1},
+	{"account", 1},
+	{"key", 2},
+}
+
+// interestScore sums the scores of every keyword found in value and returns
+// them alongside the matching keywords.
+func interestScore(value string) (int, []string) {
+	lower := strings.ToLower(value)
+	score := 0
+	matched := make([]string, 0)
+	for _, k := range interestKeywords {
+		if strings.Contains(lower, k.keyword) {
+			score += k.score
+			matched = append(matched, k.keyword)
+		}
+	}
+	return score, matched
+}
+
+func isInteresting(value string) bool {
+	score, _ := interestScore(value)
+	return score >= interestThreshold
+}
+
+func printInterestingSection(endpoints resultSet) {
+	type scored struct {
+		value    string
+		score    int
+		keywords []string
+	}
+	ranked := make([]scored, 0)
+	endpoints.Each(func(endpoint string) error {
+		if score, keywords := interestScore(endpoint); score >= interestThreshold {
+			ranked = append(ranked, scored{endpoint, score, keywords})
+		}
+		return nil
+	})
+	if len(ranked) == 0 {
+		return
+	}
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return ranked[i].score > ranked[j].score
+	})
+
+	fmt.Fprintf(statusOut, "\n%s%s[!] High-interest endpoints (%d):%s%s\n", c.Bold, c.Red, len(ranked), c.End, c.End)
+	for _, r := range ranked {
+		fmt.Fprintf(statusOut, "  %s[%2d]%s %s  %s(%s)%s\n", c.Red, r.score, c.End, r.value, c.Yellow, strings.Join(r.keywords, ", "), c.End)
+	}
+}
@@ -0,0 +1,211 @@
+package golinkfinder
+
+import (
+	"sort"
+	"strings"
+)
+
+// regexKeywords are the keywords after which a '/' starts a regex literal
+// rather than a division.
+var regexKeywords = map[string]bool{
+	"return": true, "typeof": true, "case": true, "do": true, "else": true, "in": true, "instanceof": true,
+	"new": true, "delete": true, "void": true, "throw": true, "yield": true, "await": true,
+}
+
+// textSpan is a [start, end) byte range.
+type textSpan struct{ start, end int }
+
+// Kinds of open '{' for jsStringSpans.
+const (
+	braceCode = iota
+	braceTemplate
+	braceJSX
+)
+
+// jsStringSpans lexes JavaScript just enough to return the contents of its
+// string and template literals, skipping comments and regex literals. The
+// ${...} expressions of template literals are lexed as code. With jsx, JSX
+// elements are lexed too: their attribute strings and text are literals,
+// their {...} expressions code.
+func jsStringSpans(src string, jsx bool) []textSpan {
+	var spans []textSpan
+	// braces holds the kind of every open '{'.
+	var braces []int
+	var code func(i int) int
+
+	// template scans template text from i up to the closing backtick or an
+	// opening ${, returning the position after it.
+	template := func(i int) int {
+		start := i
+		for i < len(src) {
+			switch {
+			case src[i] == '\\':
+				i += 2
+				continue
+			case src[i] == '`':
+				spans = append(spans, textSpan{start, i})
+				return i + 1
+			case src[i] == '$' && i+1 < len(src) && src[i+1] == '{':
+				spans = append(spans, textSpan{start, i})
+				braces = append(braces, braceTemplate)
+				return i + 2
+			}
+			i++
+		}
+		spans = append(spans, textSpan{start, len(src)})
+		return len(src)
+	}
+
+	// element scans the JSX element whose '<' is at i, nested elements
+	// included, returning the position after it.
+	element := func(i int) int {
+		depth := 0
+		for i < len(src) {
+			if src[i] != '<' {
+				start := i
+				for i < len(src) && src[i] != '<' && src[i] != '{' {
+					i++
+				}
+				spans = append(spans, textSpan{start, i})
+				if i < len(src) && src[i] == '{' {
+					braces = append(braces, braceJSX)
+					i = code(i + 1)
+				}
+				continue
+			}
+			closing := i+1 < len(src) && src[i+1] == '/'
+			for i++; i < len(src) && src[i] != '>'; {
+				switch ch := src[i]; {
+				case ch == '"' || ch == '\'':
+					end := strings.IndexByte(src[i+1:], ch)
+					if end < 0 {
+						return len(src)
+					}
+					spans = append(spans, textSpan{i + 1, i + 1 + end})
+					i += end + 2
+				case ch == '{':
+					braces = append(braces, braceJSX)
+					i = code(i + 1)
+				default:
+					i++
+				}
+			}
+			if i >= len(src) {
+				return len(src)
+			}
+			switch {
+			case closing:
+				depth--
+			case src[i-1] != '/':
+				depth++
+			}
+			i++
+			if depth <= 0 {
+				return i
+			}
+		}
+		return i
+	}
+
+	// code lexes from i up to the end of src or the '}' closing a JSX
+	// expression, returning the position after it.
+	code = func(i int) int {
+		regexAllowed := true
+		for i < len(src) {
+			ch := src[i]
+			switch {
+			case ch == '/' && i+1 < len(src) && src[i+1] == '/':
+				for i < len(src) && src[i] != '\n' {
+					i++
+				}
+			case ch == '/' && i+1 < len(src) && src[i+1] == '*':
+				end := strings.Index(src[i+2:], "*/")
+				if end < 0 {
+					return len(src)
+				}
+				i += end + 4
+			case ch == '/' && regexAllowed:
+				inClass := false
+				for i++; i < len(src) && src[i] != '\n'; i++ {
+					if src[i] == '\\' {
+						i++
+					} else if src[i] == '[' {
+						inClass = true
+					} else if src[i] == ']' {
+						inClass = false
+					} else if src[i] == '/' && !inClass {
+						break
+					}
+				}
+				for i++; i < len(src) && isIdentChar(src[i]); i++ {
+				}
+				regexAllowed = false
+			case jsx && ch == '<' && regexAllowed && i+1 < len(src) && (src[i+1] == '>' || isLetter(src[i+1])):
+				i = element(i)
+				regexAllowed = false
+			case ch == '"' || ch == '\'':
+				start := i + 1
+				for i++; i < len(src) && src[i] != ch && src[i] != '\n'; i++ {
+					if src[i] == '\\' {
+						i++
+					}
+				}
+				if i > len(src) {
+					i = len(src)
+				}
+				spans = append(spans, textSpan{start, i})
+				i++
+				regexAllowed = false
+			case ch == '`':
+				i = template(i + 1)
+				regexAllowed = false
+			case ch == '{':
+				braces = append(braces, braceCode)
+				i++
+				regexAllowed = true
+			case ch == '}':
+				i++
+				if n := len(braces); n > 0 {
+					kind := braces[n-1]
+					braces = braces[:n-1]
+					switch kind {
+					case braceTemplate:
+						i = template(i)
+					case braceJSX:
+						return i
+					}
+				}
+				regexAllowed = false
+			case isIdentChar(ch):
+				start := i
+				for i < len(src) && isIdentChar(src[i]) {
+					i++
+				}
+				regexAllowed = regexKeywords[src[start:i]]
+			case ch == ')' || ch == ']':
+				i++
+				regexAllowed = false
+			case ch == ' ' || ch == '\t' || ch == '\n' || ch == '\r':
+				i++
+			default:
+				i++
+				regexAllowed = true
+			}
+		}
+		return len(src)
+	}
+
+	code(0)
+	return spans
+}
+
+func isLetter(ch byte) bool {
+	return ch >= 'a' && ch <= 'z' || ch >= 'A' && ch <= 'Z'
+}
+
+// within reports whether [start, end) lies inside one of spans, which are
+// sorted and disjoint.
+func within(spans []textSpan, start, end int) bool {
+	i := sort.Search(len(spans), func(i int) bool { return spans[i].end >= end })
+	return i < len(spans) && spans[i].start <= start
+}
@@ -0,0 +1,145 @@
+package golinkfinder
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+const categoryJWT = "jwt"
+
+// jwtRegex matches header.payload.signature where both JSON parts start
+// with '{"' (base64 "eyJ"); unsigned tokens have an empty signature.
+var jwtRegex = regexp.MustCompile(`\beyJ[A-Za-z0-9_-]{8,}\.eyJ[A-Za-z0-9_-]{8,}\.[A-Za-z0-9_-]*`)
+
+// jwtScopeClaims hold the permissions a token grants.
+var jwtScopeClaims = []string{"scope", "scp", "roles", "role", "groups", "permissions", "cognito:groups"}
+
+func decodeJWTPart(part string, v interface{}) error {
+	raw, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(part, "="))
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(raw, v)
+}
+
+// claimStrings flattens a claim that is a string, a space-separated scope
+// list or an array of strings.
+func claimStrings(v interface{}) []string {
+	switch v := v.(type) {
+	case string:
+		return strings.Fields(v)
+	case []interface{}:
+		var out []string
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				out = append(out, s)
+			}
+		}
+		return out
+	}
+	return nil
+}
+
+// claimURLs collects every http(s) URL among the claims' string values,
+// nested objects included, e.g. iss, aud or a custom api_url claim.
+func claimURLs(claims map[string]interface{}) map[string]string {
+	urls := make(map[string]string)
+	var walk func(name string, v interface{})
+	walk = func(name string, v interface{}) {
+		switch v := v.(type) {
+		case string:
+			if strings.HasPrefix(v, "https://") || strings.HasPrefix(v, "http://") {
+				if _, ok := urls[v]; !ok {
+					urls[v] = name
+				}
+			}
+		case []interface{}:
+			for _, item := range v {
+				walk(name, item)
+			}
+		case map[string]interface{}:
+			for key, item := range v {
+				walk(key, item)
+			}
+		}
+	}
+	walk("", claims)
+	return urls
+}
+
+// describeJWT summarizes a token's header and claims for its note:
+// "alg HS256, iss https://auth.example.com, aud api, scope read write,
+// expired 2021-01-01". It returns false for strings that don't decode.
+func describeJWT(token string) (string, map[string]interface{}, bool) {
+	parts := strings.Split(token, ".")
+	var header, claims map[string]interface{}
+	if len(parts) != 3 || decodeJWTPart(parts[0], &header) != nil || decodeJWTPart(parts[1], &claims) != nil {
+		return "", nil, false
+	}
+	var note []string
+	if alg, ok := header["alg"].(string); ok {
+		note = append(note, "alg "+alg)
+	}
+	for _, name := range []string{"iss", "aud", "sub", "azp", "client_id"} {
+		if values := claimStrings(claims[name]); len(values) > 0 {
+			note = append(note, name+" "+strings.Join(values, " "))
+		}
+	}
+	for _, name := range jwtScopeClaims {
+		if values := claimStrings(claims[name]); len(values) > 0 {
+			note = append(note, name+" "+strings.Join(values, " "))
+		}
+	}
+	if exp, ok := claims["exp"].(float64); ok {
+		at := time.Unix(int64(exp), 0).UTC()
+		if at.Before(time.Now()) {
+			note = append(note, "expired "+at.Format("2006-01-02"))
+		} else {
+			note = append(note, "expires "+at.Format("2006-01-02"))
+		}
+	}
+	return strings.Join(note, ", "), claims, true
+}
+
+// jwtExtractor reports hardcoded JWTs with their decoded claims (the
+// signature isn't checked) and the URLs the claims point at, which often
+// name internal API hosts and identity providers.
+type jwtExtractor struct{}
+
+func (jwtExtractor) Extract(source, contentType string, body []byte) []Finding {
+	content := string(body)
+	if !strings.Contains(content, "eyJ") {
+		return nil
+	}
+	lines := newLineIndex(content)
+	seen := make(map[string]struct{})
+	findings := make([]Finding, 0)
+	for _, loc := range findAllSubmatchIndex(jwtRegex, content) {
+		token := content[loc[0]:loc[1]]
+		if _, ok := seen[token]; ok {
+			continue
+		}
+		seen[token] = struct{}{}
+		note, claims, ok := describeJWT(token)
+		if !ok {
+			continue
+		}
+		line := lines.line(loc[0])
+		findings = append(findings, Finding{Source: source, Value: token, Category: categoryJWT, Line: line, Offset: loc[0], Note: note})
+		urls := claimURLs(claims)
+		sorted := make([]string, 0, len(urls))
+		for u := range urls {
+			sorted = append(sorted, u)
+		}
+		sort.Strings(sorted)
+		for _, u := range sorted {
+			findings = append(findings, Finding{Source: source, Value: u, Category: categoryEndpoint, Line: line, Offset: loc[0], Note: fmt.Sprintf("from JWT claim %s", urls[u])})
+		}
+	}
+	return findings
+}
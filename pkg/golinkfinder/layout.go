@@ -0,0 +1,250 @@
+package golinkfinder
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// listLayout is how the final endpoint list is ordered (-sort), split into
+// sections (-group-by) and filtered by how many sources reference each value
+// (-min-count, shown with -counts). The zero value is the plain
+// alphabetical list.
+type listLayout struct {
+	sortBy   string
+	groupBy  string
+	minCount int
+	counts   bool
+	// categories maps each value to the category it was first found as,
+	// for -group-by category.
+	categories map[string]string
+}
+
+var (
+	sortModes    = []string{"alpha", "by-host", "by-count"}
+	groupByModes = []string{"host", "source", "category"}
+)
+
+func newListLayout(sortBy, groupBy string, minCount int, counts bool) (*listLayout, error) {
+	if sortBy != "" && !containsString(sortModes, sortBy) {
+		return nil, fmt.Errorf("unknown -sort %q (want %s)", sortBy, strings.Join(sortModes, ", "))
+	}
+	if groupBy != "" && !containsString(groupByModes, groupBy) {
+		return nil, fmt.Errorf("unknown -group-by %q (want %s)", groupBy, strings.Join(groupByModes, ", "))
+	}
+	l := &listLayout{sortBy: sortBy, groupBy: groupBy, minCount: minCount, counts: counts}
+	if groupBy == "category" {
+		l.categories = make(map[string]string)
+	}
+	return l, nil
+}
+
+// plain reports whether the list is the set's own alphabetical order, which
+// can be streamed without loading every value.
+func (l *listLayout) plain() bool {
+	return l == nil || ((l.sortBy == "" || l.sortBy == "alpha") && l.groupBy == "" && l.minCount <= 1 && !l.counts)
+}
+
+// needsRefs reports whether the layout needs the sources of every value.
+func (l *listLayout) needsRefs() bool {
+	return l != nil && (l.sortBy == "by-count" || l.groupBy == "source" || l.minCount > 1 || l.counts)
+}
+
+func (l *listLayout) record(value, category string) {
+	if l == nil || l.categories == nil {
+		return
+	}
+	if _, ok := l.categories[value]; !ok {
+		l.categories[value] = category
+	}
+}
+
+// templated re-keys the categories by template for -template lists.
+func (l *listLayout) templated() *listLayout {
+	if l == nil || l.categories == nil {
+		return l
+	}
+	t := *l
+	t.categories = make(map[string]string)
+	for value, category := range l.categories {
+		t.record(templatePath(value), category)
+	}
+	return &t
+}
+
+type listEntry struct {
+	Value    string      `json:"value"`
+	Category string      `json:"category,omitempty"`
+	Host     string      `json:"host,omitempty"`
+	Sources  int         `json:"sources,omitempty"`
+	Refs     []sourceRef `json:"refs,omitempty"`
+}
+
+type listGroup struct {
+	Name    string      `json:"group"`
+	Entries []listEntry `json:"endpoints"`
+}
+
+// entryHost is the host of an absolute value, or of the first source that
+// referenced a relative one.
+func entryHost(value string, refs []sourceRef) string {
+	if u, err := url.Parse(value); err == nil && u.Host != "" {
+		return u.Host
+	}
+	if len(refs) > 0 {
+		if u, err := url.Parse(refs[0].Source); err == nil {
+			return u.Host
+		}
+	}
+	return ""
+}
+
+func (l *listLayout) entries(set resultSet) ([]listEntry, error) {
+	var entries []listEntry
+	err := set.EachWithRefs(func(value string, refs []sourceRef) error {
+		if len(refs) < l.minCount {
+			return nil
+		}
+		e := listEntry{Value: value, Host: entryHost(value, refs), Sources: len(refs), Refs: refs}
+		if l.categories != nil {
+			e.Category = l.categories[value]
+			if e.Category == "" {
+				e.Category = categoryEndpoint
+			}
+		}
+		entries = append(entries, e)
+		return nil
+	})
+	return entries, err
+}
+
+func (l *listLayout) sortEntries(entries []listEntry) {
+	switch l.sortBy {
+	case "by-host":
+		sort.SliceStable(entries, func(i, j int) bool { return entries[i].Host < entries[j].Host })
+	case "by-count":
+		sort.SliceStable(entries, func(i, j int) bool { return entries[i].Sources > entries[j].Sources })
+	}
+}
+
+// groups splits entries into sections; a value found in several sources is
+// listed under each with -group-by source. Without -group-by everything is
+// one unnamed group.
+func (l *listLayout) groups(entries []listEntry) []listGroup {
+	if l.groupBy == "" {
+		l.sortEntries(entries)
+		return []listGroup{{Entries: entries}}
+	}
+	index := make(map[string]int)
+	var groups []listGroup
+	add := func(name string, e listEntry) {
+		if name == "" {
+			name = "(none)"
+		}
+		i, ok := index[name]
+		if !ok {
+			i = len(groups)
+			index[name] = i
+			groups = append(groups, listGroup{Name: name})
+		}
+		groups[i].Entries = append(groups[i].Entries, e)
+	}
+	for _, e := range entries {
+		switch l.groupBy {
+		case "host":
+			add(e.Host, e)
+		case "category":
+			add(e.Category, e)
+		case "source":
+			if len(e.Refs) == 0 {
+				add("", e)
+			}
+			for _, ref := range e.Refs {
+				in := e
+				in.Refs = []sourceRef{ref}
+				add(ref.Source, in)
+			}
+		}
+	}
+	for _, g := range groups {
+		l.sortEntries(g.Entries)
+	}
+	// Largest sections first with by-count, otherwise by name.
+	sort.SliceStable(groups, func(i, j int) bool {
+		if l.sortBy == "by-count" && len(groups[i].Entries) != len(groups[j].Entries) {
+			return len(groups[i].Entries) > len(groups[j].Entries)
+		}
+		return groups[i].Name < groups[j].Name
+	})
+	return groups
+}
+
+// print writes the list as text: a "# name (count)" header and a blank line
+// around each section, values (or provenance rows) inside.
+func (l *listLayout) print(w io.Writer, set resultSet, provenance bool) error {
+	entries, err := l.entries(set)
+	if err != nil {
+		return err
+	}
+	for i, g := range l.groups(entries) {
+		if l.groupBy != "" {
+			if i > 0 {
+				fmt.Fprintln(w)
+			}
+			if _, err := fmt.Fprintf(w, "# %s (%d)\n", g.Name, len(g.Entries)); err != nil {
+				return err
+			}
+		}
+		for _, e := range g.Entries {
+			// -counts puts the number of sources first, "12<TAB>/api/users".
+			prefix := ""
+			if l.counts {
+				prefix = fmt.Sprintf("%d\t", e.Sources)
+			}
+			if !provenance {
+				if _, err := fmt.Fprintf(w, "%s%s\n", prefix, e.Value); err != nil {
+					return err
+				}
+				continue
+			}
+			for _, ref := range e.Refs {
+				if _, err := fmt.Fprintf(w, "%s%s\t%s\t%d\t%d\n", prefix, e.Value, ref.Source, ref.Line, ref.Offset); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// writeJSON writes the list as a JSON array of entries, or of groups with
+// -group-by. Source references are only included with provenance.
+func (l *listLayout) writeJSON(w io.Writer, set resultSet, provenance bool) error {
+	if l == nil {
+		l = &listLayout{}
+	}
+	entries, err := l.entries(set)
+	if err != nil {
+		return err
+	}
+	if entries == nil {
+		entries = []listEntry{}
+	}
+	groups := l.groups(entries)
+	if !provenance {
+		for _, g := range groups {
+			for i := range g.Entries {
+				g.Entries[i].Refs = nil
+			}
+		}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if l.groupBy == "" {
+		return enc.Encode(groups[0].Entries)
+	}
+	return enc.Encode(groups)
+}
@@ -0,0 +1,174 @@
+package golinkfinder
+
+import (
+	"bytes"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// knownLibrary fingerprints a third-party library by its banner comment or
+// a marker only its code contains, and lists the paths it requests
+// internally, which are noise in every application bundling it. files are
+// the prefixes of the file names it is served under.
+type knownLibrary struct {
+	name   string
+	banner *regexp.Regexp
+	paths  []string
+	files  []string
+}
+
+var knownLibraries = []knownLibrary{
+	{"jquery", regexp.MustCompile(`/\*!?\s*jQuery (?:JavaScript Library )?v\d`), nil, []string{"jquery"}},
+	{"jquery-ui", regexp.MustCompile(`/\*!?\s*jQuery UI - v\d`), nil, []string{"jquery-ui", "jquery.ui"}},
+	{"react", regexp.MustCompile(`@license React\b|react(?:-dom)?\.production\.min\.js`), []string{"/docs/error-decoder.html"}, []string{"react"}},
+	{"vue", regexp.MustCompile(`/\*!?\s*Vue\.js v\d`), nil, []string{"vue"}},
+	{"angular", regexp.MustCompile(`@license Angular v\d|@license AngularJS v\d`), []string{"/errors", "/guide/"}, []string{"angular"}},
+	{"lodash", regexp.MustCompile(`@license\s+Lodash|lodash\.com/license`), nil, []string{"lodash"}},
+	{"moment", regexp.MustCompile(`//!\s*moment\.js`), []string{"/guides/"}, []string{"moment"}},
+	{"bootstrap", regexp.MustCompile(`/\*!?\s*Bootstrap v\d`), nil, []string{"bootstrap"}},
+	{"core-js", regexp.MustCompile(`core-js/blob/v\d|Denis Pushkarev`), nil, []string{"core-js", "shim"}},
+	{"polyfill", regexp.MustCompile(`Polyfill service v\d|polyfill\.io`), nil, []string{"polyfill"}},
+	{"google-analytics", regexp.MustCompile(`google-analytics\.com/(?:analytics|ga)\.js|GoogleAnalyticsObject`), []string{"/collect", "/g/collect", "/r/collect", "/j/collect", "/analytics.js", "/debug/collect"}, []string{"analytics.js", "ga.js"}},
+	{"google-tag-manager", regexp.MustCompile(`googletagmanager\.com/gtm\.js|gtm\.start`), []string{"/gtm.js", "/gtag/js", "/gtag/destination", "/pagead/", "/ccm/collect"}, []string{"gtm.js", "gtag"}},
+	{"segment", regexp.MustCompile(`cdn\.segment\.com|@segment/analytics-next`), []string{"/v1/t", "/v1/p", "/v1/i", "/v1/b", "/v1/batch", "/v1/projects"}, []string{"analytics"}},
+	{"mixpanel", regexp.MustCompile(`mixpanel-js|MIXPANEL_LIB_URL`), []string{"/track/", "/engage/", "/groups/", "/decide/", "/record/"}, []string{"mixpanel"}},
+	{"sentry", regexp.MustCompile(`@sentry/|sentry-cdn\.com`), []string{"/envelope/", "/store/", "/api/embed/error-page/"}, []string{"sentry"}},
+	{"hotjar", regexp.MustCompile(`static\.hotjar\.com|hjSiteSettings`), []string{"/api/v2/client/"}, []string{"hotjar"}},
+	{"facebook-pixel", regexp.MustCompile(`connect\.facebook\.net/[^/]+/fbevents\.js|fbq\.callMethod`), []string{"/tr/", "/signals/config/"}, []string{"fbevents"}},
+}
+
+// libraryCDNs serve nothing but third-party libraries; every finding from
+// a file there is vendor noise.
+var libraryCDNs = map[string]bool{
+	"code.jquery.com": true, "cdnjs.cloudflare.com": true, "cdn.jsdelivr.net": true, "unpkg.com": true,
+	"ajax.googleapis.com": true, "www.google-analytics.com": true, "www.googletagmanager.com": true,
+	"connect.facebook.net": true, "static.hotjar.com": true, "browser.sentry-cdn.com": true,
+	"js.sentry-cdn.com": true, "polyfill.io": true, "cdn.polyfill.io": true, "cdn.segment.com": true,
+}
+
+// bannerWindow bounds the leading comment searched for a banner.
+const bannerWindow = 512
+
+// leadingComment returns the comment a file starts with, where libraries
+// put their banner.
+func leadingComment(body []byte) []byte {
+	head := bytes.TrimLeft(body, " \t\r\n\ufeff")
+	if len(head) > bannerWindow {
+		head = head[:bannerWindow]
+	}
+	switch {
+	case bytes.HasPrefix(head, []byte("/*")):
+		if end := bytes.Index(head, []byte("*/")); end >= 0 {
+			return head[:end+2]
+		}
+		return head
+	case bytes.HasPrefix(head, []byte("//")):
+		if end := bytes.IndexByte(head, '\n'); end >= 0 {
+			return head[:end]
+		}
+		return head
+	}
+	return nil
+}
+
+// suppressibleCategory limits suppression to path-like findings, so a
+// secret pasted next to a vendored library is still reported.
+func suppressibleCategory(category string) bool {
+	return category == categoryEndpoint || category == categoryRealtime || category == categoryChunk || category == categoryModule
+}
+
+// libraryFilter drops the findings of known libraries for -ignore-libs and
+// counts what it dropped per library.
+type libraryFilter struct {
+	mu         sync.Mutex
+	suppressed map[string]int
+}
+
+func newLibraryFilter() *libraryFilter {
+	return &libraryFilter{suppressed: make(map[string]int)}
+}
+
+// filter removes from findings everything a standalone library file
+// reports, and the known internal paths of every library found bundled in
+// body. A file is a standalone library when it is served from a library
+// CDN, or named after a library and starting with its banner: a banner
+// alone also starts the app bundles that concatenate the library first.
+func (lf *libraryFilter) filter(source string, body []byte, findings []Finding) []Finding {
+	whole := ""
+	name := source
+	if u, err := url.Parse(source); err == nil {
+		if libraryCDNs[strings.ToLower(u.Hostname())] {
+			whole = u.Hostname()
+		}
+		if u.Host != "" {
+			name = u.Path
+		}
+	}
+	name = strings.ToLower(path.Base(name))
+	head := leadingComment(body)
+	paths := make(map[string]string)
+	for _, lib := range knownLibraries {
+		if whole == "" && head != nil && lib.namesFile(name) && lib.banner.Match(head) {
+			whole = lib.name
+		}
+		if lib.paths != nil && lib.banner.Match(body) {
+			for _, p := range lib.paths {
+				paths[p] = lib.name
+			}
+		}
+	}
+	if whole == "" && len(paths) == 0 {
+		return findings
+	}
+
+	kept := findings[:0]
+	dropped := make(map[string]int)
+	for _, f := range findings {
+		if suppressibleCategory(f.Category) {
+			if whole != "" {
+				dropped[whole]++
+				continue
+			}
+			if name, ok := libraryPath(paths, f.Value); ok {
+				dropped[name]++
+				continue
+			}
+		}
+		kept = append(kept, f)
+	}
+	lf.mu.Lock()
+	for name, n := range dropped {
+		lf.suppressed[name] += n
+	}
+	lf.mu.Unlock()
+	return kept
+}
+
+func (lib knownLibrary) namesFile(name string) bool {
+	for _, prefix := range lib.files {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// libraryPath matches value, absolute or not, against the known paths; a
+// path ending in '/' also matches everything below it.
+func libraryPath(paths map[string]string, value string) (string, bool) {
+	if u, err := url.Parse(value); err == nil {
+		value = u.Path
+	}
+	if name, ok := paths[value]; ok {
+		return name, true
+	}
+	for p, name := range paths {
+		if strings.HasSuffix(p, "/") && strings.HasPrefix(value, p) {
+			return name, true
+		}
+	}
+	return "", false
+}
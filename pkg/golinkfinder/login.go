@@ -0,0 +1,239 @@
+package golinkfinder
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+	"text/template"
+)
+
+// loginConfig is the config file's login step, run once before the scan:
+//
+//	login:
+//	  url: https://app.example.com/api/login
+//	  headers: {Content-Type: application/json}
+//	  body: '{"user": "{{env "APP_USER"}}", "password": "{{env "APP_PASSWORD"}}"}'
+//	  token: data.access_token
+//
+// The url, headers and body are templates with env and the vars map. The
+// cookies set along the login's redirect chain are sent with every request
+// they match; the token, read from a JSON field (token) or the first group
+// of token_regex, goes in token_header (Authorization: Bearer by default)
+// to the login host and the hosts of the targets.
+type loginConfig struct {
+	URL         string            `yaml:"url"`
+	Method      string            `yaml:"method"`
+	Headers     map[string]string `yaml:"headers"`
+	Body        string            `yaml:"body"`
+	Form        map[string]string `yaml:"form"`
+	Vars        map[string]string `yaml:"vars"`
+	Token       string            `yaml:"token"`
+	TokenRegex  string            `yaml:"token_regex"`
+	TokenHeader string            `yaml:"token_header"`
+	TokenPrefix *string           `yaml:"token_prefix"`
+
+	tokenRegex *regexp.Regexp
+}
+
+func (lc *loginConfig) compile() error {
+	if lc.URL == "" {
+		return fmt.Errorf("login: url is required")
+	}
+	if lc.Body != "" && len(lc.Form) > 0 {
+		return fmt.Errorf("login: use body or form, not both")
+	}
+	lc.Method = strings.ToUpper(lc.Method)
+	if lc.Method == "" {
+		lc.Method = http.MethodPost
+	}
+	if lc.TokenRegex != "" {
+		re, err := regexp.Compile(lc.TokenRegex)
+		if err != nil {
+			return fmt.Errorf("login: invalid token_regex: %v", err)
+		}
+		if re.NumSubexp() < 1 {
+			return fmt.Errorf("login: token_regex needs a capture group")
+		}
+		lc.tokenRegex = re
+	}
+	if lc.TokenHeader == "" {
+		lc.TokenHeader = "Authorization"
+	}
+	return nil
+}
+
+// expand executes one templated login field.
+func (lc *loginConfig) expand(name, text string) (string, error) {
+	t, err := template.New(name).Option("missingkey=error").Funcs(template.FuncMap{"env": os.Getenv}).Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("login: invalid %s template: %v", name, err)
+	}
+	var b strings.Builder
+	if err := t.Execute(&b, lc.Vars); err != nil {
+		return "", fmt.Errorf("login: %s: %v", name, err)
+	}
+	return b.String(), nil
+}
+
+func (lc *loginConfig) request(ctx context.Context) (*http.Request, error) {
+	target, err := lc.expand("url", lc.URL)
+	if err != nil {
+		return nil, err
+	}
+	body, contentType := lc.Body, ""
+	if body, err = lc.expand("body", body); err != nil {
+		return nil, err
+	}
+	if len(lc.Form) > 0 {
+		form := url.Values{}
+		for k, v := range lc.Form {
+			value, err := lc.expand("form."+k, v)
+			if err != nil {
+				return nil, err
+			}
+			form.Set(k, value)
+		}
+		body, contentType = form.Encode(), "application/x-www-form-urlencoded"
+	}
+	req, err := http.NewRequestWithContext(ctx, lc.Method, target, strings.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("login: %v", err)
+	}
+	req.Header.Set("User-Agent", userAgent)
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	for k, v := range lc.Headers {
+		value, err := lc.expand("headers."+k, v)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set(k, value)
+	}
+	return req, nil
+}
+
+// loginSession is what the login step captured.
+type loginSession struct {
+	jar    http.CookieJar
+	host   string
+	header string
+	value  string
+}
+
+// login runs the login step with client, following its redirects into a
+// private cookie jar.
+func login(ctx context.Context, client *http.Client, lc *loginConfig) (*loginSession, error) {
+	req, err := lc.request(ctx)
+	if err != nil {
+		return nil, err
+	}
+	jar, _ := cookiejar.New(nil)
+	lclient := *client
+	lclient.Jar = jar
+	resp, err := lclient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("login failed: %v", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, fmt.Errorf("login failed: %v", err)
+	}
+	if resp.StatusCode >= 400 {
+		return nil, fmt.Errorf("login failed: %s answered %d", resp.Request.URL, resp.StatusCode)
+	}
+
+	ls := &loginSession{jar: jar, host: strings.ToLower(req.URL.Host), header: lc.TokenHeader}
+	token := ""
+	switch {
+	case lc.Token != "":
+		if token, err = jsonField(body, lc.Token); err != nil {
+			return nil, fmt.Errorf("login: %v", err)
+		}
+	case lc.tokenRegex != nil:
+		m := lc.tokenRegex.FindSubmatch(body)
+		if m == nil {
+			return nil, fmt.Errorf("login: token_regex matched nothing in the response")
+		}
+		token = string(m[1])
+	}
+	if token != "" {
+		prefix := ""
+		if lc.TokenPrefix != nil {
+			prefix = *lc.TokenPrefix
+		} else if strings.EqualFold(lc.TokenHeader, "Authorization") {
+			prefix = "Bearer "
+		}
+		ls.value = prefix + token
+	}
+	return ls, nil
+}
+
+// jsonField returns the string at a dotted path (data.access_token) of a
+// JSON document.
+func jsonField(body []byte, path string) (string, error) {
+	var v interface{}
+	d := json.NewDecoder(bytes.NewReader(body))
+	d.UseNumber()
+	if err := d.Decode(&v); err != nil {
+		return "", fmt.Errorf("response is not JSON: %v", err)
+	}
+	for _, key := range strings.Split(path, ".") {
+		obj, ok := v.(map[string]interface{})
+		if !ok {
+			return "", fmt.Errorf("no '%s' in the response", path)
+		}
+		if v, ok = obj[key]; !ok {
+			return "", fmt.Errorf("no '%s' in the response", path)
+		}
+	}
+	switch t := v.(type) {
+	case string:
+		return t, nil
+	case json.Number:
+		return t.String(), nil
+	}
+	return "", fmt.Errorf("'%s' is not a string", path)
+}
+
+// cookies counts the cookies the login set for its own host.
+func (ls *loginSession) cookies() int {
+	return len(ls.jar.Cookies(&url.URL{Scheme: "https", Host: ls.host, Path: "/"}))
+}
+
+// loginTransport sends the login session with every request: its cookies
+// where the jar matches them, and its token to the login host and the
+// target hosts, unless the request sets that header itself.
+type loginTransport struct {
+	next    http.RoundTripper
+	session *loginSession
+	creds   *credentialStore
+}
+
+func (t *loginTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	cookies := t.session.jar.Cookies(req.URL)
+	host := strings.ToLower(req.URL.Host)
+	token := t.session.value != "" && req.Header.Get(t.session.header) == "" && (host == t.session.host || t.creds.trusted(req.Context(), host))
+	if len(cookies) == 0 && !token {
+		return t.next.RoundTrip(req)
+	}
+	req = req.Clone(req.Context())
+	for _, cookie := range cookies {
+		if _, err := req.Cookie(cookie.Name); err != nil {
+			req.AddCookie(cookie)
+		}
+	}
+	if token {
+		req.Header.Set(t.session.header, t.session.value)
+	}
+	return t.next.RoundTrip(req)
+}
@@ -0,0 +1,73 @@
+package golinkfinder
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// authRequiredError marks a target that answered with a login wall instead
+// of its content: it needs credentials rather than a retry.
+type authRequiredError struct {
+	reason string
+}
+
+func (e *authRequiredError) Error() string {
+	return "auth required: " + e.reason
+}
+
+var (
+	// Identity providers and the login routes of common frameworks.
+	idpHostRegex   = regexp.MustCompile(`(?i)(?:^|\.)(?:login\.microsoftonline\.com|login\.live\.com|accounts\.google\.com|[\w-]+\.okta(?:preview)?\.com|[\w-]+\.auth0\.com|[\w-]+\.onelogin\.com|[\w-]+\.amazoncognito\.com|sso\.[\w.-]+|login\.[\w.-]+|auth\.[\w.-]+|idp\.[\w.-]+)$`)
+	idpPathRegex   = regexp.MustCompile(`(?i)/(?:login|log-in|signin|sign-in|sso|saml2?|oauth2?|authorize|adfs|cas/login|auth/realms|realms/[^/]+/protocol/openid-connect|users/sign_in|account/login|wp-login\.php)(?:[/?.]|$)`)
+	loginFormRegex = regexp.MustCompile(`(?i)<input[^>]+type\s*=\s*["']?password`)
+)
+
+// loginWall returns why a 200 answer to target is a login page rather
+// than the requested content, or "". final is the URL after redirects.
+func loginWall(target string, final *url.URL, contentType string, body []byte) string {
+	requested, err := url.Parse(target)
+	if err != nil || final == nil {
+		return ""
+	}
+	redirected := final.Host != requested.Host || final.Path != requested.Path
+	if redirected && !idpPathRegex.MatchString(requested.Path) &&
+		(idpHostRegex.MatchString(final.Hostname()) || idpPathRegex.MatchString(final.Path)) {
+		return "redirected to " + final.Scheme + "://" + final.Host + final.Path
+	}
+	meta := sourceMeta{URL: target, ContentType: contentType}
+	if (redirected || meta.servesErrorPage()) && loginFormRegex.Match(body) {
+		return "answered with a login form"
+	}
+	return ""
+}
+
+// authChallenge returns why a 401 or 407 answer asks for credentials, or
+// "" for other statuses.
+func authChallenge(resp *http.Response) string {
+	switch resp.StatusCode {
+	case http.StatusUnauthorized, http.StatusProxyAuthRequired:
+		if scheme, _, _ := strings.Cut(resp.Header.Get("WWW-Authenticate"), " "); scheme != "" {
+			return fmt.Sprintf("HTTP %d, %s challenge", resp.StatusCode, scheme)
+		}
+		return fmt.Sprintf("HTTP %d", resp.StatusCode)
+	}
+	return ""
+}
+
+// writeAuthRequired saves the targets that need credentials, one per line,
+// ready to be re-scanned with -auth, .netrc or per-target headers.
+func writeAuthRequired(path string, urls []string) error {
+	var b bytes.Buffer
+	for _, u := range urls {
+		b.WriteString(u + "\n")
+	}
+	if err := os.WriteFile(path, b.Bytes(), 0o644); err != nil {
+		return fmt.Errorf("could not write auth-required list: %v", err)
+	}
+	return nil
+}
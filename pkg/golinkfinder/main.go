@@ -0,0 +1,178 @@
+// Author- r4gh4v
+package golinkfinder
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+type Colors struct {
+	Red    string
+	Green  string
+	Yellow string
+	Blue   string
+	End    string
+	Bold   string
+}
+
+var c Colors
+
+// findingsOut is where findings go, and statusOut the banners, progress and
+// summaries around them. Both are stdout, except that -machine and stdio
+// send statusOut to stderr so stdout carries only results.
+var (
+	findingsOut io.Writer = os.Stdout
+	statusOut   io.Writer = os.Stdout
+)
+
+// output is where a scan session writes, and in which colors. The
+// commands' sessions share the package's colors and writers, set from
+// their flags; a Scanner's session has its own, so embedding one leaves
+// those of the host program alone. Code only the commands run, such as
+// flag parsing and reports, writes to the package's directly.
+type output struct {
+	c        Colors
+	findings io.Writer
+	status   io.Writer
+	errs     io.Writer
+}
+
+// cliOutput is the output of a command's session.
+func cliOutput() *output {
+	return &output{c: c, findings: findingsOut, status: statusOut, errs: os.Stderr}
+}
+
+// themes are the palettes of -theme: bright colors for dark terminals and
+// plain ones, readable on a white background, for light terminals.
+var themes = map[string]Colors{
+	"dark":  {Red: "\033[91m", Green: "\033[92m", Yellow: "\033[93m", Blue: "\033[94m", End: "\033[0m", Bold: "\033[1m"},
+	"light": {Red: "\033[31m", Green: "\033[32m", Yellow: "\033[35m", Blue: "\033[34m", End: "\033[0m", Bold: "\033[1m"},
+}
+
+// initColors sets the palette. Colors are off with -no-color, with
+// NO_COLOR set (no-color.org), and when stdout isn't a terminal (or is a
+// Windows console without ANSI support), unless FORCE_COLOR or
+// CLICOLOR_FORCE asks for them. theme is dark, light, or
+// empty for $GOLINKFINDER_THEME.
+func initColors(noColor bool, theme string) {
+	c = Colors{}
+	if noColor || !colorWanted() {
+		return
+	}
+	// Progress and errors go to stderr in color too.
+	enableVirtualTerminal(os.Stderr)
+	if theme == "" {
+		theme = os.Getenv("GOLINKFINDER_THEME")
+	}
+	palette, ok := themes[strings.ToLower(theme)]
+	if !ok {
+		palette = themes["dark"]
+	}
+	c = palette
+}
+
+func colorWanted() bool {
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	if force := os.Getenv("FORCE_COLOR"); force != "" && force != "0" || os.Getenv("CLICOLOR_FORCE") == "1" {
+		return true
+	}
+	if os.Getenv("TERM") == "dumb" {
+		return false
+	}
+	return isTerminal(os.Stdout) && enableVirtualTerminal(os.Stdout)
+}
+
+func isTerminal(f *os.File) bool {
+	stat, err := f.Stat()
+	return err == nil && stat.Mode()&os.ModeCharDevice != 0
+}
+
+// checkTheme validates a -theme value.
+func checkTheme(theme string) error {
+	if _, ok := themes[strings.ToLower(theme)]; theme != "" && !ok {
+		return fmt.Errorf("unknown -theme '%s' (valid: dark, light)", theme)
+	}
+	return nil
+}
+
+// categoryColor is the color a finding's value is printed in: secrets red,
+// hosts and addresses blue, endpoints and the rest green.
+func categoryColor(category string) string {
+	switch category {
+	case categorySecret, categoryJWT, categoryBackend:
+		return c.Red
+	case categoryInternal, categoryIPv4, categoryIPv6, categoryRealtime, categoryEmail:
+		return c.Blue
+	}
+	return c.Green
+}
+
+func fatal(err error) {
+	fmt.Fprintf(os.Stderr, "%s[!] Error: %v%s\n", c.Red, err, c.End)
+	os.Exit(1)
+}
+
+// stringList is a flag.Value collecting every occurrence of a repeatable flag.
+type stringList []string
+
+func (s *stringList) String() string {
+	return strings.Join(*s, ",")
+}
+
+func (s *stringList) Set(value string) error {
+	*s = append(*s, value)
+	return nil
+}
+
+type command struct {
+	name    string
+	summary string
+	run     func(args []string)
+}
+
+var commands = []command{
+	{"scan", "Extract endpoints from JavaScript files (default).", runScan},
+	{"probe", "Scan, then request every resolved endpoint and report its status.", runProbe},
+	{"diff", "Compare two endpoint lists and show what was added or removed.", runDiff},
+	{"report", "Summarize the results stored in a -db database.", runReport},
+	{"monitor", "Re-scan targets on an interval and report newly found endpoints.", runMonitor},
+	{"query", "Query the results stored in a -db database.", runQuery},
+	{"serve", "Serve scans over an HTTP API (POST /scan, GET /results).", runServe},
+	{"stdio", "Answer JSONL scan requests on stdin with JSONL results on stdout, for embedding.", runStdio},
+	{"bench", "Measure extraction throughput and per-rule cost on local files.", runBench},
+	{"rules", "Install or update the signed community rule pack (rules update, rules status).", runRules},
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "%sGoLinkFinder - A fast, concurrent endpoint finder for JavaScript files.%s\n\n", c.Bold, c.End)
+	fmt.Fprintf(os.Stderr, "Usage: golinkfinder <command> [flags]\n\nCommands:\n")
+	for _, cmd := range commands {
+		fmt.Fprintf(os.Stderr, "  %-9s %s\n", cmd.name, cmd.summary)
+	}
+	fmt.Fprintf(os.Stderr, "\nRun 'golinkfinder <command> -h' for the flags of a command. Without a command, 'scan' is assumed.\n")
+}
+
+// Main runs the golinkfinder command line on os.Args; version is the one
+// the binary reports.
+func Main(v string) {
+	version = v
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "help", "-h", "-help", "--help":
+			initColors(false, "")
+			usage()
+			return
+		}
+		for _, cmd := range commands {
+			if os.Args[1] == cmd.name {
+				cmd.run(os.Args[2:])
+				return
+			}
+		}
+	}
+	runScan(os.Args[1:])
+}
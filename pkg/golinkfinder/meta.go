@@ -0,0 +1,113 @@
+package golinkfinder
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/url"
+	"runtime/debug"
+	"strings"
+)
+
+// version is the one the command passed to Main.
+var version = "dev"
+
+// toolVersion falls back to the module version recorded by go install.
+func toolVersion() string {
+	if version != "dev" {
+		return version
+	}
+	if info, ok := debug.ReadBuildInfo(); ok && info.Main.Version != "" && info.Main.Version != "(devel)" {
+		return info.Main.Version
+	}
+	return version
+}
+
+func newScanID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// redactor is implemented by the values of flags carrying credentials,
+// so they are masked where they are declared instead of in a list of
+// names kept elsewhere.
+type redactor interface {
+	redacted() string
+}
+
+// secretString is a string flag holding a credential; the recorded config
+// only says it was set.
+type secretString string
+
+func (s *secretString) String() string     { return string(*s) }
+func (s *secretString) Set(v string) error { *s = secretString(v); return nil }
+func (s *secretString) redacted() string   { return "***" }
+
+// secretList is a repeatable flag whose values may hold credentials.
+type secretList stringList
+
+func (s *secretList) String() string     { return (*stringList)(s).String() }
+func (s *secretList) Set(v string) error { return (*stringList)(s).Set(v) }
+func (s *secretList) redacted() string   { return "***" }
+
+// secretURL is a URL flag that may embed a password; the recorded config
+// keeps its address with the password masked.
+type secretURL string
+
+func (s *secretURL) String() string     { return string(*s) }
+func (s *secretURL) Set(v string) error { *s = secretURL(v); return nil }
+func (s *secretURL) redacted() string   { return redactURL(string(*s)) }
+
+// effectiveConfig records the value of every flag after profiles and the
+// config file were applied, so a run can be reproduced from its metadata.
+// Credentials are redacted: the config ends up in SARIF, the results
+// database and the summary.
+func effectiveConfig(fs *flag.FlagSet) map[string]string {
+	config := make(map[string]string)
+	fs.VisitAll(func(f *flag.Flag) {
+		value := f.Value.String()
+		if r, ok := f.Value.(redactor); ok && value != "" {
+			value = r.redacted()
+		}
+		config[f.Name] = value
+	})
+	return config
+}
+
+// redactURL masks the password of a URL, or all of it when it doesn't
+// parse as one.
+func redactURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		if strings.Contains(raw, "@") {
+			return "***"
+		}
+		return raw
+	}
+	return u.Redacted()
+}
+
+func configJSON(config map[string]string) string {
+	data, _ := json.Marshal(config)
+	return string(data)
+}
+
+// parseLabels reads the -label key=value pairs of a scan.
+func parseLabels(pairs []string) (map[string]string, error) {
+	if len(pairs) == 0 {
+		return nil, nil
+	}
+	labels := make(map[string]string)
+	for _, pair := range pairs {
+		key, value, ok := strings.Cut(pair, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("-label must be key=value, got '%s'", pair)
+		}
+		labels[key] = strings.TrimSpace(value)
+	}
+	return labels, nil
+}
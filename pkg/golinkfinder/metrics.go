@@ -0,0 +1,162 @@
+package golinkfinder
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// sourceSecondsBuckets are the upper bounds of the source duration
+// histogram.
+var sourceSecondsBuckets = []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60}
+
+// scanMetrics counts what a long-running session (serve, monitor) does,
+// exposed in the Prometheus text format on /metrics. A nil *scanMetrics
+// records nothing.
+type scanMetrics struct {
+	mu sync.Mutex
+	// requests counts HTTP requests by response code class, or "error".
+	requests map[string]int64
+	// sources counts scanned sources by outcome: "ok" or an error class.
+	sources  map[string]int64
+	findings map[string]int64
+	bytes    int64
+	buckets  []int64
+	seconds  float64
+	observed int64
+	scans    int64
+	lastScan time.Time
+	// queued is the number of jobs read from the targets and waiting for
+	// a worker.
+	queued int64
+}
+
+func newScanMetrics() *scanMetrics {
+	return &scanMetrics{
+		requests: make(map[string]int64),
+		sources:  make(map[string]int64),
+		findings: make(map[string]int64),
+		buckets:  make([]int64, len(sourceSecondsBuckets)),
+	}
+}
+
+// observe records one scanned source.
+func (m *scanMetrics) observe(res linkFinderResult) {
+	if m == nil || aborted(res.err) {
+		return
+	}
+	outcome := "ok"
+	if res.err != nil {
+		outcome = errorClass(res.err)
+	}
+	seconds := res.meta.Duration.Seconds()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sources[outcome]++
+	for _, f := range res.findings {
+		m.findings[f.Category]++
+	}
+	m.bytes += res.meta.Bytes
+	for i, bound := range sourceSecondsBuckets {
+		if seconds <= bound {
+			m.buckets[i]++
+		}
+	}
+	m.seconds += seconds
+	m.observed++
+}
+
+// scanDone records the end of a monitor pass or an API scan.
+func (m *scanMetrics) scanDone() {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	m.scans++
+	m.lastScan = time.Now()
+	m.mu.Unlock()
+}
+
+// queue adds n (negative once a worker takes them) to the jobs waiting.
+func (m *scanMetrics) queue(n int) {
+	if m != nil {
+		atomic.AddInt64(&m.queued, int64(n))
+	}
+}
+
+func (m *scanMetrics) request(code string) {
+	m.mu.Lock()
+	m.requests[code]++
+	m.mu.Unlock()
+}
+
+func (m *scanMetrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	m.write(w)
+}
+
+// write prints every metric in the Prometheus text exposition format.
+func (m *scanMetrics) write(w io.Writer) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	family := func(name, kind, help string) {
+		fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+	}
+	labeled := func(name, label string, values map[string]int64) {
+		keys := make([]string, 0, len(values))
+		for k := range values {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			fmt.Fprintf(w, "%s{%s=%q} %d\n", name, label, k, values[k])
+		}
+	}
+
+	family("golinkfinder_requests_total", "counter", "HTTP requests sent, by response code class.")
+	labeled("golinkfinder_requests_total", "code", m.requests)
+	family("golinkfinder_sources_total", "counter", "Sources scanned, by outcome (ok or error class).")
+	labeled("golinkfinder_sources_total", "outcome", m.sources)
+	family("golinkfinder_findings_total", "counter", "Findings extracted, by category, before deduplication.")
+	labeled("golinkfinder_findings_total", "category", m.findings)
+	family("golinkfinder_downloaded_bytes_total", "counter", "Bytes of source bodies downloaded.")
+	fmt.Fprintf(w, "golinkfinder_downloaded_bytes_total %d\n", m.bytes)
+
+	family("golinkfinder_source_duration_seconds", "histogram", "Time taken to fetch and extract a source.")
+	for i, bound := range sourceSecondsBuckets {
+		fmt.Fprintf(w, "golinkfinder_source_duration_seconds_bucket{le=%q} %d\n", strconv.FormatFloat(bound, 'g', -1, 64), m.buckets[i])
+	}
+	fmt.Fprintf(w, "golinkfinder_source_duration_seconds_bucket{le=\"+Inf\"} %d\n", m.observed)
+	fmt.Fprintf(w, "golinkfinder_source_duration_seconds_sum %g\n", m.seconds)
+	fmt.Fprintf(w, "golinkfinder_source_duration_seconds_count %d\n", m.observed)
+
+	family("golinkfinder_queue_depth", "gauge", "Jobs waiting for a worker.")
+	fmt.Fprintf(w, "golinkfinder_queue_depth %d\n", atomic.LoadInt64(&m.queued))
+	family("golinkfinder_scans_total", "counter", "Monitor passes or API scans completed.")
+	fmt.Fprintf(w, "golinkfinder_scans_total %d\n", m.scans)
+	if !m.lastScan.IsZero() {
+		family("golinkfinder_last_scan_timestamp_seconds", "gauge", "When the last scan completed, in Unix time.")
+		fmt.Fprintf(w, "golinkfinder_last_scan_timestamp_seconds %d\n", m.lastScan.Unix())
+	}
+}
+
+// metricsTransport counts every request sent, retries included.
+type metricsTransport struct {
+	next    http.RoundTripper
+	metrics *scanMetrics
+}
+
+func (t *metricsTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		t.metrics.request("error")
+	} else {
+		t.metrics.request(fmt.Sprintf("%dxx", resp.StatusCode/100))
+	}
+	return resp, err
+}
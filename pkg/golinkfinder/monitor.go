@@ -0,0 +1,251 @@
+package golinkfinder
+
+import (
+	"flag"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"time"
+)
+
+func runMonitor(args []string) {
+	o := defaultScanOptions()
+	var interval time.Duration
+	var dashboardAddr, metricsAddr string
+	fs := flag.NewFlagSet("monitor", flag.ExitOnError)
+	addScanFlags(fs, &o)
+	fs.DurationVar(&interval, "interval", time.Hour, "Time to wait between scans.")
+	fs.StringVar(&o.targetsDir, "targets-dir", "", "Also scan the target lists in this directory (one file per list). Edits to it and to -l files are picked up between passes.")
+	fs.StringVar(&dashboardAddr, "dashboard", "", "Serve a web dashboard of the targets, runs and findings in -db on this address (e.g. 127.0.0.1:8090).")
+	fs.StringVar(&metricsAddr, "metrics", "", "Serve Prometheus metrics (requests, errors, findings by category, durations, queue depth) on this loopback address at /metrics (e.g. 127.0.0.1:9464).")
+	urlsToScan := loadTargets(fs, args, &o)
+	// Under -safe without -scope, the scope is the hosts of the targets
+	// and follows them on reload.
+	inputScoped := o.safe && o.scopeFile == ""
+	watcher := newTargetWatcher(&o)
+	o.metrics = metricsAddr != ""
+	if dashboardAddr != "" {
+		if o.dbPath == "" {
+			fatal(fmt.Errorf("-dashboard needs a -db results database"))
+		}
+		if !loopbackAddr(dashboardAddr) {
+			fatal(fmt.Errorf("refusing to serve the dashboard on %s: it has no authentication, so it only listens on loopback", dashboardAddr))
+		}
+	}
+	if metricsAddr != "" && !loopbackAddr(metricsAddr) {
+		fatal(fmt.Errorf("refusing to serve metrics on %s: they name the targets and have no authentication, so they only listen on loopback", metricsAddr))
+	}
+
+	// Per-source output would repeat every endpoint on every pass, so the
+	// session always runs quietly and only new endpoints are printed here.
+	quiet := o.quiet
+	o.quiet = true
+	ctx, stop := signalContext()
+	defer stop()
+	s, err := newScanSession(&o, cliOutput())
+	if err != nil {
+		fatal(err)
+	}
+	defer s.Close()
+	if o.precheck {
+		urlsToScan = s.precheck(ctx, urlsToScan)
+	}
+
+	var stream *ndjsonSink
+	if isNDJSON(o.outputFile) {
+		var err error
+		if stream, err = openNDJSON(o.outputFile); err != nil {
+			fatal(err)
+		}
+		defer stream.Close()
+	}
+
+	var board *dashboard
+	if dashboardAddr != "" {
+		board = &dashboard{rdb: s.rdb}
+		board.setTargets(urlsToScan)
+		listener, err := net.Listen("tcp", dashboardAddr)
+		if err != nil {
+			fatal(fmt.Errorf("could not serve the dashboard: %v", err))
+		}
+		_, board.port, _ = net.SplitHostPort(listener.Addr().String())
+		go http.Serve(listener, board)
+		if !quiet {
+			fmt.Fprintf(statusOut, "%s[*] Dashboard on http://%s%s\n", c.Yellow, dashboardAddr, c.End)
+		}
+	}
+
+	if metricsAddr != "" {
+		listener, err := net.Listen("tcp", metricsAddr)
+		if err != nil {
+			fatal(fmt.Errorf("could not serve metrics: %v", err))
+		}
+		mux := http.NewServeMux()
+		mux.Handle("/metrics", s.metrics)
+		_, port, _ := net.SplitHostPort(listener.Addr().String())
+		go http.Serve(listener, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !loopbackHost(r.Host, port) {
+				http.Error(w, "forbidden host", http.StatusForbidden)
+				return
+			}
+			mux.ServeHTTP(w, r)
+		}))
+		if !quiet {
+			fmt.Fprintf(statusOut, "%s[*] Metrics on http://%s/metrics%s\n", c.Yellow, metricsAddr, c.End)
+		}
+	}
+
+	seen := make(map[string]struct{})
+	// hashes holds the last body hash of every source. Unchanged bodies
+	// aren't extracted again (the session's body cache answers for them),
+	// and changed ones are reported even when they add no endpoint.
+	hashes := make(map[string]string)
+	baseline := true
+	if s.rdb != nil {
+		known, err := s.rdb.knownValues()
+		if err != nil {
+			fatal(err)
+		}
+		seen = known
+		baseline = len(seen) == 0
+		if hashes, err = s.rdb.knownHashes(); err != nil {
+			fatal(err)
+		}
+	}
+
+	// reload re-reads the targets after an edit and reports whether any
+	// were added.
+	reload := func() bool {
+		scope := o.hostScope
+		if inputScoped {
+			o.hostScope = nil
+		}
+		next, err := readTargets(&o)
+		if err != nil {
+			o.hostScope = scope
+			fmt.Fprintf(os.Stderr, "%s[!] Could not reload the targets, keeping the current list: %v%s\n", c.Red, err, c.End)
+			return false
+		}
+		next = prepareTargets(&o, next)
+		if o.precheck {
+			next = s.precheck(ctx, next)
+		}
+		added, removed := diffTargets(urlsToScan, next)
+		urlsToScan = next
+		if board != nil {
+			board.setTargets(urlsToScan)
+		}
+		if len(added) == 0 && len(removed) == 0 {
+			return false
+		}
+		if quiet {
+			for _, u := range added {
+				fmt.Fprintf(os.Stderr, "[ADDED] %s\n", u)
+			}
+			for _, u := range removed {
+				fmt.Fprintf(os.Stderr, "[REMOVED] %s\n", u)
+			}
+		} else {
+			fmt.Fprintf(statusOut, "%s[*] [%s] Targets reloaded: %d added, %d removed, %d in total.%s\n", c.Yellow, time.Now().Format(time.RFC3339), len(added), len(removed), len(urlsToScan), c.End)
+			for _, u := range added {
+				fmt.Fprintf(statusOut, "  %s[+]%s %s\n", c.Green, c.End, u)
+			}
+			for _, u := range removed {
+				fmt.Fprintf(statusOut, "  %s[-]%s %s\n", c.Red, c.End, u)
+			}
+		}
+		return len(added) > 0
+	}
+
+	for pass := 1; ; pass++ {
+		if !quiet {
+			fmt.Fprintf(statusOut, "%s[*] [%s] Pass #%d: scanning %d URL(s)...%s\n", c.Yellow, time.Now().Format(time.RFC3339), pass, len(urlsToScan), c.End)
+		}
+		// The pass clears the jobs it hands out, so it gets a copy.
+		found, failed := s.run(ctx, append([]scanJob(nil), urlsToScan...))
+
+		newCount := 0
+		err := found.Each(func(endpoint string) error {
+			if _, ok := seen[endpoint]; ok {
+				return nil
+			}
+			seen[endpoint] = struct{}{}
+			if stream != nil {
+				if err := stream.write(formatRecord{Endpoint: endpoint, Value: endpoint, Template: templatePath(endpoint), Labels: o.labels}); err != nil {
+					return err
+				}
+			}
+			if baseline {
+				return nil
+			}
+			newCount++
+			if quiet || o.machine {
+				fmt.Fprintln(findingsOut, endpoint)
+			}
+			if !quiet {
+				fmt.Fprintf(statusOut, "  %s[NEW]%s %s\n", c.Green, c.End, endpoint)
+			}
+			return nil
+		})
+		total := found.Len()
+		found.Close()
+		if err != nil {
+			fatal(err)
+		}
+
+		changed := 0
+		for _, m := range s.stats.Sources {
+			if m.Hash == "" {
+				continue
+			}
+			previous, ok := hashes[m.URL]
+			hashes[m.URL] = m.Hash
+			if !ok || previous == m.Hash {
+				continue
+			}
+			changed++
+			if quiet {
+				fmt.Fprintf(os.Stderr, "[CHANGED] %s\n", m.URL)
+			} else {
+				fmt.Fprintf(statusOut, "  %s[CHANGED]%s %s (sha256 %.12s -> %.12s, %d findings)\n", c.Blue, c.End, m.URL, previous, m.Hash, m.Findings)
+			}
+		}
+
+		if !quiet {
+			if baseline {
+				fmt.Fprintf(statusOut, "%s[*] Baseline recorded: %d endpoints (%d failed). Next pass in %s.%s\n", c.Yellow, total, failed, interval, c.End)
+			} else {
+				fmt.Fprintf(statusOut, "%s[*] %d endpoints, %d new, %d changed source(s), %d failed. Next pass in %s.%s\n", c.Yellow, total, newCount, changed, failed, interval, c.End)
+			}
+		}
+		baseline = false
+		s.metrics.scanDone()
+		if board != nil {
+			board.passDone(pass, time.Now().Add(interval))
+		}
+
+		if o.outputFile != "" && stream == nil {
+			if err := writeResultSet(o.outputFile, &memoryResultSet{values: seen}, false, nil); err != nil {
+				fatal(err)
+			}
+		}
+		// Added targets are scanned right away rather than after the
+		// interval.
+		next := time.Now().Add(interval)
+		for wait := interval; wait > 0; wait = time.Until(next) {
+			if watcher != nil && wait > targetPollInterval {
+				wait = targetPollInterval
+			}
+			if sleep(ctx, wait) != nil {
+				return
+			}
+			if watcher != nil && watcher.changed() && reload() {
+				if !quiet {
+					fmt.Fprintf(statusOut, "%s[*] Scanning the added targets now.%s\n", c.Yellow, c.End)
+				}
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package golinkfinder
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// isNDJSON reports whether -o names a streaming NDJSON file.
+func isNDJSON(path string) bool {
+	return strings.EqualFold(filepath.Ext(path), ".ndjson")
+}
+
+// ndjsonSink appends one JSON record per finding as it is found, so a run
+// that is killed still leaves every finding so far, and tail -f sees them
+// live. Each record goes out in a single unbuffered write.
+type ndjsonSink struct {
+	file *os.File
+}
+
+func openNDJSON(path string) (*ndjsonSink, error) {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("could not open output file: %v", err)
+	}
+	return &ndjsonSink{file: file}, nil
+}
+
+func (k *ndjsonSink) write(rec formatRecord) error {
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	if _, err := k.file.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("could not write to '%s': %v", k.file.Name(), err)
+	}
+	return nil
+}
+
+func (k *ndjsonSink) Close() error {
+	if k == nil {
+		return nil
+	}
+	return k.file.Close()
+}
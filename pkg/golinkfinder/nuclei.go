@@ -0,0 +1,116 @@
+package golinkfinder
+
+import (
+	"bytes"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+var nucleiIDChars = regexp.MustCompile(`[^a-z0-9]+`)
+
+type nucleiTemplate struct {
+	ID   string `yaml:"id"`
+	Info struct {
+		Name     string `yaml:"name"`
+		Author   string `yaml:"author"`
+		Severity string `yaml:"severity"`
+		Tags     string `yaml:"tags"`
+	} `yaml:"info"`
+	HTTP []nucleiRequest `yaml:"http"`
+}
+
+type nucleiRequest struct {
+	Method   string          `yaml:"method"`
+	Path     []string        `yaml:"path"`
+	Matchers []nucleiMatcher `yaml:"matchers"`
+}
+
+type nucleiMatcher struct {
+	Type   string `yaml:"type"`
+	Status []int  `yaml:"status"`
+}
+
+// nucleiHosts groups the absolute http(s) endpoints of set by host, each
+// list sorted.
+func nucleiHosts(set resultSet) (map[string][]*url.URL, error) {
+	hosts := make(map[string][]*url.URL)
+	err := set.Each(func(value string) error {
+		u, err := url.Parse(value)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return nil
+		}
+		hosts[u.Host] = append(hosts[u.Host], u)
+		return nil
+	})
+	for _, urls := range hosts {
+		sort.Slice(urls, func(i, j int) bool { return urls[i].String() < urls[j].String() })
+	}
+	return hosts, err
+}
+
+// writeNuclei writes, for every host, <host>.txt listing its endpoints for
+// `nuclei -l` and <host>.yaml, a template requesting each endpoint's path
+// against whichever base URL nuclei is given:
+//
+//	nuclei -l dir/example.com.txt -t cves/
+//	nuclei -u https://example.com -t dir/example.com.yaml
+func writeNuclei(dir string, set resultSet) (int, error) {
+	hosts, err := nucleiHosts(set)
+	if err != nil {
+		return 0, err
+	}
+	dir = longPath(dir)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return 0, fmt.Errorf("could not create nuclei output directory: %v", err)
+	}
+	for host, urls := range hosts {
+		name := safeFileName(strings.NewReplacer("[", "", "]", "").Replace(host))
+		var list strings.Builder
+		paths := make([]string, 0, len(urls))
+		seen := make(map[string]struct{})
+		for _, u := range urls {
+			list.WriteString(u.String() + "\n")
+			path := u.EscapedPath()
+			if u.RawQuery != "" {
+				path += "?" + u.RawQuery
+			}
+			if _, ok := seen[path]; ok {
+				continue
+			}
+			seen[path] = struct{}{}
+			paths = append(paths, "{{RootURL}}"+path)
+		}
+		if err := os.WriteFile(filepath.Join(dir, name+".txt"), []byte(list.String()), 0o644); err != nil {
+			return 0, fmt.Errorf("could not write nuclei targets: %v", err)
+		}
+
+		var t nucleiTemplate
+		t.ID = "golinkfinder-" + strings.Trim(nucleiIDChars.ReplaceAllString(strings.ToLower(host), "-"), "-")
+		t.Info.Name = "Endpoints discovered by golinkfinder on " + host
+		t.Info.Author = "golinkfinder"
+		t.Info.Severity = "info"
+		t.Info.Tags = "golinkfinder,discovery"
+		t.HTTP = []nucleiRequest{{
+			Method:   "GET",
+			Path:     paths,
+			Matchers: []nucleiMatcher{{Type: "status", Status: []int{200}}},
+		}}
+		var data bytes.Buffer
+		enc := yaml.NewEncoder(&data)
+		enc.SetIndent(2)
+		if err := enc.Encode(t); err != nil {
+			return 0, fmt.Errorf("could not encode nuclei template: %v", err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, name+".yaml"), data.Bytes(), 0o644); err != nil {
+			return 0, fmt.Errorf("could not write nuclei template: %v", err)
+		}
+	}
+	return len(hosts), nil
+}
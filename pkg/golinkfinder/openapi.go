@@ -0,0 +1,109 @@
+package golinkfinder
+
+import (
+	"encoding/json"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+var specMethods = []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"}
+
+var specNameHints = []string{"swagger", "openapi", "api-docs", "apidocs"}
+
+// looksLikeSpecURL reports URLs that are worth fetching as an API
+// specification, e.g. /swagger.json, /v3/api-docs or /openapi.yaml.
+func looksLikeSpecURL(value string) bool {
+	u, err := url.Parse(value)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return false
+	}
+	lower := strings.ToLower(u.Path)
+	for _, hint := range specNameHints {
+		if strings.Contains(lower, hint) {
+			ext := path.Ext(lower)
+			return ext == "" || ext == ".json" || ext == ".yaml" || ext == ".yml"
+		}
+	}
+	return false
+}
+
+type apiSpec struct {
+	Swagger  string                            `json:"swagger" yaml:"swagger"`
+	OpenAPI  string                            `json:"openapi" yaml:"openapi"`
+	BasePath string                            `json:"basePath" yaml:"basePath"`
+	Host     string                            `json:"host" yaml:"host"`
+	Servers  []struct{ URL string }            `json:"servers" yaml:"servers"`
+	Paths    map[string]map[string]interface{} `json:"paths" yaml:"paths"`
+}
+
+// parseSpec decodes body as a Swagger 2 or OpenAPI 3 document in JSON or
+// YAML. It returns nil when the body is neither.
+func parseSpec(contentType string, body []byte) *apiSpec {
+	trimmed := strings.TrimSpace(string(body))
+	var spec apiSpec
+	if strings.HasPrefix(trimmed, "{") {
+		if err := json.Unmarshal(body, &spec); err != nil {
+			return nil
+		}
+	} else if strings.Contains(contentType, "yaml") || strings.HasPrefix(trimmed, "openapi:") || strings.HasPrefix(trimmed, "swagger:") || strings.HasPrefix(trimmed, "---") {
+		if err := yaml.Unmarshal(body, &spec); err != nil {
+			return nil
+		}
+	} else {
+		return nil
+	}
+	if (spec.Swagger == "" && spec.OpenAPI == "") || len(spec.Paths) == 0 {
+		return nil
+	}
+	return &spec
+}
+
+// specBase returns the prefix every path in the document is relative to:
+// the first server URL for OpenAPI 3, host plus basePath for Swagger 2.
+func (s *apiSpec) specBase() string {
+	if len(s.Servers) > 0 {
+		return strings.TrimRight(s.Servers[0].URL, "/")
+	}
+	base := strings.TrimRight(s.BasePath, "/")
+	if s.Host != "" {
+		return "//" + s.Host + base
+	}
+	return base
+}
+
+type specExtractor struct{}
+
+func (specExtractor) Extract(source, contentType string, body []byte) []Finding {
+	spec := parseSpec(contentType, body)
+	if spec == nil {
+		return nil
+	}
+	base := spec.specBase()
+
+	paths := make([]string, 0, len(spec.Paths))
+	for p := range spec.Paths {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+
+	findings := make([]Finding, 0, len(paths))
+	for _, p := range paths {
+		methods := make([]string, 0)
+		for _, method := range specMethods {
+			if _, ok := spec.Paths[p][method]; ok {
+				methods = append(methods, strings.ToUpper(method))
+			}
+		}
+		findings = append(findings, Finding{
+			Source:   source,
+			Value:    base + p,
+			Category: categorySpec,
+			Method:   strings.Join(methods, ","),
+		})
+	}
+	return findings
+}
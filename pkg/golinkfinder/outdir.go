@@ -0,0 +1,182 @@
+package golinkfinder
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// categoryFileNames names the -o-dir file of each category; others use the
+// category itself.
+var categoryFileNames = map[string]string{
+	categoryEndpoint:    "endpoints",
+	categoryEmail:       "emails",
+	categoryInternal:    "internal-hosts",
+	categorySecret:      "secrets",
+	categorySpec:        "specs",
+	categoryChunk:       "chunks",
+	categoryModule:      "modules",
+	categoryBackend:     "backends",
+	categoryJWT:         "jwts",
+	categoryFeed:        "feeds",
+	categoryRoute:       "routes",
+	categoryBase:        "bases",
+	categoryEnvEndpoint: "env-endpoints",
+}
+
+// jsonCategories are written as JSON findings because their notes (kind,
+// verification, decoded claims, config) matter as much as the value.
+var jsonCategories = map[string]bool{categorySecret: true, categoryJWT: true, categoryBackend: true}
+
+// categoryFiles collects the unique values of a run by category for -o-dir.
+type categoryFiles struct {
+	values  map[string][]string
+	records map[string][]Finding
+	hosts   map[string]struct{}
+	// infos holds the -resolve-hosts details, saved as hosts.json.
+	infos map[string]*hostInfo
+}
+
+func newCategoryFiles() *categoryFiles {
+	return &categoryFiles{values: make(map[string][]string), records: make(map[string][]Finding), hosts: make(map[string]struct{}), infos: make(map[string]*hostInfo)}
+}
+
+// add records the first occurrence of a value.
+func (cf *categoryFiles) add(f Finding) {
+	if f.HostInfo != nil {
+		cf.infos[f.HostInfo.Host] = f.HostInfo
+	}
+	if jsonCategories[f.Category] {
+		cf.records[f.Category] = append(cf.records[f.Category], f)
+		return
+	}
+	cf.values[f.Category] = append(cf.values[f.Category], f.Value)
+	if u, err := url.Parse(f.Value); err == nil && u.Host != "" {
+		cf.hosts[u.Hostname()] = struct{}{}
+	}
+}
+
+// write saves <category>.txt (or .json) for every category found, plus
+// hosts.txt with the hosts of every absolute URL, and returns the names of
+// the files written.
+func (cf *categoryFiles) write(dir string) ([]string, error) {
+	dir = longPath(dir)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("could not create output directory: %v", err)
+	}
+	var written []string
+	save := func(name string, data []byte) error {
+		if err := os.WriteFile(filepath.Join(dir, name), data, 0o644); err != nil {
+			return fmt.Errorf("could not write %s: %v", name, err)
+		}
+		written = append(written, name)
+		return nil
+	}
+	fileName := func(category string) string {
+		if name, ok := categoryFileNames[category]; ok {
+			return name
+		}
+		return safeFileName(category)
+	}
+
+	for category, values := range cf.values {
+		sort.Strings(values)
+		var b bytes.Buffer
+		for _, v := range values {
+			b.WriteString(v + "\n")
+		}
+		if err := save(fileName(category)+".txt", b.Bytes()); err != nil {
+			return written, err
+		}
+	}
+	for category, records := range cf.records {
+		sort.Slice(records, func(i, j int) bool { return records[i].Value < records[j].Value })
+		data, err := json.MarshalIndent(records, "", "  ")
+		if err != nil {
+			return written, err
+		}
+		if err := save(fileName(category)+".json", append(data, '\n')); err != nil {
+			return written, err
+		}
+	}
+	if len(cf.hosts) > 0 {
+		hosts := make([]string, 0, len(cf.hosts))
+		for h := range cf.hosts {
+			hosts = append(hosts, h)
+		}
+		sort.Strings(hosts)
+		var b bytes.Buffer
+		for _, h := range hosts {
+			b.WriteString(h + "\n")
+		}
+		if err := save("hosts.txt", b.Bytes()); err != nil {
+			return written, err
+		}
+	}
+	if len(cf.infos) > 0 {
+		infos := make([]*hostInfo, 0, len(cf.infos))
+		for _, info := range cf.infos {
+			infos = append(infos, info)
+		}
+		sort.Slice(infos, func(i, j int) bool { return infos[i].Host < infos[j].Host })
+		data, err := json.MarshalIndent(infos, "", "  ")
+		if err != nil {
+			return written, err
+		}
+		if err := save("hosts.json", append(data, '\n')); err != nil {
+			return written, err
+		}
+	}
+	sort.Strings(written)
+	return written, nil
+}
+
+// windowsReserved are the device names Windows refuses as file names, with
+// or without an extension.
+var windowsReserved = map[string]bool{"CON": true, "PRN": true, "AUX": true, "NUL": true,
+	"COM1": true, "COM2": true, "COM3": true, "COM4": true, "COM5": true, "COM6": true, "COM7": true, "COM8": true, "COM9": true,
+	"LPT1": true, "LPT2": true, "LPT3": true, "LPT4": true, "LPT5": true, "LPT6": true, "LPT7": true, "LPT8": true, "LPT9": true}
+
+// maxFileName leaves room for an extension under the 255-byte limit of
+// most file systems.
+const maxFileName = 200
+
+// safeFileName turns a category or host into a file name valid on every
+// platform, so output written on Linux can be copied to Windows and back:
+// path separators, <>:"|?* and control characters become _, trailing dots
+// and spaces are dropped, device names get a _ prefix, and long names are
+// cut.
+func safeFileName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if r < 32 || strings.ContainsRune(`<>:"/\|?*`, r) {
+			return '_'
+		}
+		return r
+	}, name)
+	if len(name) > maxFileName {
+		name = strings.ToValidUTF8(name[:maxFileName], "")
+	}
+	name = strings.TrimRight(name, ". ")
+	if base, _, _ := strings.Cut(name, "."); windowsReserved[strings.ToUpper(base)] {
+		name = "_" + name
+	}
+	if name == "" {
+		name = "_"
+	}
+	return name
+}
+
+// longPath makes dir absolute: Go only lifts the 260-character MAX_PATH
+// limit of Windows for absolute paths, and deep output directories with
+// long host names reach it.
+func longPath(dir string) string {
+	if abs, err := filepath.Abs(dir); err == nil {
+		return abs
+	}
+	return dir
+}
@@ -0,0 +1,63 @@
+package golinkfinder
+
+import (
+	"regexp"
+	"runtime"
+	"sync"
+)
+
+const (
+	// Texts from parallelMatchSize up are matched in windows on every core.
+	parallelMatchSize = 4 << 20
+	matchWindow       = 1 << 20
+	// matchOverlap is the context each window gets on both sides, so
+	// matches crossing a boundary are found whole and with the same
+	// leftmost-first choices as a single pass.
+	matchOverlap = 64 << 10
+)
+
+// findAllSubmatchIndex is re.FindAllStringSubmatchIndex(text, -1), split
+// across goroutines for large texts. Each window keeps the matches that
+// start inside it, so results come back once and in order.
+func findAllSubmatchIndex(re *regexp.Regexp, text string) [][]int {
+	if len(text) < parallelMatchSize {
+		return re.FindAllStringSubmatchIndex(text, -1)
+	}
+	windows := (len(text) + matchWindow - 1) / matchWindow
+	found := make([][][]int, windows)
+	sem := make(chan struct{}, runtime.GOMAXPROCS(0))
+	var wg sync.WaitGroup
+	for w := 0; w < windows; w++ {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(w int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			start, end := w*matchWindow, (w+1)*matchWindow
+			from, to := start-matchOverlap, end+matchOverlap
+			if from < 0 {
+				from = 0
+			}
+			if to > len(text) {
+				to = len(text)
+			}
+			for _, loc := range re.FindAllStringSubmatchIndex(text[from:to], -1) {
+				if loc[0]+from < start || loc[0]+from >= end {
+					continue
+				}
+				for i := range loc {
+					if loc[i] >= 0 {
+						loc[i] += from
+					}
+				}
+				found[w] = append(found[w], loc)
+			}
+		}(w)
+	}
+	wg.Wait()
+	var all [][]int
+	for _, locs := range found {
+		all = append(all, locs...)
+	}
+	return all
+}
@@ -0,0 +1,165 @@
+package golinkfinder
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Extractor turns a fetched body into findings. The built-in rules and every
+// -plugin process implement it.
+type Extractor interface {
+	Extract(source, contentType string, body []byte) []Finding
+}
+
+type ruleExtractor struct {
+	rules       []extractionRule
+	stringsOnly bool
+}
+
+func (r ruleExtractor) Extract(source, contentType string, body []byte) []Finding {
+	return extract(source, contentType, body, r.rules, r.stringsOnly)
+}
+
+type pluginRequest struct {
+	Source      string `json:"source"`
+	ContentType string `json:"content_type"`
+	Body        []byte `json:"body"`
+}
+
+type pluginFinding struct {
+	Value    string `json:"value"`
+	Category string `json:"category"`
+}
+
+type pluginResponse struct {
+	Findings []pluginFinding `json:"findings"`
+	Error    string          `json:"error,omitempty"`
+}
+
+// subprocessExtractor talks to a long-running plugin process: one JSON
+// request per line on its stdin (body base64-encoded), one JSON response per
+// request on its stdout. Calls are serialized, so plugins need no locking.
+// A plugin that takes longer than timeout to answer is killed.
+type subprocessExtractor struct {
+	name    string
+	timeout time.Duration
+	mu      sync.Mutex
+	cmd     *exec.Cmd
+	kill    context.CancelFunc
+	in      io.WriteCloser
+	stdout  io.ReadCloser
+	enc     *json.Encoder
+	dec     *json.Decoder
+	dead    bool
+	// out gets the plugin's stderr and failures.
+	out *output
+}
+
+func startPlugin(command string, timeout time.Duration, out *output) (*subprocessExtractor, error) {
+	fields := strings.Fields(command)
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("empty plugin command")
+	}
+	ctx, kill := context.WithCancel(context.Background())
+	cmd := exec.CommandContext(ctx, fields[0], fields[1:]...)
+	cmd.Stderr = out.errs
+	in, err := cmd.StdinPipe()
+	var stdout io.ReadCloser
+	if err == nil {
+		stdout, err = cmd.StdoutPipe()
+	}
+	if err == nil {
+		err = cmd.Start()
+	}
+	if err != nil {
+		kill()
+		return nil, fmt.Errorf("could not start plugin '%s': %v", command, err)
+	}
+	return &subprocessExtractor{
+		name:    fields[0],
+		timeout: timeout,
+		cmd:     cmd,
+		kill:    kill,
+		in:      in,
+		stdout:  stdout,
+		enc:     json.NewEncoder(in),
+		dec:     json.NewDecoder(stdout),
+		out:     out,
+	}, nil
+}
+
+func (p *subprocessExtractor) Extract(source, contentType string, body []byte) []Finding {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.dead {
+		return nil
+	}
+
+	var resp pluginResponse
+	err := p.call(pluginRequest{Source: source, ContentType: contentType, Body: body}, &resp)
+	if err != nil {
+		// A broken pipe, garbled response or late answer leaves the
+		// stream out of sync, so the plugin is disabled for the rest of
+		// the run.
+		p.dead = true
+		fmt.Fprintf(p.out.errs, "%s[!] Plugin %s failed and was disabled: %v%s\n", p.out.c.Red, p.name, err, p.out.c.End)
+		return nil
+	}
+	if resp.Error != "" {
+		fmt.Fprintf(p.out.errs, "%s[-] Plugin %s on %s: %s%s\n", p.out.c.Red, p.name, source, resp.Error, p.out.c.End)
+	}
+
+	findings := make([]Finding, 0, len(resp.Findings))
+	for _, pf := range resp.Findings {
+		if pf.Value == "" {
+			continue
+		}
+		category := pf.Category
+		if category == "" {
+			category = categoryEndpoint
+		}
+		findings = append(findings, Finding{Source: source, Value: pf.Value, Category: category})
+	}
+	return findings
+}
+
+// call sends req and reads the answer into resp. Past the timeout, the
+// plugin is killed and its pipes closed, which ends the exchange.
+func (p *subprocessExtractor) call(req pluginRequest, resp *pluginResponse) error {
+	done := make(chan error, 1)
+	go func() {
+		err := p.enc.Encode(req)
+		if err == nil {
+			err = p.dec.Decode(resp)
+		}
+		done <- err
+	}()
+	var expired <-chan time.Time
+	if p.timeout > 0 {
+		timer := time.NewTimer(p.timeout)
+		defer timer.Stop()
+		expired = timer.C
+	}
+	select {
+	case err := <-done:
+		return err
+	case <-expired:
+	}
+	p.kill()
+	p.in.Close()
+	p.stdout.Close()
+	<-done
+	return fmt.Errorf("no answer within %s", p.timeout)
+}
+
+func (p *subprocessExtractor) Close() error {
+	p.in.Close()
+	defer p.kill()
+	return p.cmd.Wait()
+}
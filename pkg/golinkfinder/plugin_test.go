@@ -0,0 +1,55 @@
+package golinkfinder
+
+import (
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// shellPlugin starts a plugin running script with sh.
+func shellPlugin(t *testing.T, script string, timeout time.Duration) *subprocessExtractor {
+	t.Helper()
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not installed")
+	}
+	path := filepath.Join(t.TempDir(), "plugin.sh")
+	if err := os.WriteFile(path, []byte(script), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	p, err := startPlugin("sh "+path, timeout, &output{errs: io.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { p.Close() })
+	return p
+}
+
+func TestPluginExtract(t *testing.T) {
+	p := shellPlugin(t, `while read -r line; do echo '{"findings": [{"value": "/x"}]}'; done`, time.Second)
+	for i := 0; i < 2; i++ {
+		findings := p.Extract("https://example.com/app.js", "application/javascript", []byte("x"))
+		if len(findings) != 1 || findings[0].Value != "/x" || findings[0].Category != categoryEndpoint {
+			t.Fatalf("call %d: got %+v", i, findings)
+		}
+	}
+}
+
+func TestPluginTimeout(t *testing.T) {
+	p := shellPlugin(t, "read -r line; exec sleep 60", 100*time.Millisecond)
+	start := time.Now()
+	if findings := p.Extract("https://example.com/app.js", "application/javascript", []byte("x")); findings != nil {
+		t.Errorf("got %+v from a plugin that never answered", findings)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Extract returned after %s", elapsed)
+	}
+	if !p.dead {
+		t.Error("the plugin was not disabled")
+	}
+	if findings := p.Extract("https://example.com/b.js", "application/javascript", []byte("x")); findings != nil {
+		t.Errorf("a disabled plugin returned %+v", findings)
+	}
+}
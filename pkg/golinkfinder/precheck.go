@@ -0,0 +1,152 @@
+package golinkfinder
+
+import (
+	"context"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Reasons -precheck drops a target for.
+const (
+	precheckDead    = "dead host"
+	precheckGone    = "not found"
+	precheckMoved   = "permanent redirect"
+	precheckNonText = "non-text content"
+)
+
+// nonTextTypes are content types never worth downloading for a scan.
+var nonTextTypes = []string{"image/", "video/", "audio/", "font/", "application/octet-stream", "application/pdf", "application/zip", "application/x-", "application/vnd."}
+
+// precheck HEADs every URL target, without following redirects, and drops
+// those on dead hosts, gone (404/410), permanently moved, or serving
+// non-text content. Requests go through -rate and -delay like the scan's.
+// Once a host fails to resolve, shake hands or, after -retries (at least
+// one), to connect, its other targets are dropped without a request.
+// Targets that reject HEAD are kept.
+func (s *scanSession) precheck(ctx context.Context, jobs []scanJob) []scanJob {
+	o := s.opts
+	client := *s.client
+	client.CheckRedirect = func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }
+
+	var deadHosts sync.Map
+	verdicts := make([]string, len(jobs))
+	sem := make(chan struct{}, o.threads)
+	var wg sync.WaitGroup
+	for i, job := range jobs {
+		if job.body != nil || !strings.HasPrefix(job.url, "http") {
+			continue
+		}
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, job scanJob) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			host := ""
+			if u, err := url.Parse(job.url); err == nil {
+				host = strings.ToLower(u.Host)
+			}
+			if _, dead := deadHosts.Load(host); dead {
+				verdicts[i] = precheckDead
+				return
+			}
+			verdict, hostDown := s.precheckTarget(ctx, &client, job)
+			if hostDown {
+				deadHosts.Store(host, true)
+			}
+			verdicts[i] = verdict
+		}(i, job)
+	}
+	wg.Wait()
+	if ctx.Err() != nil {
+		return jobs
+	}
+
+	kept := make([]scanJob, 0, len(jobs))
+	skipped := make(map[string]int)
+	for i, job := range jobs {
+		if verdicts[i] == "" {
+			kept = append(kept, job)
+			continue
+		}
+		skipped[verdicts[i]]++
+	}
+	if !o.quiet {
+		reasons := make([]string, 0, len(skipped))
+		for reason, n := range skipped {
+			reasons = append(reasons, fmt.Sprintf("%d %s", n, reason))
+		}
+		sort.Strings(reasons)
+		summary := ""
+		if len(reasons) > 0 {
+			summary = "; skipped " + strings.Join(reasons, ", ")
+		}
+		fmt.Fprintf(statusOut, "%s[*] Pre-check: kept %d of %d target(s)%s.%s\n", c.Yellow, len(kept), len(jobs), summary, c.End)
+	}
+	return kept
+}
+
+// precheckTarget returns why job should be dropped, or "", and whether its
+// host is unreachable.
+func (s *scanSession) precheckTarget(ctx context.Context, client *http.Client, job scanJob) (string, bool) {
+	retries := s.opts.retries
+	if retries < 1 {
+		retries = 1
+	}
+	var resp *http.Response
+	for attempt := 0; ; attempt++ {
+		if attempt > 0 && sleep(ctx, time.Duration(attempt)*time.Second) != nil {
+			return "", false
+		}
+		if s.pause(ctx) != nil || s.waitRate(ctx) != nil {
+			return "", false
+		}
+		req, err := http.NewRequestWithContext(ctx, http.MethodHead, job.url, nil)
+		if err != nil {
+			return "", false
+		}
+		for name, values := range job.headers {
+			req.Header[name] = values
+		}
+		if job.host != "" {
+			req.Host = job.host
+		}
+		resp, err = client.Do(req)
+		if err == nil {
+			break
+		}
+		switch errorClass(err) {
+		case classDNS, classTLS:
+			return precheckDead, true
+		case classConnection:
+			// A refused or reset connection may be passing.
+			if attempt < retries {
+				continue
+			}
+			return precheckDead, true
+		}
+		// Timeouts and the rest get their chance in the scan.
+		return "", false
+	}
+	resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusNotFound, http.StatusGone:
+		return precheckGone, false
+	case http.StatusMovedPermanently, http.StatusPermanentRedirect:
+		return precheckMoved, false
+	}
+	if resp.StatusCode < 300 {
+		mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+		for _, prefix := range nonTextTypes {
+			if strings.HasPrefix(mediaType, prefix) && !strings.Contains(mediaType, "javascript") && !strings.Contains(mediaType, "json") && !strings.Contains(mediaType, "xml") {
+				return precheckNonText, false
+			}
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,246 @@
+package golinkfinder
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"text/template"
+)
+
+type probeResult struct {
+	url    string
+	method string
+	source string
+	status int
+	length int64
+	// allow is the Allow header of an OPTIONS answer.
+	allow string
+	// simhash fingerprints the first MiB of the body for -probe-cluster.
+	simhash uint64
+	// hash is the SHA-256 of the whole body, for -probe-filter-body.
+	hash string
+	// wildcard marks an answer matching the origin's catch-all response.
+	wildcard bool
+	err      error
+}
+
+// probeMethods are the methods -probe-methods accepts; none of them is
+// meant to change anything on the target.
+var probeMethods = []string{"GET", "HEAD", "OPTIONS"}
+
+func parseProbeMethods(list string) ([]string, error) {
+	var methods []string
+	seen := make(map[string]bool)
+	for _, m := range strings.Split(list, ",") {
+		m = strings.ToUpper(strings.TrimSpace(m))
+		if m == "" || seen[m] {
+			continue
+		}
+		valid := false
+		for _, p := range probeMethods {
+			valid = valid || m == p
+		}
+		if !valid {
+			return nil, fmt.Errorf("unsupported -probe-methods method '%s' (valid: %s)", m, strings.Join(probeMethods, ", "))
+		}
+		seen[m] = true
+		methods = append(methods, m)
+	}
+	if len(methods) == 0 {
+		return nil, fmt.Errorf("-probe-methods needs at least one method")
+	}
+	return methods, nil
+}
+
+func probeURL(ctx context.Context, client *http.Client, method, target string) probeResult {
+	req, err := http.NewRequestWithContext(ctx, method, target, nil)
+	if err != nil {
+		return probeResult{url: target, method: method, err: fmt.Errorf("could not create request: %v", err)}
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return probeResult{url: target, method: method, err: fmt.Errorf("http request failed: %w", err)}
+	}
+	defer resp.Body.Close()
+
+	h := sha256.New()
+	body := io.TeeReader(resp.Body, h)
+	head, _ := io.ReadAll(io.LimitReader(body, 1<<20))
+	rest, _ := io.Copy(io.Discard, body)
+	res := probeResult{url: target, method: method, status: resp.StatusCode, length: int64(len(head)) + rest, simhash: simhash(head), hash: hex.EncodeToString(h.Sum(nil))}
+	if method == "OPTIONS" {
+		res.allow = strings.Join(resp.Header.Values("Allow"), ", ")
+	}
+	return res
+}
+
+func statusColor(status int) string {
+	switch {
+	case status >= 200 && status < 300:
+		return c.Green
+	case status >= 300 && status < 400:
+		return c.Blue
+	case status >= 400 && status < 500:
+		return c.Yellow
+	default:
+		return c.Red
+	}
+}
+
+// probeEndpoints requests every absolute endpoint with each -probe-methods
+// method and the configured number of threads, and prints one status line
+// per request as results arrive, or one format line when a -format template
+// is given. With -probe-cluster,
+// answers matching a host's catch-all response or repeating too often are
+// collapsed into a summary at the end, as are answers with the body of a
+// -probe-filter-body sample. Probing stops when ctx is done or
+// after -probe-timeout.
+func probeEndpoints(ctx context.Context, client *http.Client, endpoints resultSet, o *scanOptions, format *template.Template) {
+	ctx, cancel := withTimeout(ctx, o.probeTimeout)
+	defer cancel()
+	if !o.quiet {
+		fmt.Fprintf(statusOut, "\n%s[*] Probing endpoints with %d threads...%s\n", c.Yellow, o.threads, c.End)
+	}
+
+	jobs := make(chan probeResult, o.threads)
+	results := make(chan probeResult, o.threads)
+	baselines := newProbeBaselines()
+	clusters := &probeClusters{limit: o.probeCluster}
+	wildcards := make(map[string]int)
+	sampled := make(map[string]int)
+	var wg sync.WaitGroup
+	for i := 0; i < o.threads; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for target := range jobs {
+				res := probeURL(ctx, client, target.method, target.url)
+				if ctx.Err() != nil {
+					continue
+				}
+				res.source = target.source
+				// The catch-all baseline is a GET; other methods are only
+				// clustered among themselves.
+				if o.probeCluster > 0 && res.method == "GET" {
+					res.wildcard = similarResponses(res, baselines.get(ctx, client, res.url))
+				}
+				results <- res
+			}
+		}()
+	}
+	// unsafe counts the endpoints -safe or -scope keep from being probed.
+	unsafe := 0
+	go func() {
+		endpoints.EachWithRefs(func(endpoint string, refs []sourceRef) error {
+			if !strings.HasPrefix(endpoint, "http://") && !strings.HasPrefix(endpoint, "https://") {
+				return nil
+			}
+			if !o.hostScope.allows(endpoint) || (o.safe && looksStateChanging(endpoint)) {
+				unsafe++
+				return nil
+			}
+			target := probeResult{url: endpoint}
+			if len(refs) > 0 {
+				target.source = refs[0].Source
+			}
+			for _, method := range o.probeVerbs {
+				target.method = method
+				select {
+				case jobs <- target:
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+			}
+			return nil
+		})
+		close(jobs)
+	}()
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	for res := range results {
+		if sample := o.probeFilter.match(res); sample != "" {
+			sampled[sample]++
+			continue
+		}
+		if o.probeCluster > 0 {
+			if res.wildcard {
+				if u, err := url.Parse(res.url); err == nil {
+					wildcards[u.Host]++
+				}
+				continue
+			}
+			if !clusters.add(res) {
+				continue
+			}
+		}
+		if format != nil || o.jsonl {
+			rec := formatRecord{Source: res.source, Endpoint: res.url, Value: res.url, Template: templatePath(res.url), Method: res.method, Status: res.status, Length: res.length, Allow: res.allow, Labels: o.labels}
+			if res.err != nil {
+				rec.Error, rec.ErrorClass = res.err.Error(), errorClass(res.err)
+			}
+			printRecord(format, o.jsonl, rec)
+			continue
+		}
+		// A lone GET keeps the original "status length url" lines.
+		target := res.url
+		if len(o.probeVerbs) > 1 || res.method != "GET" {
+			target = res.method + " " + res.url
+		}
+		if res.err != nil {
+			if !o.quiet {
+				fmt.Fprintf(statusOut, "  %s[ERR] %s: %v%s\n", c.Red, target, res.err, c.End)
+			}
+			continue
+		}
+		allow := ""
+		if res.allow != "" {
+			allow = " (Allow: " + res.allow + ")"
+		}
+		if o.quiet || o.machine {
+			fmt.Fprintf(findingsOut, "%d %d %s%s\n", res.status, res.length, target, allow)
+		}
+		if !o.quiet {
+			fmt.Fprintf(statusOut, "  %s[%d]%s [%d] %s%s\n", statusColor(res.status), res.status, c.End, res.length, target, allow)
+		}
+	}
+	if ctx.Err() != nil {
+		fmt.Fprintf(os.Stderr, "%s[!] Probing stopped early (%v).%s\n", c.Yellow, ctx.Err(), c.End)
+	}
+	if o.quiet {
+		return
+	}
+	if unsafe > 0 {
+		fmt.Fprintf(statusOut, "%s[!] %d endpoint(s) not probed: out of scope or state-changing-looking under -safe%s\n", c.Yellow, unsafe, c.End)
+	}
+	for sample, n := range sampled {
+		fmt.Fprintf(statusOut, "%s[!] %d endpoint(s) hidden, same body as the -probe-filter-body sample %s%s\n", c.Yellow, n, sample, c.End)
+	}
+	for host, n := range wildcards {
+		fmt.Fprintf(statusOut, "%s[!] %s: %d endpoint(s) hidden, same answer as a non-existent path (catch-all route)%s\n", c.Yellow, host, n, c.End)
+	}
+	for _, cl := range clusters.collapsed() {
+		fmt.Fprintf(statusOut, "%s[!] %d more endpoint(s) answered like %s %s [%d] [%d], hidden%s\n", c.Yellow, cl.count-clusters.limit, cl.first.method, cl.first.url, cl.first.status, cl.first.length, c.End)
+	}
+}
+
+func runProbe(args []string) {
+	o := defaultScanOptions()
+	o.resolve = true
+	o.probe = true
+	fs := flag.NewFlagSet("probe", flag.ExitOnError)
+	addScanFlags(fs, &o)
+	fs.IntVar(&o.probeCluster, "probe-cluster", o.probeCluster, "Show at most this many probe answers with the same status, length and body, and hide answers matching a non-existent path (0 shows all).")
+	scanAndReport(fs, args, &o)
+}
@@ -0,0 +1,67 @@
+package golinkfinder
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math/bits"
+	"os"
+)
+
+// probeBodyFilter holds the -probe-filter-body samples: saved copies of an
+// application's "not found" page. Apps that answer unknown routes with a
+// custom 200 can't be filtered by status, so probe answers with the same
+// body are hidden instead.
+type probeBodyFilter struct {
+	samples []probeSample
+}
+
+type probeSample struct {
+	name    string
+	hash    string
+	simhash uint64
+	length  int64
+}
+
+func loadProbeBodyFilter(files []string) (*probeBodyFilter, error) {
+	if len(files) == 0 {
+		return nil, nil
+	}
+	f := &probeBodyFilter{}
+	for _, name := range files {
+		body, err := os.ReadFile(name)
+		if err != nil {
+			return nil, fmt.Errorf("could not read -probe-filter-body sample: %v", err)
+		}
+		sum := sha256.Sum256(body)
+		f.samples = append(f.samples, probeSample{name: name, hash: hex.EncodeToString(sum[:]), simhash: simhash(body), length: int64(len(body))})
+	}
+	return f, nil
+}
+
+// match returns the sample a probe answer's body matches, or "". Besides an
+// identical body, a near-identical one (same length within 10%, simhashes a
+// few bits apart) matches, so pages embedding a request ID or a CSRF token
+// are still caught.
+func (f *probeBodyFilter) match(res probeResult) string {
+	if f == nil || res.err != nil {
+		return ""
+	}
+	for _, s := range f.samples {
+		if res.hash == s.hash {
+			return s.name
+		}
+		diff := res.length - s.length
+		if diff < 0 {
+			diff = -diff
+		}
+		max := res.length
+		if s.length > max {
+			max = s.length
+		}
+		if max > 0 && diff*10 <= max && bits.OnesCount64(res.simhash^s.simhash) <= 3 {
+			return s.name
+		}
+	}
+	return ""
+}
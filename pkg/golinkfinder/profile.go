@@ -0,0 +1,83 @@
+package golinkfinder
+
+import (
+	"flag"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// builtinProfiles are flag presets selected with -profile. Flags given
+// explicitly on the command line always win over the profile.
+var builtinProfiles = map[string]map[string]string{
+	"stealth": {
+		"t":        "2",
+		"rate":     "2",
+		"retries":  "2",
+		"adaptive": "true",
+		"jitter":   "500ms",
+	},
+	"fast": {
+		"t":        "50",
+		"rate":     "0",
+		"retries":  "0",
+		"adaptive": "false",
+	},
+	"thorough": {
+		"t":            "20",
+		"retries":      "3",
+		"adaptive":     "true",
+		"extract":      "all",
+		"follow-specs": "true",
+	},
+}
+
+// resolveProfile merges the built-in preset called name with any override
+// of the same name from the config file.
+func resolveProfile(name string, cfg *fileConfig) (map[string]string, error) {
+	profile := make(map[string]string)
+	builtin, ok := builtinProfiles[name]
+	for k, v := range builtin {
+		profile[k] = v
+	}
+	if override, found := cfg.Profiles[name]; found {
+		ok = true
+		for k, v := range override {
+			profile[k] = v
+		}
+	}
+	if !ok {
+		names := make([]string, 0, len(builtinProfiles)+len(cfg.Profiles))
+		for n := range builtinProfiles {
+			names = append(names, n)
+		}
+		for n := range cfg.Profiles {
+			if _, dup := builtinProfiles[n]; !dup {
+				names = append(names, n)
+			}
+		}
+		sort.Strings(names)
+		return nil, fmt.Errorf("unknown profile '%s' (available: %s)", name, strings.Join(names, ", "))
+	}
+	return profile, nil
+}
+
+// applyProfile sets every flag of the profile that was not given explicitly.
+func applyProfile(fs *flag.FlagSet, profile map[string]string) error {
+	explicit := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) {
+		explicit[f.Name] = true
+	})
+	for name, value := range profile {
+		if explicit[name] {
+			continue
+		}
+		if fs.Lookup(name) == nil {
+			return fmt.Errorf("profile sets unknown flag -%s", name)
+		}
+		if err := fs.Set(name, value); err != nil {
+			return fmt.Errorf("profile sets invalid value for -%s: %v", name, err)
+		}
+	}
+	return nil
+}
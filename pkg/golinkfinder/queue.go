@@ -0,0 +1,215 @@
+package golinkfinder
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"os"
+	"strings"
+)
+
+// stdin is read through a buffer so the start of a piped input can be
+// looked at before deciding how to read it.
+var stdin = bufio.NewReaderSize(os.Stdin, 64<<10)
+
+func stdinPiped() bool {
+	stat, err := os.Stdin.Stat()
+	return err == nil && stat.Mode()&os.ModeCharDevice == 0
+}
+
+// jobFeed hands out the targets of a scan pass one at a time.
+type jobFeed interface {
+	next() (scanJob, bool)
+	// size is the number of jobs left, or -1 when unknown.
+	size() int
+}
+
+type sliceFeed struct {
+	jobs []scanJob
+}
+
+func (f *sliceFeed) next() (scanJob, bool) {
+	if len(f.jobs) == 0 {
+		return scanJob{}, false
+	}
+	job := f.jobs[0]
+	// Let a handed-out job's body be collected once it is scanned.
+	f.jobs[0] = scanJob{}
+	f.jobs = f.jobs[1:]
+	return job, true
+}
+
+func (f *sliceFeed) size() int {
+	return len(f.jobs)
+}
+
+// jobKey identifies a target for de-duplication. The same URL with
+// different headers (another user's cookies, say) is a separate target.
+func jobKey(job scanJob) string {
+	return fmt.Sprint(job.method, " ", slashless(job.url), " ", job.host, " ", job.headers)
+}
+
+func hashKey(key string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(key))
+	return h.Sum64()
+}
+
+// targetStream reads a -l file or a piped list of URLs or JSON targets a
+// line at a time as workers free up, so a list of millions of targets
+// starts scanning at once and is never held in memory. Each line goes
+// through the filters readTargetList applies to a whole list; duplicates
+// are dropped by hash.
+type targetStream struct {
+	o       *scanOptions
+	creds   *credentialStore
+	reader  *bufio.Reader
+	closer  io.Closer
+	line    int
+	include []string
+	exclude []string
+	seen    map[uint64]struct{}
+	// The targets each filter dropped.
+	droppedExt, droppedScope, droppedSafe, duplicates int
+}
+
+// openTargetStream returns a stream over the target list when it is the
+// only input, or nil when it has to be read whole: several inputs, CSV
+// lists, piped content, and
+// -crawl, -precheck, -dry-run or -safe without -scope, which need every
+// target before the first request.
+func openTargetStream(o *scanOptions) (*targetStream, error) {
+	if o.crawl || o.precheck || o.dryRun || (o.safe && o.hostScope == nil) || o.stdinBody {
+		return nil, nil
+	}
+	if o.gitRepo != "" || o.burpFile != "" || o.archive != "" || len(o.targetURLs) > 0 || len(o.urlLists) > 1 {
+		return nil, nil
+	}
+	ts := &targetStream{o: o, include: splitList(o.ext), exclude: splitList(o.excludeExt), seen: make(map[uint64]struct{})}
+	switch {
+	case len(o.urlLists) == 1 && o.urlLists[0] != "-":
+		file, err := os.Open(o.urlLists[0])
+		if err != nil {
+			return nil, fmt.Errorf("the file '%s' was not found: %v", o.urlLists[0], err)
+		}
+		ts.reader, ts.closer = bufio.NewReaderSize(file, 64<<10), file
+	case stdinPiped():
+		ts.reader = stdin
+	default:
+		return nil, nil
+	}
+	first := peekLine(ts.reader)
+	if strings.HasPrefix(strings.ToLower(first), "url,") || (ts.closer == nil && !looksLikeURLList([]byte(first))) {
+		ts.Close()
+		return nil, nil
+	}
+	return ts, nil
+}
+
+// peekLine returns the first non-empty line of r without consuming it, as
+// far as r's buffer goes.
+func peekLine(r *bufio.Reader) string {
+	for n := 1; ; {
+		data, err := r.Peek(n)
+		lines := bytes.Split(data, []byte("\n"))
+		for i, line := range lines {
+			// The last line is only complete at EOF or a full buffer.
+			if line = bytes.TrimSpace(line); len(line) > 0 && (i < len(lines)-1 || err != nil) {
+				return string(line)
+			}
+		}
+		if err != nil {
+			return ""
+		}
+		n = len(data) + 1
+	}
+}
+
+func (ts *targetStream) next() (scanJob, bool) {
+	o := ts.o
+	for {
+		text, err := ts.reader.ReadString('\n')
+		if text == "" && err != nil {
+			if err != io.EOF {
+				fatal(fmt.Errorf("could not read targets: %v", err))
+			}
+			return scanJob{}, false
+		}
+		ts.line++
+		line := strings.TrimSpace(text)
+		if line == "" {
+			continue
+		}
+		job, perr := parseTarget(line)
+		if perr != nil {
+			fatal(fmt.Errorf("line %d: %v", ts.line, perr))
+		}
+		job.input = true
+		jobs := []scanJob{job}
+		var dropped int
+		if jobs, dropped = filterByExtension(jobs, ts.include, ts.exclude); dropped > 0 {
+			ts.droppedExt++
+			continue
+		}
+		if o.hostScope != nil {
+			if jobs, dropped = o.hostScope.filterJobs(jobs); dropped > 0 {
+				ts.droppedScope++
+				continue
+			}
+		}
+		if o.safe {
+			if jobs, dropped = safeJobs(jobs); dropped > 0 {
+				ts.droppedSafe++
+				continue
+			}
+		}
+		canonicalJobs(jobs, o.stripQuery)
+		job = jobs[0]
+		if job.host == "" {
+			job.host = o.hostHeader
+		}
+		key := hashKey(jobKey(job))
+		if _, ok := ts.seen[key]; ok && job.body == nil {
+			ts.duplicates++
+			continue
+		}
+		ts.seen[key] = struct{}{}
+		if ts.creds != nil {
+			ts.creds.trust(jobs)
+		}
+		return job, true
+	}
+}
+
+func (ts *targetStream) size() int {
+	return -1
+}
+
+// report prints what the filters dropped, as readTargetList does before
+// a scan.
+func (ts *targetStream) report() {
+	if ts.o.quiet {
+		return
+	}
+	if ts.droppedExt > 0 {
+		fmt.Fprintf(statusOut, "%s[*] %d input URL(s) dropped by -ext/-exclude-ext.%s\n", c.Yellow, ts.droppedExt, c.End)
+	}
+	if ts.droppedScope > 0 {
+		fmt.Fprintf(statusOut, "%s[*] %d input URL(s) dropped as out of -scope.%s\n", c.Yellow, ts.droppedScope, c.End)
+	}
+	if ts.droppedSafe > 0 {
+		fmt.Fprintf(statusOut, "%s[*] %d input target(s) dropped by -safe (not GET/HEAD, or state-changing-looking).%s\n", c.Yellow, ts.droppedSafe, c.End)
+	}
+	if ts.duplicates > 0 {
+		fmt.Fprintf(statusOut, "%s[*] %d duplicate input target(s) skipped.%s\n", c.Yellow, ts.duplicates, c.End)
+	}
+}
+
+func (ts *targetStream) Close() error {
+	if ts.closer == nil {
+		return nil
+	}
+	return ts.closer.Close()
+}
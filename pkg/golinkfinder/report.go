@@ -0,0 +1,125 @@
+package golinkfinder
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+)
+
+func runReport(args []string) {
+	var (
+		dbPath     string
+		top        int
+		noColor    bool
+		theme      string
+		compare    bool
+		format     string
+		output     string
+		ignoreFile string
+		noIgnore   bool
+	)
+	fs := flag.NewFlagSet("report", flag.ExitOnError)
+	fs.StringVar(&dbPath, "db", "", "SQLite results database to summarize.")
+	fs.IntVar(&top, "top", 10, "Number of top sources to list.")
+	fs.BoolVar(&noColor, "no-color", false, "Disable colorized output.")
+	fs.StringVar(&theme, "theme", "", "Color theme: dark, or light for light terminals (default $GOLINKFINDER_THEME, else dark).")
+	fs.BoolVar(&compare, "compare", false, "Compare two stored runs (-o .json lists, -jsonl output or plain lists) given as arguments: added, removed and changed endpoints and secrets.")
+	fs.StringVar(&format, "format", "text", "Output format of -compare: text, json or html.")
+	fs.StringVar(&output, "o", "", "Write the -compare report to this file instead of stdout.")
+	fs.StringVar(&ignoreFile, "ignore-file", "", "File of triaged findings -compare leaves out (default ./"+defaultIgnoreFile+" when present).")
+	fs.BoolVar(&noIgnore, "no-ignore", false, "Don't read the ignore file.")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: golinkfinder report -db results.db\n       golinkfinder report -compare [flags] <old.json> <new.json>\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if err := checkTheme(theme); err != nil {
+		fatal(err)
+	}
+	initColors(noColor, theme)
+
+	if compare {
+		if fs.NArg() != 2 {
+			fs.Usage()
+			os.Exit(1)
+		}
+		ignore, err := openIgnoreList(ignoreFile, noIgnore)
+		if err != nil {
+			fatal(err)
+		}
+		runCompare(fs.Arg(0), fs.Arg(1), format, output, ignore)
+		return
+	}
+	if dbPath == "" {
+		fs.Usage()
+		fatal(errors.New("-db is required"))
+	}
+	rdb, err := openResultsDB(dbPath)
+	if err != nil {
+		fatal(err)
+	}
+	defer rdb.Close()
+
+	var runs, sources, failing, endpoints int
+	err = rdb.db.QueryRow(`SELECT (SELECT COUNT(*) FROM runs), (SELECT COUNT(*) FROM sources),
+		(SELECT COUNT(*) FROM sources WHERE last_error != ''), (SELECT COUNT(*) FROM endpoints)`).
+		Scan(&runs, &sources, &failing, &endpoints)
+	if err != nil {
+		fatal(err)
+	}
+
+	fmt.Printf("%s%s[*] Report for %s%s%s\n", c.Bold, c.Yellow, dbPath, c.End, c.End)
+	fmt.Printf("  Runs:      %d\n", runs)
+	fmt.Printf("  Sources:   %d (%d failing on their last scan)\n", sources, failing)
+	fmt.Printf("  Endpoints: %d\n", endpoints)
+
+	var (
+		started, scanID, runVersion string
+		targets, failed, runTotals  int
+	)
+	err = rdb.db.QueryRow(`SELECT started_at, scan_id, version, targets, failed, endpoints FROM runs ORDER BY id DESC LIMIT 1`).
+		Scan(&started, &scanID, &runVersion, &targets, &failed, &runTotals)
+	if err == nil {
+		fmt.Printf("  Last run:  %s (targets=%d failed=%d endpoints=%d)\n", started, targets, failed, runTotals)
+		if scanID != "" {
+			fmt.Printf("  Scan ID:   %s (golinkfinder %s)\n", scanID, runVersion)
+		}
+	}
+
+	rows, err := rdb.db.Query(`SELECT category, COUNT(*) FROM endpoints GROUP BY category ORDER BY COUNT(*) DESC`)
+	if err != nil {
+		fatal(err)
+	}
+	fmt.Printf("\n%s[+] Endpoints by category:%s\n", c.Blue, c.End)
+	for rows.Next() {
+		var (
+			category string
+			count    int
+		)
+		if err := rows.Scan(&category, &count); err != nil {
+			fatal(err)
+		}
+		fmt.Printf("  %-12s %s%d%s\n", category, c.Green, count, c.End)
+	}
+	rows.Close()
+
+	rows, err = rdb.db.Query(`SELECT s.url, COUNT(es.endpoint_id) AS n FROM sources s
+		JOIN endpoint_sources es ON es.source_id = s.id
+		GROUP BY s.id ORDER BY n DESC, s.url LIMIT ?`, top)
+	if err != nil {
+		fatal(err)
+	}
+	fmt.Printf("\n%s[+] Top sources by endpoint count:%s\n", c.Blue, c.End)
+	for rows.Next() {
+		var (
+			source string
+			count  int
+		)
+		if err := rows.Scan(&source, &count); err != nil {
+			fatal(err)
+		}
+		fmt.Printf("  %s%6d%s  %s\n", c.Green, count, c.End, source)
+	}
+	rows.Close()
+}
@@ -0,0 +1,39 @@
+package golinkfinder
+
+// reporterBacklog is how many outputs can wait for the reporter before
+// queuing blocks.
+const reporterBacklog = 1024
+
+// reporter runs the output of a scan pass (printing, -o streams, the
+// results database) on its own goroutine, in the order it was queued. The
+// queue is bounded: a slow terminal or sink absorbs a burst, then holds up
+// the results loop and through it the workers, so memory stays bounded like
+// the job and result channels.
+type reporter struct {
+	queue chan func()
+	done  chan struct{}
+}
+
+func newReporter() *reporter {
+	r := &reporter{queue: make(chan func(), reporterBacklog), done: make(chan struct{})}
+	go r.loop()
+	return r
+}
+
+func (r *reporter) loop() {
+	defer close(r.done)
+	for fn := range r.queue {
+		fn()
+	}
+}
+
+// do queues fn, waiting while the queue is full.
+func (r *reporter) do(fn func()) {
+	r.queue <- fn
+}
+
+// close waits until everything queued has run.
+func (r *reporter) close() {
+	close(r.queue)
+	<-r.done
+}
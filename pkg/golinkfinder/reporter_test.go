@@ -0,0 +1,106 @@
+package golinkfinder
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestReporterOrder(t *testing.T) {
+	rep := newReporter()
+	var got []int
+	for i := 0; i < 3*reporterBacklog; i++ {
+		rep.do(func() { got = append(got, i) })
+	}
+	rep.close()
+	if len(got) != 3*reporterBacklog {
+		t.Fatalf("%d of %d outputs ran", len(got), 3*reporterBacklog)
+	}
+	for i, v := range got {
+		if v != i {
+			t.Fatalf("output %d ran as number %d", v, i)
+		}
+	}
+}
+
+func TestReporterBackpressure(t *testing.T) {
+	rep := newReporter()
+	unblock := make(chan struct{})
+	rep.do(func() { <-unblock })
+	// Wait for the reporter to take the blocking output off the queue.
+	for len(rep.queue) > 0 {
+		time.Sleep(time.Millisecond)
+	}
+	for i := 0; i < reporterBacklog; i++ {
+		rep.do(func() {})
+	}
+	queued := make(chan struct{})
+	go func() {
+		rep.do(func() {})
+		close(queued)
+	}()
+	select {
+	case <-queued:
+		t.Fatal("do returned with the queue full")
+	case <-time.After(50 * time.Millisecond):
+	}
+	close(unblock)
+	select {
+	case <-queued:
+	case <-time.After(5 * time.Second):
+		t.Fatal("do still blocked once the reporter caught up")
+	}
+	rep.close()
+}
+
+// slowWriter is a terminal that takes a while for every write.
+type slowWriter struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (w *slowWriter) Write(p []byte) (int, error) {
+	time.Sleep(100 * time.Microsecond)
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.buf.Write(p)
+}
+
+// Run with -race: the reporter writes while the results loop goes on.
+func TestRunSlowOutput(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/javascript")
+		fmt.Fprintf(w, "fetch('/api%s');", r.URL.Path)
+	}))
+	defer srv.Close()
+	out := &slowWriter{}
+	defer func(colors Colors, s, f io.Writer) { c, statusOut, findingsOut = colors, s, f }(c, statusOut, findingsOut)
+	c, statusOut, findingsOut = Colors{}, out, out
+
+	o := defaultScanOptions()
+	o.threads = 8
+	s, err := newScanSession(&o, cliOutput())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	jobs := make([]scanJob, 100)
+	for i := range jobs {
+		jobs[i] = scanJob{url: fmt.Sprintf("%s/%d", srv.URL, i)}
+	}
+	found, _ := s.run(context.Background(), jobs)
+	defer found.Close()
+	printed := out.buf.String()
+	for i := range jobs {
+		if !strings.Contains(printed, fmt.Sprintf("/api/%d ", i)) {
+			t.Errorf("/api/%d was not printed", i)
+		}
+	}
+}
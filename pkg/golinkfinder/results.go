@@ -0,0 +1,234 @@
+package golinkfinder
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"sort"
+)
+
+// sourceRef records where a value was seen: the source and the position of
+// its first occurrence there (Line 0 when unknown).
+type sourceRef struct {
+	Source string `json:"source"`
+	Line   int    `json:"line,omitempty"`
+	Offset int    `json:"offset"`
+}
+
+// resultSet holds the unique values of a run. The in-memory set is the
+// default; -spill-dir swaps in a SQLite-backed one so memory stays flat no
+// matter how many values a huge scan produces. Source references are only
+// kept when the set was created to track them.
+type resultSet interface {
+	// Add records value as seen at ref and reports whether the value is new.
+	Add(value string, ref sourceRef) (bool, error)
+	Len() int
+	// Each calls fn for every value in sorted order, stopping at the first error.
+	Each(fn func(value string) error) error
+	// EachWithRefs is Each with the sources of every value, sorted by source.
+	EachWithRefs(fn func(value string, refs []sourceRef) error) error
+	Close() error
+}
+
+type memoryResultSet struct {
+	values map[string]struct{}
+	refs   map[string][]sourceRef
+}
+
+func newMemoryResultSet(trackRefs bool) *memoryResultSet {
+	m := &memoryResultSet{values: make(map[string]struct{})}
+	if trackRefs {
+		m.refs = make(map[string][]sourceRef)
+	}
+	return m
+}
+
+func (m *memoryResultSet) Add(value string, ref sourceRef) (bool, error) {
+	if m.refs != nil {
+		known := false
+		for _, r := range m.refs[value] {
+			if r.Source == ref.Source {
+				known = true
+				break
+			}
+		}
+		if !known {
+			m.refs[value] = append(m.refs[value], ref)
+		}
+	}
+	if _, ok := m.values[value]; ok {
+		return false, nil
+	}
+	m.values[value] = struct{}{}
+	return true, nil
+}
+
+func (m *memoryResultSet) Len() int {
+	return len(m.values)
+}
+
+func (m *memoryResultSet) Each(fn func(value string) error) error {
+	return m.EachWithRefs(func(value string, _ []sourceRef) error {
+		return fn(value)
+	})
+}
+
+func (m *memoryResultSet) EachWithRefs(fn func(value string, refs []sourceRef) error) error {
+	sorted := make([]string, 0, len(m.values))
+	for value := range m.values {
+		sorted = append(sorted, value)
+	}
+	sort.Strings(sorted)
+	for _, value := range sorted {
+		refs := m.refs[value]
+		sort.Slice(refs, func(i, j int) bool {
+			return refs[i].Source < refs[j].Source
+		})
+		if err := fn(value, refs); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *memoryResultSet) Close() error {
+	return nil
+}
+
+type diskResultSet struct {
+	path      string
+	db        *sql.DB
+	insert    *sql.Stmt
+	insertRef *sql.Stmt
+	count     int
+}
+
+func newDiskResultSet(dir string, trackRefs bool) (*diskResultSet, error) {
+	file, err := os.CreateTemp(dir, "golinkfinder-*.sqlite")
+	if err != nil {
+		return nil, fmt.Errorf("could not create spill file: %v", err)
+	}
+	path := file.Name()
+	file.Close()
+
+	d := &diskResultSet{path: path}
+	d.db, err = sql.Open("sqlite", path)
+	if err == nil {
+		d.db.SetMaxOpenConns(1)
+		_, err = d.db.Exec(`PRAGMA journal_mode = OFF; PRAGMA synchronous = OFF;
+			CREATE TABLE seen (value TEXT PRIMARY KEY) WITHOUT ROWID;
+			CREATE TABLE refs (value TEXT, source TEXT, line INTEGER, offset INTEGER, PRIMARY KEY (value, source)) WITHOUT ROWID`)
+	}
+	if err == nil {
+		d.insert, err = d.db.Prepare(`INSERT OR IGNORE INTO seen (value) VALUES (?)`)
+	}
+	if err == nil && trackRefs {
+		d.insertRef, err = d.db.Prepare(`INSERT OR IGNORE INTO refs (value, source, line, offset) VALUES (?, ?, ?, ?)`)
+	}
+	if err != nil {
+		if d.db != nil {
+			d.db.Close()
+		}
+		os.Remove(path)
+		return nil, fmt.Errorf("could not open spill file: %v", err)
+	}
+	return d, nil
+}
+
+func (d *diskResultSet) Add(value string, ref sourceRef) (bool, error) {
+	if d.insertRef != nil {
+		if _, err := d.insertRef.Exec(value, ref.Source, ref.Line, ref.Offset); err != nil {
+			return false, err
+		}
+	}
+	res, err := d.insert.Exec(value)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil || n == 0 {
+		return false, err
+	}
+	d.count++
+	return true, nil
+}
+
+func (d *diskResultSet) Len() int {
+	return d.count
+}
+
+func (d *diskResultSet) Each(fn func(value string) error) error {
+	rows, err := d.db.Query(`SELECT value FROM seen ORDER BY value`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var value string
+		if err := rows.Scan(&value); err != nil {
+			return err
+		}
+		if err := fn(value); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+func (d *diskResultSet) EachWithRefs(fn func(value string, refs []sourceRef) error) error {
+	rows, err := d.db.Query(`SELECT s.value, COALESCE(r.source, ''), COALESCE(r.line, 0), COALESCE(r.offset, 0)
+		FROM seen s LEFT JOIN refs r ON r.value = s.value ORDER BY s.value, r.source`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	var (
+		current string
+		refs    []sourceRef
+		started bool
+	)
+	for rows.Next() {
+		var (
+			value string
+			ref   sourceRef
+		)
+		if err := rows.Scan(&value, &ref.Source, &ref.Line, &ref.Offset); err != nil {
+			return err
+		}
+		if started && value != current {
+			if err := fn(current, refs); err != nil {
+				return err
+			}
+			refs = nil
+		}
+		current, started = value, true
+		if ref.Source != "" {
+			refs = append(refs, ref)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	if started {
+		return fn(current, refs)
+	}
+	return nil
+}
+
+func (d *diskResultSet) Close() error {
+	d.insert.Close()
+	if d.insertRef != nil {
+		d.insertRef.Close()
+	}
+	err := d.db.Close()
+	os.Remove(d.path)
+	return err
+}
+
+func newResultSet(spillDir string, trackRefs bool) (resultSet, error) {
+	if spillDir == "" {
+		return newMemoryResultSet(trackRefs), nil
+	}
+	return newDiskResultSet(spillDir, trackRefs)
+}
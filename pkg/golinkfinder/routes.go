@@ -0,0 +1,117 @@
+package golinkfinder
+
+import (
+	"path"
+	"regexp"
+	"strings"
+)
+
+const categoryRoute = "route"
+
+var (
+	// routeHintRegex gates the route extractor to scripts that configure a
+	// client-side router.
+	routeHintRegex = regexp.MustCompile(`\broutes\s*:|\bchildren\s*:|RouterModule|createBrowserRouter|createHashRouter|createRouter|VueRouter|useRoutes|<Route\b|\.Route\b`)
+	// routePathRegex matches path: "..." in route objects (React Router,
+	// Vue Router, Angular) and path="..." on JSX <Route> elements.
+	routePathRegex  = regexp.MustCompile(`\bpath\s*:\s*["'` + "`" + `]([^"'` + "`" + `\s]{0,200})["'` + "`" + `]|<Route\b[^>]*?\spath\s*=\s*["']([^"'\s]{0,200})["']`)
+	routeValueRegex = regexp.MustCompile(`^[\w\-./:*?()~@%+\\]+$`)
+)
+
+// routeExtractor reports the route patterns of client-side router tables,
+// parameters included (/users/:id), under "route". Child routes are joined
+// to the paths of the route objects they are nested in, so Angular's
+// {path: "users", children: [{path: ":id"}]} gives /users/:id.
+type routeExtractor struct{}
+
+func (routeExtractor) Extract(source, contentType string, body []byte) []Finding {
+	content := string(body)
+	if !strings.Contains(content, "path") || !routeHintRegex.MatchString(content) {
+		return nil
+	}
+	matches := routePathRegex.FindAllStringSubmatchIndex(content, -1)
+	if len(matches) == 0 {
+		return nil
+	}
+	// Braces inside string literals don't open objects.
+	spans := []textSpan{}
+	if !isHTML(contentType, body) {
+		spans = jsStringSpans(content, isJSXLang(sourceLang(source)))
+	}
+	inString := func(i int) bool { return within(spans, i, i+1) }
+	lines := newLineIndex(content)
+
+	// objectPaths maps the '{' of a route object to its joined path; open
+	// holds the '{' of every object enclosing the current position.
+	objectPaths := make(map[int]string)
+	var open []int
+	seen := make(map[string]struct{})
+	findings := make([]Finding, 0)
+	next := 0
+	for i := 0; i < len(content) && next < len(matches); i++ {
+		for next < len(matches) && matches[next][0] == i {
+			m := matches[next]
+			next++
+			if inString(m[0]) {
+				continue
+			}
+			// JSX elements aren't objects, so only path: keys nest.
+			value, start, jsx := "", 0, m[2] < 0
+			if jsx {
+				value, start = content[m[4]:m[5]], m[4]
+			} else {
+				value, start = content[m[2]:m[3]], m[2]
+				if value != "" && !inString(start) {
+					continue
+				}
+			}
+			if value != "" && !routeValueRegex.MatchString(value) {
+				continue
+			}
+			value = strings.ReplaceAll(value, `\\`, `\`)
+			parent := ""
+			for j := len(open) - 1; j >= 0 && !jsx; j-- {
+				if p, ok := objectPaths[open[j]]; ok {
+					parent = p
+					break
+				}
+			}
+			route := joinRoute(parent, value)
+			if len(open) > 0 && !jsx {
+				objectPaths[open[len(open)-1]] = route
+			}
+			if route == "/" || strings.Contains(value, "**") || value == "*" {
+				continue
+			}
+			if _, ok := seen[route]; ok {
+				continue
+			}
+			seen[route] = struct{}{}
+			findings = append(findings, Finding{Source: source, Value: route, Category: categoryRoute, Line: lines.line(start), Offset: start})
+		}
+		switch content[i] {
+		case '{':
+			if !inString(i) {
+				open = append(open, i)
+			}
+		case '}':
+			if !inString(i) && len(open) > 0 {
+				open = open[:len(open)-1]
+			}
+		}
+	}
+	return findings
+}
+
+// joinRoute appends a route segment to its parent's path; absolute child
+// paths (Vue) stand alone.
+func joinRoute(parent, value string) string {
+	if strings.HasPrefix(value, "/") || parent == "" {
+		parent = "/"
+	}
+	joined := path.Join(parent, value)
+	if strings.HasSuffix(value, "/") && joined != "/" {
+		joined += "/"
+	}
+	return joined
+}
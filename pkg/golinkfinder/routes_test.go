@@ -0,0 +1,77 @@
+package golinkfinder
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestRouteExtractor(t *testing.T) {
+	tests := []struct {
+		name, source, content string
+		want                  []string
+	}{
+		{
+			"React Router objects",
+			"https://app.example/main.js",
+			`const router = createBrowserRouter([{path: "/", element: e(Root), children: [{path: "users", children: [{path: ":id"}, {path: ":id/edit"}]}, {path: "*"}]}]);`,
+			[]string{"/users", "/users/:id", "/users/:id/edit"},
+		},
+		{
+			"Vue Router with absolute children",
+			"https://app.example/app.js",
+			`new VueRouter({routes: [{path: '/admin', component: A, children: [{path: 'settings'}, {path: '/login'}]}, {path: '/posts/:slug(\\d+)'}]})`,
+			[]string{"/admin", "/admin/settings", "/login", `/posts/:slug(\d+)`},
+		},
+		{
+			"Angular RouterModule",
+			"https://app.example/main.js",
+			"RouterModule.forRoot([{path: `orders`, children: [{path: '', component: List}, {path: ':orderId'}]}, {path: '**', redirectTo: ''}])",
+			[]string{"/orders", "/orders/:orderId"},
+		},
+		{
+			"JSX elements",
+			"https://app.example/App.jsx",
+			`<Routes><Route path="/teams" element={<Teams />}><Route path="/teams/:teamId" element={<Team />} /></Route></Routes>`,
+			[]string{"/teams", "/teams/:teamId"},
+		},
+		{
+			"path keys inside strings",
+			"https://app.example/main.js",
+			`createRouter({routes: []}); const help = "use {path: '/not-a-route'} here";`,
+			nil,
+		},
+		{
+			"no router",
+			"https://app.example/main.js",
+			`const cfg = {path: "/tmp/cache"};`,
+			nil,
+		},
+	}
+	for _, tt := range tests {
+		var got []string
+		for _, f := range (routeExtractor{}).Extract(tt.source, "application/javascript", []byte(tt.content)) {
+			if f.Category != categoryRoute {
+				t.Errorf("%s: %q has category %q", tt.name, f.Value, f.Category)
+			}
+			got = append(got, f.Value)
+		}
+		if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+			t.Errorf("%s: got %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestJoinRoute(t *testing.T) {
+	tests := []struct{ parent, value, want string }{
+		{"", "users", "/users"},
+		{"/users", ":id", "/users/:id"},
+		{"/users", "/login", "/login"},
+		{"/users", "", "/users"},
+		{"/docs", "guide/", "/docs/guide/"},
+	}
+	for _, tt := range tests {
+		if got := joinRoute(tt.parent, tt.value); got != tt.want {
+			t.Errorf("joinRoute(%q, %q) = %q, want %q", tt.parent, tt.value, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,389 @@
+package golinkfinder
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// rulePack is a versioned set of community rules: extraction rules in the
+// config-file format (endpoint patterns, secret rules with category
+// "secret"...) and noise filters in the ignore-file format.
+type rulePack struct {
+	Version string     `json:"version"`
+	Rules   []userRule `json:"rules"`
+	Ignore  []string   `json:"ignore"`
+}
+
+// rulePackConfig is the rule_pack section of the config file:
+//
+//	rule_pack:
+//	  registry: https://rules.example.com/golinkfinder
+//	  key: <base64 ed25519 public key>
+//	  version: 2026.10.1    # pin
+//
+// A registry serves index.json ({"latest": "<version>"}) and, per version,
+// <version>/pack.json with its detached signature <version>/pack.json.sig
+// (base64 ed25519 over the pack bytes).
+type rulePackConfig struct {
+	Registry string `yaml:"registry"`
+	Key      string `yaml:"key"`
+	Version  string `yaml:"version"`
+}
+
+// rulePackLock records the installed pack next to it. File names the pack
+// in the rule pack directory; locks written before packs were stored by
+// hash have none and mean pack.json.
+type rulePackLock struct {
+	Registry string    `json:"registry"`
+	Version  string    `json:"version"`
+	Pinned   bool      `json:"pinned"`
+	SHA256   string    `json:"sha256"`
+	File     string    `json:"file,omitempty"`
+	Updated  time.Time `json:"updated"`
+}
+
+func (l *rulePackLock) file() string {
+	if l.File == "" {
+		return "pack.json"
+	}
+	return l.File
+}
+
+const rulePackMaxSize = 8 << 20
+
+func rulePackDir() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "golinkfinder", "rules")
+}
+
+func readRulePackLock(dir string) (*rulePackLock, error) {
+	data, err := os.ReadFile(filepath.Join(dir, "lock.json"))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	lock := &rulePackLock{}
+	if err := json.Unmarshal(data, lock); err != nil {
+		return nil, fmt.Errorf("could not parse rule pack lock: %v", err)
+	}
+	return lock, nil
+}
+
+// loadRulePack reads the installed pack, or returns nil when there is none.
+// The pack must still hash to what its lock recorded.
+func loadRulePack(dir string) (*rulePack, error) {
+	if dir == "" {
+		return nil, nil
+	}
+	lock, err := readRulePackLock(dir)
+	if err != nil || lock == nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(filepath.Join(dir, lock.file()))
+	if err != nil {
+		return nil, fmt.Errorf("could not read rule pack: %v", err)
+	}
+	if sum := sha256.Sum256(data); hex.EncodeToString(sum[:]) != lock.SHA256 {
+		return nil, fmt.Errorf("rule pack in '%s' does not match its lock; run 'golinkfinder rules update'", dir)
+	}
+	return parseRulePack(data)
+}
+
+func parseRulePack(data []byte) (*rulePack, error) {
+	pack := &rulePack{}
+	if err := json.Unmarshal(data, pack); err != nil {
+		return nil, fmt.Errorf("could not parse rule pack: %v", err)
+	}
+	if pack.Version == "" {
+		return nil, errors.New("rule pack has no version")
+	}
+	return pack, nil
+}
+
+// verifyRulePack parses a downloaded pack once its detached signature
+// checks out against publicKey and it is the version that was asked for.
+func verifyRulePack(publicKey ed25519.PublicKey, data, sig []byte, version string) (*rulePack, error) {
+	signature, err := base64.StdEncoding.DecodeString(string(bytes.TrimSpace(sig)))
+	if err != nil || !ed25519.Verify(publicKey, data, signature) {
+		return nil, fmt.Errorf("rule pack %s is not signed by the configured key", version)
+	}
+	pack, err := parseRulePack(data)
+	if err != nil {
+		return nil, err
+	}
+	if pack.Version != version {
+		return nil, fmt.Errorf("registry served rule pack %s for version %s", pack.Version, version)
+	}
+	return pack, nil
+}
+
+// compile returns the pack's extraction rules and noise filters.
+func (p *rulePack) compile() ([]extractionRule, *ignoreList, error) {
+	rules, err := compileUserRules(p.Rules)
+	if err != nil {
+		return nil, nil, fmt.Errorf("rule pack %s: %v", p.Version, err)
+	}
+	ignore, err := parseIgnoreList("rule pack "+p.Version, strings.NewReader(strings.Join(p.Ignore, "\n")))
+	if err != nil {
+		return nil, nil, err
+	}
+	return rules, ignore, nil
+}
+
+// merge adds the entries of other to l, either of which may be nil.
+func (l *ignoreList) merge(other *ignoreList) *ignoreList {
+	if other == nil || len(other.entries) == 0 {
+		return l
+	}
+	if l == nil {
+		return other
+	}
+	l.entries = append(l.entries, other.entries...)
+	return l
+}
+
+func fetchRegistry(client *http.Client, registry, name string) ([]byte, error) {
+	u := strings.TrimSuffix(registry, "/") + "/" + name
+	resp, err := client.Get(u)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s: HTTP %d", u, resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, rulePackMaxSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", u, err)
+	}
+	if len(data) > rulePackMaxSize {
+		return nil, fmt.Errorf("%s: larger than %d bytes", u, rulePackMaxSize)
+	}
+	return data, nil
+}
+
+// compareVersions orders two rule pack versions such as 2026.10.1 field by
+// field, numerically where both fields are numbers.
+func compareVersions(a, b string) int {
+	as, bs := strings.Split(strings.TrimPrefix(a, "v"), "."), strings.Split(strings.TrimPrefix(b, "v"), ".")
+	for i := 0; i < len(as) && i < len(bs); i++ {
+		an, aErr := strconv.Atoi(as[i])
+		bn, bErr := strconv.Atoi(bs[i])
+		switch {
+		case aErr == nil && bErr == nil && an != bn:
+			if an < bn {
+				return -1
+			}
+			return 1
+		case (aErr != nil || bErr != nil) && as[i] != bs[i]:
+			return strings.Compare(as[i], bs[i])
+		}
+	}
+	switch {
+	case len(as) < len(bs):
+		return -1
+	case len(as) > len(bs):
+		return 1
+	}
+	return 0
+}
+
+// writeFileAtomic replaces path with data through a temporary file, so a
+// scan never reads half a pack.
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+func runRules(args []string) {
+	initColors(false, "")
+	if len(args) == 0 || (args[0] != "update" && args[0] != "status") {
+		fmt.Fprintf(os.Stderr, "Usage: golinkfinder rules update [flags]\n       golinkfinder rules status\n")
+		os.Exit(2)
+	}
+	dir := rulePackDir()
+	if dir == "" {
+		fatal(errors.New("no user config directory for the rule pack"))
+	}
+	if args[0] == "status" {
+		rulesStatus(dir)
+		return
+	}
+
+	var registry, key, pin, configPath string
+	var latest bool
+	fs := flag.NewFlagSet("rules update", flag.ExitOnError)
+	fs.StringVar(&registry, "registry", os.Getenv("GOLINKFINDER_RULES_REGISTRY"), "Rule pack registry URL (default rule_pack.registry in the config file, or $GOLINKFINDER_RULES_REGISTRY).")
+	fs.StringVar(&key, "key", os.Getenv("GOLINKFINDER_RULES_KEY"), "Base64 ed25519 public key the pack must be signed with (default rule_pack.key, or $GOLINKFINDER_RULES_KEY).")
+	fs.StringVar(&pin, "pin", "", "Install this version and keep it on later updates.")
+	fs.BoolVar(&latest, "latest", false, "Drop the pin and install the latest version.")
+	fs.StringVar(&configPath, "config", "", "Config file (default $XDG_CONFIG_HOME/golinkfinder/config.yaml).")
+	fs.Parse(args[1:])
+
+	cfg, err := loadConfig(configPath)
+	if err != nil {
+		fatal(err)
+	}
+	if cfg.RulePack == nil {
+		cfg.RulePack = &rulePackConfig{}
+	}
+	if registry == "" {
+		registry = cfg.RulePack.Registry
+	}
+	if key == "" {
+		key = cfg.RulePack.Key
+	}
+	if registry == "" || key == "" {
+		fatal(errors.New("a registry and its signing key are needed: -registry and -key, or rule_pack in the config file"))
+	}
+	publicKey, err := base64.StdEncoding.DecodeString(key)
+	if err != nil || len(publicKey) != ed25519.PublicKeySize {
+		fatal(errors.New("-key must be a base64 ed25519 public key"))
+	}
+	lock, err := readRulePackLock(dir)
+	if err != nil {
+		fatal(err)
+	}
+
+	// The version: -pin, then the config file's pin, then the installed
+	// pin, then the registry's latest.
+	version, pinned := pin, pin != ""
+	if version == "" && !latest && cfg.RulePack.Version != "" {
+		version, pinned = cfg.RulePack.Version, true
+	}
+	if version == "" && !latest && lock != nil && lock.Pinned {
+		version, pinned = lock.Version, true
+	}
+	client := &http.Client{Timeout: 30 * time.Second}
+	if version == "" {
+		data, err := fetchRegistry(client, registry, "index.json")
+		if err != nil {
+			fatal(fmt.Errorf("could not read the registry index: %v", err))
+		}
+		var index struct {
+			Latest string `json:"latest"`
+		}
+		if err := json.Unmarshal(data, &index); err != nil || index.Latest == "" {
+			fatal(errors.New("the registry index has no latest version"))
+		}
+		version = index.Latest
+		// An index pointing back at an older pack would otherwise bring
+		// back rules and filters since fixed; only a pin goes back.
+		if lock != nil && compareVersions(version, lock.Version) < 0 {
+			fatal(fmt.Errorf("the registry's latest rule pack %s is older than the installed %s; use -pin %s to install it anyway", version, lock.Version, version))
+		}
+	}
+	if strings.ContainsAny(version, "/\\") || strings.Contains(version, "..") {
+		fatal(fmt.Errorf("invalid rule pack version '%s'", version))
+	}
+
+	data, err := fetchRegistry(client, registry, version+"/pack.json")
+	if err != nil {
+		fatal(fmt.Errorf("could not download rule pack %s: %v", version, err))
+	}
+	sig, err := fetchRegistry(client, registry, version+"/pack.json.sig")
+	if err != nil {
+		fatal(fmt.Errorf("could not download the signature of rule pack %s: %v", version, err))
+	}
+	pack, err := verifyRulePack(publicKey, data, sig, version)
+	if err != nil {
+		fatal(err)
+	}
+	rules, ignore, err := pack.compile()
+	if err != nil {
+		fatal(err)
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		fatal(fmt.Errorf("could not create rule pack directory: %v", err))
+	}
+	// The pack is stored under its hash and the lock, naming it, is written
+	// last: until then scans keep loading the previous pack, so an
+	// interrupted update leaves a working install.
+	sum := sha256.Sum256(data)
+	hash := hex.EncodeToString(sum[:])
+	file := "pack-" + hash + ".json"
+	newLock, _ := json.MarshalIndent(rulePackLock{Registry: registry, Version: version, Pinned: pinned, SHA256: hash, File: file, Updated: time.Now().UTC()}, "", "  ")
+	for _, f := range []struct {
+		name string
+		data []byte
+	}{{file, data}, {file + ".sig", sig}, {"lock.json", append(newLock, '\n')}} {
+		if err := writeFileAtomic(filepath.Join(dir, f.name), f.data); err != nil {
+			fatal(fmt.Errorf("could not save rule pack: %v", err))
+		}
+	}
+	if lock != nil && lock.file() != file {
+		os.Remove(filepath.Join(dir, lock.file()))
+		os.Remove(filepath.Join(dir, lock.file()+".sig"))
+	}
+
+	if lock != nil && lock.Version == version && lock.SHA256 == hash {
+		fmt.Fprintf(statusOut, "%s[*] Rule pack %s is already installed.%s\n", c.Yellow, version, c.End)
+		return
+	}
+	pinNote := ""
+	if pinned {
+		pinNote = " (pinned)"
+	}
+	fmt.Fprintf(statusOut, "%s[+] Installed rule pack %s%s: %d rules, %d noise filters.%s\n", c.Green, version, pinNote, len(rules), len(ignore.entries), c.End)
+}
+
+func rulesStatus(dir string) {
+	lock, err := readRulePackLock(dir)
+	if err != nil {
+		fatal(err)
+	}
+	if lock == nil {
+		fmt.Fprintf(statusOut, "%s[*] No rule pack installed; run 'golinkfinder rules update'.%s\n", c.Yellow, c.End)
+		return
+	}
+	pack, err := loadRulePack(dir)
+	if err != nil {
+		fatal(err)
+	}
+	rules, ignore, err := pack.compile()
+	if err != nil {
+		fatal(err)
+	}
+	pinNote := ""
+	if lock.Pinned {
+		pinNote = " (pinned)"
+	}
+	fmt.Fprintf(statusOut, "Rule pack:  %s%s\n", lock.Version, pinNote)
+	fmt.Fprintf(statusOut, "Registry:   %s\n", lock.Registry)
+	fmt.Fprintf(statusOut, "Updated:    %s\n", lock.Updated.Format(time.RFC3339))
+	fmt.Fprintf(statusOut, "SHA-256:    %s\n", lock.SHA256)
+	fmt.Fprintf(statusOut, "Contents:   %d rules, %d noise filters\n", len(rules), len(ignore.entries))
+}
@@ -0,0 +1,123 @@
+package golinkfinder
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func signedTestPack(t *testing.T, version string) (ed25519.PublicKey, ed25519.PrivateKey, []byte, []byte) {
+	t.Helper()
+	public, private, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := []byte(`{"version": "` + version + `", "rules": [], "ignore": ["endpoint /health"]}`)
+	sig := []byte(base64.StdEncoding.EncodeToString(ed25519.Sign(private, data)) + "\n")
+	return public, private, data, sig
+}
+
+func TestVerifyRulePack(t *testing.T) {
+	public, private, data, sig := signedTestPack(t, "2026.10.1")
+	otherPublic, _, _, _ := signedTestPack(t, "2026.10.1")
+
+	pack, err := verifyRulePack(public, data, sig, "2026.10.1")
+	if err != nil {
+		t.Fatalf("valid pack: %v", err)
+	}
+	if pack.Version != "2026.10.1" || len(pack.Ignore) != 1 {
+		t.Errorf("valid pack parsed as %+v", pack)
+	}
+
+	tampered := []byte(strings.Replace(string(data), "/health", "/admin", 1))
+	rawSig, _ := base64.StdEncoding.DecodeString(strings.TrimSpace(string(sig)))
+	flipped := append([]byte(nil), rawSig...)
+	flipped[0] ^= 1
+	otherVersion := []byte(`{"version": "2026.9.1", "rules": []}`)
+	otherVersionSig := []byte(base64.StdEncoding.EncodeToString(ed25519.Sign(private, otherVersion)))
+	tests := []struct {
+		name      string
+		key       ed25519.PublicKey
+		data, sig []byte
+		wantErr   string
+	}{
+		{"tampered pack", public, tampered, sig, "not signed"},
+		{"tampered signature", public, data, []byte(base64.StdEncoding.EncodeToString(flipped)), "not signed"},
+		{"wrong key", otherPublic, data, sig, "not signed"},
+		{"signature not base64", public, data, []byte("not base64!"), "not signed"},
+		{"empty signature", public, data, nil, "not signed"},
+		{"other version", public, otherVersion, otherVersionSig, "served rule pack 2026.9.1"},
+	}
+	for _, tt := range tests {
+		_, err := verifyRulePack(tt.key, tt.data, tt.sig, "2026.10.1")
+		if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("%s: got error %v, want one containing %q", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func writeTestLock(t *testing.T, dir string, lock rulePackLock) {
+	t.Helper()
+	data, _ := json.Marshal(lock)
+	if err := os.WriteFile(filepath.Join(dir, "lock.json"), data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadRulePack(t *testing.T) {
+	_, _, data, _ := signedTestPack(t, "2026.10.1")
+	sum := sha256.Sum256(data)
+	hash := hex.EncodeToString(sum[:])
+
+	dir := t.TempDir()
+	if pack, err := loadRulePack(dir); pack != nil || err != nil {
+		t.Fatalf("no lock: got %v, %v", pack, err)
+	}
+
+	file := "pack-" + hash + ".json"
+	os.WriteFile(filepath.Join(dir, file), data, 0o644)
+	writeTestLock(t, dir, rulePackLock{Version: "2026.10.1", SHA256: hash, File: file})
+	if pack, err := loadRulePack(dir); err != nil || pack.Version != "2026.10.1" {
+		t.Fatalf("installed pack: got %v, %v", pack, err)
+	}
+
+	os.WriteFile(filepath.Join(dir, file), append(data, ' '), 0o644)
+	if _, err := loadRulePack(dir); err == nil || !strings.Contains(err.Error(), "does not match its lock") {
+		t.Errorf("modified pack: got %v", err)
+	}
+
+	// Locks from before packs were stored by hash name pack.json.
+	legacy := t.TempDir()
+	os.WriteFile(filepath.Join(legacy, "pack.json"), data, 0o644)
+	writeTestLock(t, legacy, rulePackLock{Version: "2026.10.1", SHA256: hash})
+	if pack, err := loadRulePack(legacy); err != nil || pack.Version != "2026.10.1" {
+		t.Errorf("legacy install: got %v, %v", pack, err)
+	}
+}
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"2026.10.1", "2026.10.1", 0},
+		{"2026.9.1", "2026.10.1", -1},
+		{"2026.10.2", "2026.10.10", -1},
+		{"2027.1.0", "2026.12.31", 1},
+		{"v1.2.0", "1.2.0", 0},
+		{"1.2", "1.2.1", -1},
+		{"1.2.1", "1.2", 1},
+		{"1.2.0-rc1", "1.2.0-rc2", -1},
+	}
+	for _, tt := range tests {
+		if got := compareVersions(tt.a, tt.b); got != tt.want {
+			t.Errorf("compareVersions(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package golinkfinder
+
+import (
+	"fmt"
+	"regexp"
+	"regexp/syntax"
+	"sync"
+	"time"
+)
+
+// userRule is a custom extraction rule from the config file:
+//
+//	rules:
+//	  - name: internal-api
+//	    category: endpoint
+//	    pattern: '["''](/internal/[^"'']+)["'']'
+//	    group: 1
+type userRule struct {
+	Name     string `yaml:"name"`
+	Category string `yaml:"category"`
+	Pattern  string `yaml:"pattern"`
+	Group    int    `yaml:"group"`
+}
+
+const (
+	// maxRulePattern and maxRuleProgram bound the size of a custom pattern
+	// and of its compiled form, which is what matching time grows with.
+	maxRulePattern = 4096
+	maxRuleProgram = 20000
+	// maxRuleBody is the largest body custom rules are run on.
+	maxRuleBody = 16 << 20
+	// ruleStrikes is how many timeouts disable a rule for the rest of the run.
+	ruleStrikes = 3
+)
+
+func compileUserRules(rules []userRule) ([]extractionRule, error) {
+	compiled := make([]extractionRule, 0, len(rules))
+	for i, r := range rules {
+		if r.Name == "" {
+			r.Name = fmt.Sprintf("rule #%d", i+1)
+		}
+		if r.Pattern == "" {
+			return nil, fmt.Errorf("custom rule '%s' has no pattern", r.Name)
+		}
+		if len(r.Pattern) > maxRulePattern {
+			return nil, fmt.Errorf("custom rule '%s': pattern longer than %d bytes", r.Name, maxRulePattern)
+		}
+		re, err := regexp.Compile(r.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("custom rule '%s': %v", r.Name, err)
+		}
+		if size := regexpProgramSize(r.Pattern); size > maxRuleProgram {
+			return nil, fmt.Errorf("custom rule '%s': pattern too complex (%d instructions, limit %d)", r.Name, size, maxRuleProgram)
+		}
+		if r.Group > re.NumSubexp() {
+			return nil, fmt.Errorf("custom rule '%s': group %d but the pattern has %d", r.Name, r.Group, re.NumSubexp())
+		}
+		category := r.Category
+		if category == "" {
+			category = r.Name
+		}
+		compiled = append(compiled, extractionRule{category: category, re: re, group: r.Group, name: r.Name})
+	}
+	return compiled, nil
+}
+
+// regexpProgramSize is the number of instructions pattern compiles to.
+func regexpProgramSize(pattern string) int {
+	re, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil {
+		return 0
+	}
+	prog, err := syntax.Compile(re.Simplify())
+	if err != nil {
+		return 0
+	}
+	return len(prog.Inst)
+}
+
+// guardedRule runs one custom rule within a time budget. Go's regexp
+// can't backtrack, but a large pattern over a large body still takes long;
+// a match that overruns is abandoned (its goroutine finishes in the
+// background) and reported, and a rule that keeps overrunning is disabled.
+type guardedRule struct {
+	rule        extractionRule
+	budget      time.Duration
+	stringsOnly bool
+	out         *output
+
+	mu       sync.Mutex
+	strikes  int
+	disabled bool
+}
+
+type guardedRuleExtractor struct {
+	rules []*guardedRule
+}
+
+func newGuardedRuleExtractor(rules []extractionRule, budget time.Duration, stringsOnly bool, out *output) guardedRuleExtractor {
+	g := guardedRuleExtractor{}
+	for _, r := range rules {
+		g.rules = append(g.rules, &guardedRule{rule: r, budget: budget, stringsOnly: stringsOnly, out: out})
+	}
+	return g
+}
+
+func (g guardedRuleExtractor) Extract(source, contentType string, body []byte) []Finding {
+	findings := make([]Finding, 0)
+	for _, r := range g.rules {
+		findings = append(findings, r.match(source, contentType, body)...)
+	}
+	return findings
+}
+
+func (r *guardedRule) match(source, contentType string, body []byte) []Finding {
+	r.mu.Lock()
+	disabled := r.disabled
+	r.mu.Unlock()
+	if disabled {
+		return nil
+	}
+	if len(body) > maxRuleBody {
+		fmt.Fprintf(r.out.errs, "%s[!] Custom rule '%s' skipped %s: body larger than %s%s\n", r.out.c.Yellow, r.rule.name, source, formatBytes(maxRuleBody), r.out.c.End)
+		return nil
+	}
+	stringsOnly := r.stringsOnly && !isHTML(contentType, body)
+	if r.budget <= 0 {
+		return matchRules(source, body, []extractionRule{r.rule}, stringsOnly)
+	}
+
+	done := make(chan []Finding, 1)
+	go func() {
+		done <- matchRules(source, body, []extractionRule{r.rule}, stringsOnly)
+	}()
+	timer := time.NewTimer(r.budget)
+	defer timer.Stop()
+	select {
+	case findings := <-done:
+		return findings
+	case <-timer.C:
+	}
+
+	r.mu.Lock()
+	r.strikes++
+	strikes := r.strikes
+	r.disabled = strikes >= ruleStrikes
+	r.mu.Unlock()
+	fmt.Fprintf(r.out.errs, "%s[!] Custom rule '%s' exceeded %s on %s (%s); its matches there are dropped%s\n", r.out.c.Yellow, r.rule.name, r.budget, source, formatBytes(int64(len(body))), r.out.c.End)
+	if strikes == ruleStrikes {
+		fmt.Fprintf(r.out.errs, "%s[!] Custom rule '%s' disabled after %d timeouts%s\n", r.out.c.Red, r.rule.name, strikes, r.out.c.End)
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package golinkfinder
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// stateChangingRegex matches paths that look like they do something when
+// requested: logging out, deleting, resetting, unsubscribing, or admin
+// actions. -safe neither crawls nor probes them.
+var stateChangingRegex = regexp.MustCompile(`(?i)(?:^|[/_.-])(?:log-?out|sign-?out|logoff|delete|remove|destroy|purge|drop|truncate|wipe|reset|revoke|disable|deactivate|suspend|ban|unsubscribe|cancel|terminate|shutdown|restart|reboot|kill|flush|approve|reject|transfer|withdraw|pay|checkout|impersonate|sudo)(?:$|[/_.?;-])`)
+
+// looksStateChanging reports whether requesting u could change state.
+func looksStateChanging(u string) bool {
+	parsed, err := url.Parse(u)
+	if err != nil {
+		return true
+	}
+	return stateChangingRegex.MatchString(parsed.EscapedPath()) || stateChangingRegex.MatchString(parsed.RawQuery)
+}
+
+// safeTransport refuses every request that isn't a GET or HEAD, so -safe
+// holds whatever code path sends it.
+type safeTransport struct {
+	next http.RoundTripper
+}
+
+func (t *safeTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		return nil, fmt.Errorf("-safe: refusing %s request", req.Method)
+	}
+	return t.next.RoundTrip(req)
+}
+
+// checkSafe rejects the options -safe can't honour.
+func checkSafe(o *scanOptions) error {
+	if o.verifySecrets {
+		return fmt.Errorf("-safe can't be combined with -verify-secrets, which calls third-party APIs")
+	}
+	if o.login != nil && o.login.Method != http.MethodGet {
+		return fmt.Errorf("-safe can't run a %s login step", o.login.Method)
+	}
+	for _, m := range o.probeVerbs {
+		if m != http.MethodGet && m != http.MethodHead {
+			return fmt.Errorf("-safe only allows GET and HEAD in -probe-methods")
+		}
+	}
+	return nil
+}
+
+// checkAPISafe rejects -safe without -scope in serve and stdio: targets
+// arrive with each request there, so there are no input hosts to keep the
+// scan on.
+func checkAPISafe(o *scanOptions) error {
+	if o.safe && o.hostScope == nil {
+		return fmt.Errorf("-safe needs -scope in serve and stdio")
+	}
+	return nil
+}
+
+// safeJobs drops the targets -safe won't request: other methods than GET
+// and HEAD, and state-changing-looking URLs.
+func safeJobs(jobs []scanJob) ([]scanJob, int) {
+	kept := make([]scanJob, 0, len(jobs))
+	for _, job := range jobs {
+		method := strings.ToUpper(job.method)
+		if method != "" && method != http.MethodGet && method != http.MethodHead {
+			continue
+		}
+		if strings.HasPrefix(job.url, "http") && looksStateChanging(job.url) {
+			continue
+		}
+		kept = append(kept, job)
+	}
+	return kept, len(jobs) - len(kept)
+}
+
+// inputScope is the -safe scope without a -scope file: requests stay on
+// exactly the hosts of the input URLs.
+func inputScope(jobs []scanJob) *hostScope {
+	scope := &hostScope{requestsOnly: true}
+	seen := make(map[string]bool)
+	for _, job := range jobs {
+		if u, err := url.Parse(job.url); err == nil && u.Host != "" && !seen[u.Hostname()] {
+			seen[u.Hostname()] = true
+			scope.include = append(scope.include, strings.ToLower(u.Hostname()))
+		}
+	}
+	return scope
+}
@@ -0,0 +1,46 @@
+package golinkfinder
+
+import "net/url"
+
+// hostSampler keeps the terminal readable on huge scans with
+// -sample-per-host: it lets through at most limit findings per host, one
+// per endpoint template, so /users/1 ... /users/900 take a single line.
+// Only printing is sampled; -o, -jsonl, -format and -db get everything.
+type hostSampler struct {
+	limit int
+	shown map[string]map[string]struct{}
+	// hidden counts, per host, the findings not printed.
+	hidden map[string]int
+}
+
+func newHostSampler(limit int) *hostSampler {
+	if limit <= 0 {
+		return nil
+	}
+	return &hostSampler{limit: limit, shown: make(map[string]map[string]struct{}), hidden: make(map[string]int)}
+}
+
+// keep reports whether f is printed.
+func (hs *hostSampler) keep(f Finding) bool {
+	if hs == nil {
+		return true
+	}
+	host := f.Source
+	if u, err := url.Parse(f.Value); err == nil && u.Host != "" {
+		host = u.Host
+	} else if u, err := url.Parse(f.Source); err == nil && u.Host != "" {
+		host = u.Host
+	}
+	templates := hs.shown[host]
+	if templates == nil {
+		templates = make(map[string]struct{})
+		hs.shown[host] = templates
+	}
+	template := f.Category + " " + templatePath(f.Value)
+	if _, ok := templates[template]; ok || len(templates) >= hs.limit {
+		hs.hidden[host]++
+		return false
+	}
+	templates[template] = struct{}{}
+	return true
+}
@@ -0,0 +1,181 @@
+package golinkfinder
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// SARIF 2.1.0, limited to what code-scanning uploads need.
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool              sarifTool `json:"tool"`
+	AutomationDetails struct {
+		ID string `json:"id"`
+	} `json:"automationDetails"`
+	Results    []sarifResult `json:"results"`
+	Properties struct {
+		Started string            `json:"started"`
+		Config  map[string]string `json:"config"`
+		Labels  map[string]string `json:"labels,omitempty"`
+	} `json:"properties"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	Version        string      `json:"version"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifText struct {
+	Text string `json:"text"`
+}
+
+type sarifRule struct {
+	ID                   string    `json:"id"`
+	ShortDescription     sarifText `json:"shortDescription"`
+	DefaultConfiguration struct {
+		Level string `json:"level"`
+	} `json:"defaultConfiguration"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	Level     string          `json:"level"`
+	Message   sarifText       `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation struct {
+		ArtifactLocation struct {
+			URI string `json:"uri"`
+		} `json:"artifactLocation"`
+		Region *sarifRegion `json:"region,omitempty"`
+	} `json:"physicalLocation"`
+}
+
+type sarifRegion struct {
+	StartLine  int `json:"startLine"`
+	ByteOffset int `json:"byteOffset"`
+}
+
+// sarifReport collects every finding of a run for -o-sarif.
+type sarifReport struct {
+	rules   map[string]sarifRule
+	results []sarifResult
+}
+
+func newSarifReport() *sarifReport {
+	return &sarifReport{rules: make(map[string]sarifRule)}
+}
+
+// sarifClassify picks the rule and level for a finding: secrets are errors,
+// internal hosts and high-interest endpoints warnings, the rest notes.
+func sarifClassify(f Finding) (id, level, description string) {
+	switch {
+	case f.Category == categorySecret:
+		kind := "unknown"
+		if k := secretKindOf(f.Value); k != nil {
+			kind = k.name
+		}
+		return "secret/" + kind, "error", "Hardcoded " + kind
+	case f.Category == categoryJWT:
+		return categoryJWT, "warning", "Hardcoded JWT"
+	case f.Category == categoryInternal:
+		return categoryInternal, "warning", "Internal hostname exposed in client code"
+	case isInteresting(f.Value):
+		return f.Category + "/interesting", "warning", "High-interest " + f.Category + " exposed in client code"
+	}
+	return f.Category, "note", "Discovered " + f.Category
+}
+
+// sarifTokenRegex matches the keys and tokens quoted in a finding's note.
+var sarifTokenRegex = regexp.MustCompile(`[A-Za-z0-9_\-.]{20,}`)
+
+// maskSecret keeps enough of a secret to identify it without publishing
+// it to wherever the SARIF file is uploaded.
+func maskSecret(value string) string {
+	if len(value) <= 8 {
+		return strings.Repeat("*", len(value))
+	}
+	return value[:6] + strings.Repeat("*", len(value)-6)
+}
+
+func (r *sarifReport) add(f Finding) {
+	id, level, description := sarifClassify(f)
+	value, note := f.Value, f.Note
+	switch f.Category {
+	case categorySecret, categoryJWT:
+		value = maskSecret(value)
+	case categoryBackend:
+		// Mapbox secret tokens are the value; Supabase and Algolia keys
+		// ride in the note.
+		value = maskSecret(value)
+		note = sarifTokenRegex.ReplaceAllStringFunc(note, maskSecret)
+	}
+	message := fmt.Sprintf("%s: %s", description, value)
+	if note != "" {
+		message += " (" + note + ")"
+	}
+
+	var loc sarifLocation
+	loc.PhysicalLocation.ArtifactLocation.URI = f.Source
+	if f.Line > 0 {
+		loc.PhysicalLocation.Region = &sarifRegion{StartLine: f.Line, ByteOffset: f.Offset}
+	}
+
+	if _, ok := r.rules[id]; !ok {
+		rule := sarifRule{ID: id, ShortDescription: sarifText{description}}
+		rule.DefaultConfiguration.Level = level
+		r.rules[id] = rule
+	}
+	r.results = append(r.results, sarifResult{RuleID: id, Level: level, Message: sarifText{message}, Locations: []sarifLocation{loc}})
+}
+
+// write saves the report, identifying the run by st's scan ID.
+func (r *sarifReport) write(path string, st *scanStats) error {
+	rules := make([]sarifRule, 0, len(r.rules))
+	for _, rule := range r.rules {
+		rules = append(rules, rule)
+	}
+	sort.Slice(rules, func(i, j int) bool { return rules[i].ID < rules[j].ID })
+	results := r.results
+	if results == nil {
+		results = []sarifResult{}
+	}
+
+	run := sarifRun{
+		Tool:    sarifTool{Driver: sarifDriver{Name: "golinkfinder", Version: st.Version, InformationURI: "https://github.com/nullqore/golinkfinder", Rules: rules}},
+		Results: results,
+	}
+	// automationDetails.id is SARIF's "category/run" identifier.
+	run.AutomationDetails.ID = "golinkfinder/" + st.ScanID
+	run.Properties.Started = st.Started.UTC().Format(time.RFC3339)
+	run.Properties.Config = st.Config
+	run.Properties.Labels = st.Labels
+	log := sarifLog{
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Version: "2.1.0",
+		Runs:    []sarifRun{run},
+	}
+	data, err := json.MarshalIndent(log, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
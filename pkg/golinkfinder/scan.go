@@ -0,0 +1,1799 @@
+package golinkfinder
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+)
+
+const userAgent = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/115.0.0.0 Safari/537.36"
+
+type scanOptions struct {
+	targetURLs stringList
+	urlLists   stringList
+	outputFile string
+	threads    int
+	resolve    bool
+	quiet      bool
+	noColor    bool
+	theme      string
+	dbPath     string
+	probe      bool
+	extract    string
+	adaptive   bool
+	plugins    stringList
+
+	onlyInteresting bool
+	followSpecs     bool
+	followSitemaps  bool
+	probeCluster    int
+	profile         string
+	configPath      string
+	rate            float64
+	retries         int
+	stdinBody       bool
+	base            string
+	spillDir        string
+	provenance      bool
+	tlsImpersonate  string
+	ua              string
+	uaRotate        string
+	randomHeaders   bool
+	noChunks        bool
+	stringsOnly     bool
+	noModules       bool
+	noBasePaths     bool
+	minConfidence   string
+	moduleDepth     int
+	ignoreLibs      bool
+	ext             string
+	excludeExt      string
+	contentTypes    string
+	authFile        string
+	dryRun          bool
+	verifySecrets   bool
+	format          string
+	hostHeader      string
+	unixSocket      string
+	ipv4            bool
+	ipv6            bool
+	noHappyEyeballs bool
+	upstream        string
+	delay           time.Duration
+	jitter          time.Duration
+	summaryFile     string
+	checksums       bool
+	sarifFile       string
+	nucleiDir       string
+	outputDir       string
+	auth            string
+	netrcFile       string
+	machine         bool
+	jsonl           bool
+	gitRepo         string
+	gitHistory      bool
+	http3           bool
+	template        bool
+	burpFile        string
+	archive         string
+	crawl           bool
+	depth           int
+	ruleTimeout     time.Duration
+	pluginTimeout   time.Duration
+	customRules     []extractionRule
+	fetchTimeout    time.Duration
+	scanTimeout     time.Duration
+	probeTimeout    time.Duration
+	probeMethods    string
+	probeVerbs      []string
+	probeBodies     stringList
+	targetsDir      string
+	probeFilter     *probeBodyFilter
+	scopeFile       string
+	hostScope       *hostScope
+	maxSize         int64
+	retryFile       string
+	safe            bool
+	dedupKey        string
+	maxBandwidth    string
+	maxTotalBytes   string
+	budget          *byteBudget
+	login           *loginConfig
+	awsSigV4        string
+	precheck        bool
+	resolveHosts    bool
+	asnDB           string
+	asnAPI          string
+	ignoreFile      string
+	noIgnore        bool
+	noRulePack      bool
+	ignore          *ignoreList
+	signHeaders     stringList
+	signer          *requestSigner
+	varPairs        stringList
+	vars            map[string]string
+	sinkNames       stringList
+	sinks           *sinkMatcher
+	maxPerSource    int
+	contextChars    int
+	stripQuery      bool
+	maxFindings     int
+	samplePerHost   int
+	debugHTTP       bool
+	labelPairs      stringList
+	labels          map[string]string
+	dnsTTL          time.Duration
+	dnsConcurrency  int
+	// metrics is set by serve, and by monitor -metrics.
+	metrics  bool
+	sortBy   string
+	groupBy  string
+	minCount int
+	counts   bool
+
+	// config is the effective value of every flag, recorded with each run.
+	config map[string]string
+}
+
+func addScanFlags(fs *flag.FlagSet, o *scanOptions) {
+	fs.Var(&o.targetURLs, "u", "URL to scan (repeatable; combines with -l and stdin).")
+	fs.Var(&o.urlLists, "l", "File containing a list of URLs to scan (plain, JSONL or CSV with per-target method/headers/cookies); - reads stdin alongside -u (repeatable).")
+	fs.StringVar(&o.burpFile, "burp", o.burpFile, "Extract from the responses recorded in a Burp Suite \"Save items\" XML export instead of fetching.")
+	fs.StringVar(&o.archive, "archive", o.archive, "Scan the text files inside a .zip, .tar, .tar.gz or .tgz archive (e.g. a CI dist.zip) instead of URLs; sources are archive!/path.")
+	fs.StringVar(&o.gitRepo, "git", o.gitRepo, "Scan the JS/TS/HTML/JSON files of a git repository (URL to clone, or local path) instead of URLs.")
+	fs.BoolVar(&o.gitHistory, "git-history", o.gitHistory, "With -git, also scan the lines every commit removed from those files.")
+	fs.StringVar(&o.outputFile, "o", o.outputFile, "File to save the final output of unique endpoints. A .ndjson file is appended to as each finding is discovered, one JSON record per line.")
+	fs.IntVar(&o.threads, "t", o.threads, "Number of concurrent threads to use.")
+	fs.BoolVar(&o.resolve, "r", o.resolve, "Resolve found paths to full URLs.")
+	fs.BoolVar(&o.template, "template", o.template, "List endpoints with numeric, UUID and hash segments collapsed (/users/{id}); raw forms stay in console, -format and -db output.")
+	fs.BoolVar(&o.quiet, "q", o.quiet, "Silent mode. Only output the final list of unique endpoints.")
+	fs.BoolVar(&o.noColor, "no-color", o.noColor, "Disable colorized output.")
+	fs.StringVar(&o.theme, "theme", o.theme, "Color theme: dark, or light for light terminals (default $GOLINKFINDER_THEME, else dark).")
+	fs.StringVar(&o.dbPath, "db", o.dbPath, "SQLite database to persist results into across runs (query with 'golinkfinder query').")
+	fs.BoolVar(&o.adaptive, "adaptive", o.adaptive, "Adapt per-host concurrency: start slow, ramp up to -t while the host copes, halve on errors/429s.")
+	fs.BoolVar(&o.onlyInteresting, "only-interesting", o.onlyInteresting, "Only report endpoints matching high-interest keywords (admin, debug, backup, .env, ...).")
+	fs.BoolVar(&o.crawl, "crawl", o.crawl, "Follow discovered same-origin page, script and JSON links up to -depth, extracting from everything fetched.")
+	fs.IntVar(&o.depth, "depth", o.depth, "Maximum link depth from the seed URLs for -crawl.")
+	fs.BoolVar(&o.safe, "safe", o.safe, "Production-safe mode: only GET/HEAD requests, no crawling, probing or redirects to logout/delete/reset-looking URLs, and scope enforced (the input hosts without -scope).")
+	fs.StringVar(&o.scopeFile, "scope", o.scopeFile, "Scope file of hostname patterns (*.example.com, !*.cdn.example.com): out-of-scope targets, followed links, redirects and URL findings are dropped.")
+	fs.BoolVar(&o.stripQuery, "strip-query", o.stripQuery, "Drop the query string of input URLs, so cache-busted copies (app.js?v=1, app.js?v=2) are fetched once.")
+	fs.IntVar(&o.contextChars, "context", o.contextChars, "Include this many characters of code on each side of every finding in -jsonl, -format ({{.Context}}) and the API (0 = none).")
+	fs.IntVar(&o.maxPerSource, "max-findings-per-source", o.maxPerSource, "Keep at most this many findings from one source and mark it truncated (0 = no limit).")
+	fs.IntVar(&o.samplePerHost, "sample-per-host", o.samplePerHost, "Print at most this many findings per host, one per endpoint template; -o, -jsonl, -format and -db still get all of them (0 = print all).")
+	fs.IntVar(&o.maxFindings, "max-findings", o.maxFindings, "Stop the scan once this many unique values were found (0 = no limit).")
+	fs.BoolVar(&o.followSpecs, "follow-specs", o.followSpecs, "Fetch discovered swagger/openapi/api-docs URLs and parse them as API specifications.")
+	fs.BoolVar(&o.followSitemaps, "follow-sitemaps", o.followSitemaps, "Fetch the sitemaps listed in sitemap indexes; the pages sitemaps and feeds list are fetched with -crawl.")
+	fs.BoolVar(&o.noChunks, "no-chunks", o.noChunks, "Don't fetch the lazily-loaded chunks reconstructed from webpack runtimes.")
+	fs.StringVar(&o.ext, "ext", o.ext, "Only fetch input URLs with these comma-separated extensions, e.g. js,mjs,json (URLs without one are dropped).")
+	fs.StringVar(&o.excludeExt, "exclude-ext", o.excludeExt, "Never fetch input URLs with these comma-separated extensions, e.g. png,css,woff2.")
+	fs.StringVar(&o.contentTypes, "content-type", o.contentTypes, "Only download bodies whose Content-Type contains one of these comma-separated values, e.g. javascript,json,html; others are skipped after the headers.")
+	fs.BoolVar(&o.ignoreLibs, "ignore-libs", o.ignoreLibs, "Suppress findings from known third-party libraries (jQuery, React, analytics SDKs, polyfills...) and their internal paths when bundled.")
+	fs.StringVar(&o.minConfidence, "min-confidence", o.minConfidence, "Drop findings below this confidence: low, medium or high (each finding's confidence is in -jsonl and {{.Confidence}}).")
+	fs.BoolVar(&o.noBasePaths, "no-base-paths", o.noBasePaths, "With -r, resolve relative endpoints against their source even when it configures an API base (baseURL: \"https://api.x.com/v2\").")
+	fs.BoolVar(&o.noModules, "no-modules", o.noModules, "Don't fetch the ES modules scripts import (import ... from \"./x.js\", import(\"./y.js\")).")
+	fs.IntVar(&o.moduleDepth, "module-depth", o.moduleDepth, "Maximum chain of ES module imports followed from a fetched script.")
+	fs.DurationVar(&o.ruleTimeout, "rule-timeout", o.ruleTimeout, "Time budget of each custom config-file rule per source; slower matches are dropped and reported, and a rule overrunning 3 times is disabled (0 = no limit).")
+	fs.BoolVar(&o.stringsOnly, "strings-only", o.stringsOnly, "In scripts, only report matches inside string and template literals, ignoring comments, regex literals and code.")
+	fs.BoolVar(&o.dryRun, "dry-run", o.dryRun, "Print the final target list and exit without sending any request.")
+	fs.StringVar(&o.dedupKey, "dedup-key", o.dedupKey, "What makes two findings the same: value (as printed), raw (the match), url (resolved), path (resolved path only) or host-path; url and host-path imply -r.")
+	fs.StringVar(&o.profile, "profile", o.profile, "Apply a preset: stealth, fast, thorough, or one defined in the config file. Explicit flags win.")
+	fs.StringVar(&o.configPath, "config", o.configPath, "Config file (default $XDG_CONFIG_HOME/golinkfinder/config.yaml).")
+	fs.DurationVar(&o.fetchTimeout, "timeout", o.fetchTimeout, "Give up on a single request (connect, headers and body) after this long.")
+	fs.DurationVar(&o.scanTimeout, "scan-timeout", o.scanTimeout, "Stop fetching after this long (e.g. 10m) and report what was found; in serve and stdio, the limit of one request (0 = none).")
+	fs.DurationVar(&o.probeTimeout, "probe-timeout", o.probeTimeout, "Stop probing after this long (0 = none).")
+	fs.StringVar(&o.probeMethods, "probe-methods", o.probeMethods, "Comma-separated methods to probe every endpoint with: GET, HEAD, OPTIONS (OPTIONS answers report their Allow header).")
+	fs.Var(&o.probeBodies, "probe-filter-body", "Hide probe answers whose body matches this saved \"not found\" page, for apps answering unknown routes with a custom 200 (repeatable).")
+	fs.StringVar(&o.maxBandwidth, "max-bandwidth", o.maxBandwidth, "Cap the download rate of all workers together, e.g. 10MB/s or 500KB/s.")
+	fs.StringVar(&o.maxTotalBytes, "max-total-bytes", o.maxTotalBytes, "Stop the scan once this much was downloaded, e.g. 2GB.")
+	fs.Float64Var(&o.rate, "rate", o.rate, "Maximum requests per second across all threads (0 = unlimited).")
+	fs.DurationVar(&o.delay, "delay", o.delay, "Pause each worker this long between its requests (e.g. 500ms), on top of -rate.")
+	fs.DurationVar(&o.jitter, "jitter", o.jitter, "Add a random extra pause of up to this long to every -delay.")
+	fs.IntVar(&o.retries, "retries", o.retries, "Retries for requests failing with timeouts, connection errors, 429 or 5xx.")
+	fs.BoolVar(&o.stdinBody, "stdin-body", o.stdinBody, "Treat stdin as raw JS/HTML content to extract from instead of a URL list (auto-detected when stdin isn't URLs).")
+	fs.StringVar(&o.base, "base", o.base, "With stdin content, the URL it came from: used as the source and for -r resolution.")
+	fs.StringVar(&o.spillDir, "spill-dir", o.spillDir, "Keep the unique-endpoint set in a temporary SQLite file in this directory instead of memory.")
+	fs.StringVar(&o.sortBy, "sort", o.sortBy, "Order the final endpoint list: alpha, by-host, or by-count (most sources first).")
+	fs.StringVar(&o.groupBy, "group-by", o.groupBy, "Split the final endpoint list into host, source or category sections (\"# name (count)\" headers, or JSON groups with -o file.json).")
+	fs.IntVar(&o.minCount, "min-count", o.minCount, "Only list values referenced by at least this many distinct sources (final list and -o).")
+	fs.BoolVar(&o.counts, "counts", o.counts, "Prefix each value of the final list with the number of distinct sources referencing it (count<TAB>value).")
+	fs.BoolVar(&o.provenance, "provenance", o.provenance, "Keep every source (with line and byte offset) of each endpoint; list and file output become value<TAB>source<TAB>line<TAB>offset rows.")
+	fs.StringVar(&o.hostHeader, "host-header", o.hostHeader, "Send this Host header (and TLS server name) while connecting to the address in the URL, for vhosts not in DNS.")
+	fs.BoolVar(&o.ipv4, "4", o.ipv4, "Only connect over IPv4.")
+	fs.BoolVar(&o.ipv6, "6", o.ipv6, "Only connect over IPv6.")
+	fs.BoolVar(&o.noHappyEyeballs, "no-happy-eyeballs", o.noHappyEyeballs, "Try a dual-stack host's addresses one after the other instead of racing IPv6 and IPv4 connections.")
+	fs.StringVar(&o.unixSocket, "unix", o.unixSocket, "Connect to this Unix domain socket for every request; URLs still set the path and Host.")
+	fs.Var((*secretURL)(&o.upstream), "upstream", "Open every connection through host:port, tcp://host:port or socks5://[user:pass@]host:port.")
+	fs.BoolVar(&o.http3, "http3", o.http3, "Try HTTPS targets over HTTP/3 (QUIC) first, falling back to HTTP/2 or HTTP/1.1 per host.")
+	fs.DurationVar(&o.dnsTTL, "dns-ttl", o.dnsTTL, "Cache the addresses of every host connected to for this long (0 re-resolves for each connection; concurrent lookups of a host are still shared).")
+	fs.IntVar(&o.dnsConcurrency, "dns-concurrency", o.dnsConcurrency, "Maximum DNS lookups in flight at once, so host-diverse lists don't overload the resolver (0 = no limit).")
+	fs.BoolVar(&o.debugHTTP, "debug-http", o.debugHTTP, "Log each request's connection reuse, DNS, connect, TLS and first-byte times and protocol to stderr, with totals and the slowest hosts at the end.")
+	fs.StringVar(&o.ua, "ua", o.ua, "User-Agent sent with every request (default a desktop Chrome).")
+	fs.StringVar(&o.uaRotate, "ua-rotate", o.uaRotate, "File of User-Agents, one per line; each request uses a random one.")
+	fs.BoolVar(&o.randomHeaders, "random-headers", o.randomHeaders, "Send a random Accept-Language and a plausible Referer (the site itself or a search engine) with each request.")
+	fs.StringVar(&o.tlsImpersonate, "tls-impersonate", o.tlsImpersonate, "Mimic a browser TLS fingerprint: chrome, firefox, safari, edge, ios, or random.")
+	fs.StringVar(&o.ignoreFile, "ignore-file", o.ignoreFile, "File of triaged findings to leave out (default ./"+defaultIgnoreFile+" when present).")
+	fs.BoolVar(&o.noIgnore, "no-ignore", o.noIgnore, "Don't read the ignore file.")
+	fs.BoolVar(&o.noRulePack, "no-rule-pack", o.noRulePack, "Don't use the rules and noise filters of the rule pack installed by 'golinkfinder rules update'.")
+	fs.BoolVar(&o.resolveHosts, "resolve-hosts", o.resolveHosts, "Resolve the host of every absolute URL and internal hostname found, flagging private addresses (in -jsonl, -o-dir hosts.json and the summary).")
+	fs.StringVar(&o.asnDB, "asn-db", o.asnDB, "With -resolve-hosts, look up the AS and organization of each address in this ip2asn TSV file (iptoasn.com).")
+	fs.Var((*secretURL)(&o.asnAPI), "asn-api", "With -resolve-hosts, look up the AS of each address at this URL, with {ip} replaced, answering JSON with asn and org fields.")
+	fs.BoolVar(&o.precheck, "precheck", o.precheck, "HEAD every target first and skip dead hosts, 404/410s, permanent redirects and non-text content.")
+	fs.StringVar(&o.awsSigV4, "aws-sigv4", o.awsSigV4, "Sign the requests to the target hosts with AWS SigV4 as service:region (e.g. s3:eu-west-1), using AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY and AWS_SESSION_TOKEN.")
+	fs.Var((*secretList)(&o.signHeaders), "sign-header", "Add a header computed per request to the target hosts, as 'Name: template' with hmacSHA256, sha256, env, .Method, .Path, .Timestamp... (repeatable).")
+	fs.Var(&o.labelPairs, "label", "Stamp key=value onto every finding in JSON output (-jsonl, .ndjson, the API), the summary, SARIF and the -db run, e.g. engagement=acme (repeatable).")
+	fs.Var(&o.sinkNames, "sink", "Also record endpoints passed to this HTTP client call, e.g. api.request or 'client.' for any of its methods (repeatable; fetch, axios, $.ajax, xhr.open... are built in).")
+	fs.Var((*secretList)(&o.varPairs), "var", "Substitute NAME=value into endpoints built from environment variables (${API_URL}/users, process.env.API + \"/login\") (repeatable).")
+	fs.Var(&o.plugins, "plugin", "External extractor command speaking JSON lines over stdin/stdout (repeatable).")
+	fs.DurationVar(&o.pluginTimeout, "plugin-timeout", o.pluginTimeout, "Time a -plugin gets to answer for one source; a plugin overrunning it is killed and disabled for the rest of the run (0 = no limit).")
+	fs.StringVar(&o.sarifFile, "o-sarif", o.sarifFile, "Also write every finding as SARIF 2.1.0 for code-scanning upload (secrets are masked).")
+	fs.BoolVar(&o.machine, "machine", o.machine, "Strict pipeline mode: stdout carries only findings, streamed one per line (or -format/-jsonl records); every banner, progress and error line goes to stderr.")
+	fs.BoolVar(&o.jsonl, "jsonl", o.jsonl, "Print findings (and probe results) as JSON lines.")
+	fs.Var((*secretString)(&o.auth), "auth", "Basic auth user:pass sent to the target hosts (default $GLF_AUTH). Per-host credentials come from GLF_AUTH_HOST_<HOST> and .netrc.")
+	fs.StringVar(&o.netrcFile, "netrc", o.netrcFile, "netrc file with per-host credentials (default $NETRC or ~/.netrc).")
+	fs.StringVar(&o.outputDir, "o-dir", o.outputDir, "Write one file per category into this directory: endpoints.txt, emails.txt, internal-hosts.txt, ... (secrets, jwts and backends as .json), plus hosts.txt.")
+	fs.StringVar(&o.nucleiDir, "o-nuclei", o.nucleiDir, "Write per-host target lists (<host>.txt) and nuclei template stubs (<host>.yaml) of the resolved endpoints into this directory (implies -r).")
+	fs.Int64Var(&o.maxSize, "max-size", o.maxSize, "Don't download response bodies larger than this many MiB (0 = no limit).")
+	fs.StringVar(&o.retryFile, "o-retry", o.retryFile, "Save the targets that failed with a retryable error (timeout, connection, 5xx, 429) to this file.")
+	fs.StringVar(&o.authFile, "o-auth", o.authFile, "Save the targets that answered with a login page, IdP redirect or 401 to this file, to re-scan with credentials.")
+	fs.StringVar(&o.summaryFile, "summary", o.summaryFile, "Write the run's summary statistics (targets, failures, categories, hosts, bytes, duration) to this file: JSON, or an HTML page when it ends in .html.")
+	fs.BoolVar(&o.checksums, "checksums", o.checksums, "Write a <file>.sha256.json sidecar with the scan ID, date, target count and SHA-256 next to each saved file (checksums.json in -o-dir).")
+	fs.StringVar(&o.format, "format", o.format, "Print each finding with this Go template instead, e.g. '{{.Source}} {{.Endpoint}} {{.Status}}' (fields: Source, Endpoint, Template, Category, Method, Line, Offset, Note, SourceStatus, SourceType, SourceBytes, SourceMillis; Status, Length, Allow, Error, ErrorClass with -probe).")
+	fs.StringVar(&o.extract, "extract", o.extract, "Also extract these comma-separated categories: email, ip, host (internal hostnames), secrets, jwt (decoded tokens and the URLs in their claims), backends (Firebase, Supabase, Algolia and Mapbox configs), or all.")
+	fs.BoolVar(&o.verifySecrets, "verify-secrets", o.verifySecrets, "Check detected secrets against the provider's read-only endpoints and mark them active/inactive (implies -extract secrets).")
+}
+
+func defaultScanOptions() scanOptions {
+	return scanOptions{threads: 20, depth: 2, moduleDepth: 5, probeCluster: 5, probeMethods: "GET", fetchTimeout: 10 * time.Second, ruleTimeout: 2 * time.Second, pluginTimeout: 30 * time.Second, dnsTTL: 5 * time.Minute, dnsConcurrency: 16}
+}
+
+// scanJob is one unit of work: a URL to fetch, or content obtained
+// elsewhere (body set) that only needs extracting and is attributed to url.
+type scanJob struct {
+	url     string
+	method  string
+	host    string
+	headers http.Header
+	// depth counts the -crawl links followed from a seed URL to this job,
+	// imports the ES module imports.
+	depth       int
+	imports     int
+	body        []byte
+	contentType string
+	// input marks the targets given on the command line (-u, -l, stdin),
+	// the only ones that may be file:// URLs.
+	input bool
+}
+
+func urlJobs(urls []string) []scanJob {
+	jobs := make([]scanJob, 0, len(urls))
+	for _, u := range urls {
+		jobs = append(jobs, scanJob{url: u})
+	}
+	return jobs
+}
+
+type linkFinderResult struct {
+	job       scanJob
+	sourceURL string
+	findings  []Finding
+	meta      sourceMeta
+	err       error
+}
+
+// fetchAndFindLinks returns the findings in job's response and how it was
+// fetched: status, content type, body size and time to download it.
+func fetchAndFindLinks(ctx context.Context, client *http.Client, job scanJob, allowedTypes []string, maxSize int64, extract func(source, contentType string, body []byte) []Finding) ([]Finding, sourceMeta, error) {
+	meta := sourceMeta{URL: job.url}
+	targetURL := job.url
+	method := job.method
+	if method == "" {
+		method = "GET"
+	}
+	req, err := http.NewRequestWithContext(ctx, method, targetURL, nil)
+	if err != nil {
+		return nil, meta, fmt.Errorf("could not create request: %v", err)
+	}
+	for name, values := range job.headers {
+		req.Header[name] = values
+	}
+	if job.host != "" {
+		req.Host = job.host
+	}
+
+	start := time.Now()
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, meta, fmt.Errorf("http request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	meta.Status = resp.StatusCode
+	meta.ContentType = resp.Header.Get("Content-Type")
+
+	if reason := authChallenge(resp); reason != "" {
+		meta.Duration = time.Since(start)
+		return nil, meta, &authRequiredError{reason: reason}
+	}
+	if resp.StatusCode != http.StatusOK {
+		meta.Duration = time.Since(start)
+		return nil, meta, &statusError{code: resp.StatusCode, challenge: blockingChallenge(resp)}
+	}
+	// Returning before reading drops the connection instead of
+	// downloading a body that would be thrown away.
+	if reason := loginWall(targetURL, resp.Request.URL, meta.ContentType, nil); reason != "" {
+		meta.Duration = time.Since(start)
+		return nil, meta, &authRequiredError{reason: reason}
+	}
+	if err := contentTypeAllowed(allowedTypes, meta.ContentType); err != nil {
+		meta.Duration = time.Since(start)
+		return nil, meta, err
+	}
+	if maxSize > 0 && resp.ContentLength > maxSize {
+		meta.Duration = time.Since(start)
+		return nil, meta, &tooLargeError{size: resp.ContentLength, limit: maxSize}
+	}
+
+	var r io.Reader = resp.Body
+	if maxSize > 0 {
+		r = io.LimitReader(resp.Body, maxSize+1)
+	}
+	body, err := io.ReadAll(r)
+	meta.Duration = time.Since(start)
+	meta.Bytes = int64(len(body))
+	if err != nil {
+		return nil, meta, fmt.Errorf("could not read response body: %w", err)
+	}
+	if maxSize > 0 && int64(len(body)) > maxSize {
+		return nil, meta, &tooLargeError{limit: maxSize}
+	}
+	meta.Hash = contentHash(body)
+	if reason := loginWall(targetURL, resp.Request.URL, meta.ContentType, body); reason != "" {
+		return nil, meta, &authRequiredError{reason: reason}
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, meta, err
+	}
+	return extract(targetURL, meta.ContentType, body), meta, nil
+}
+
+// extractAll runs every extractor over body and over the content embedded
+// in it as data: or javascript: URIs. Findings that come back without a
+// position are located at the first occurrence of their value, if any.
+func extractAll(extractors []Extractor, source, contentType string, body []byte) []Finding {
+	body = toUTF8(body, contentType)
+	findings := make([]Finding, 0)
+	for _, extractor := range extractors {
+		findings = append(findings, extractor.Extract(source, contentType, body)...)
+	}
+
+	var lines lineIndex
+	for i := range findings {
+		if findings[i].Line > 0 {
+			continue
+		}
+		if offset := bytes.Index(body, []byte(findings[i].Value)); offset >= 0 {
+			if lines == nil {
+				lines = newLineIndex(string(body))
+			}
+			findings[i].Offset = offset
+			findings[i].Line = lines.line(offset)
+		}
+	}
+
+	// Scripts hidden in data: and javascript: URIs are extracted too and
+	// located at the URI that carried them.
+	embedded := embeddedContents(body)
+	if len(embedded) == 0 {
+		return findings
+	}
+	seen := make(map[[2]string]struct{}, len(findings))
+	for _, f := range findings {
+		seen[[2]string{f.Category, f.Value}] = struct{}{}
+	}
+	if lines == nil {
+		lines = newLineIndex(string(body))
+	}
+	for _, e := range embedded {
+		for _, f := range extractAll(extractors, source, e.contentType, e.body) {
+			key := [2]string{f.Category, f.Value}
+			if _, ok := seen[key]; ok {
+				continue
+			}
+			seen[key] = struct{}{}
+			f.Offset = e.offset
+			f.Line = lines.line(e.offset)
+			findings = append(findings, f)
+		}
+	}
+	return findings
+}
+
+func (s *scanSession) worker(ctx context.Context, jobs <-chan scanJob, results chan<- linkFinderResult, wg *sync.WaitGroup) {
+	defer wg.Done()
+	first := true
+	emit := func(res linkFinderResult) { results <- res }
+	for job := range jobs {
+		s.metrics.queue(-1)
+		s.handle(ctx, job, &first, emit)
+	}
+}
+
+// handle processes job and passes its result to emit. With -adaptive, a
+// job whose host is at its limit is parked instead, and the worker moves
+// on; the worker finishing a request to that host runs it next.
+func (s *scanSession) handle(ctx context.Context, job scanJob, first *bool, emit func(linkFinderResult)) {
+	if s.adaptive == nil || job.body != nil || isFileURL(job.url) {
+		emit(s.process(ctx, job, first))
+		return
+	}
+	limiter := s.adaptive.limiter(job.url)
+	p := parkedJob{ctx: ctx, job: job, emit: emit}
+	if !limiter.admit(p) {
+		return
+	}
+	for {
+		res := s.process(p.ctx, p.job, first)
+		next, ok := limiter.release(hostHealthy(res.err))
+		p.emit(res)
+		if !ok {
+			return
+		}
+		p = next
+	}
+}
+
+// process fetches and extracts one job with retries, rate limiting and the
+// politeness delay, which is skipped before a worker's first request. Once
+// ctx is done it returns ctx's error without sending anything.
+func (s *scanSession) process(ctx context.Context, job scanJob, first *bool) linkFinderResult {
+	url := job.url
+	if err := ctx.Err(); err != nil {
+		return linkFinderResult{job: job, sourceURL: url, meta: sourceMeta{URL: url}, err: err}
+	}
+	if job.body != nil {
+		meta := sourceMeta{URL: url, ContentType: job.contentType, Bytes: int64(len(job.body)), Hash: contentHash(job.body)}
+		findings := s.capFindings(s.extract(url, job.contentType, job.body), &meta)
+		return linkFinderResult{job: job, sourceURL: url, findings: s.annotate(ctx, findings), meta: meta}
+	}
+	if isFileURL(url) {
+		if !job.input {
+			return linkFinderResult{job: job, sourceURL: url, meta: sourceMeta{URL: url}, err: &skipError{reason: "file URLs are only read from the command-line targets"}}
+		}
+		return s.processFile(ctx, job)
+	}
+	var findings []Finding
+	var meta sourceMeta
+	var err error
+	for attempt := 0; attempt <= s.opts.retries; attempt++ {
+		if attempt > 0 {
+			err = sleep(ctx, time.Duration(attempt)*time.Second)
+		}
+		if !*first && err == nil {
+			err = s.pause(ctx)
+		}
+		*first = false
+		if err == nil {
+			err = s.waitRate(ctx)
+		}
+		if err == nil {
+			findings, meta, err = fetchAndFindLinks(ctx, s.client, job, s.allowedTypes, s.opts.maxSize<<20, s.extract)
+		}
+		// The client reports a cancelled request as a network error.
+		if ctx.Err() != nil {
+			meta.URL, err = url, ctx.Err()
+			break
+		}
+		if !retryableClasses[errorClass(err)] {
+			break
+		}
+	}
+	findings = s.capFindings(findings, &meta)
+	return linkFinderResult{job: job, sourceURL: url, findings: s.annotate(ctx, findings), meta: meta, err: err}
+}
+
+// capFindings keeps the first -max-findings-per-source findings of a
+// source, recording how many were dropped in meta.
+func (s *scanSession) capFindings(findings []Finding, meta *sourceMeta) []Finding {
+	if max := s.opts.maxPerSource; max > 0 && len(findings) > max {
+		meta.Truncated = len(findings) - max
+		findings = findings[:max]
+	}
+	return findings
+}
+
+func (s *scanSession) extract(source, contentType string, body []byte) []Finding {
+	findings := s.bodies.extract(s.extractors, source, contentType, body)
+	addContext(body, contentType, findings, s.opts.contextChars)
+	scoreConfidence(body, findings)
+	findings = filterConfidence(findings, s.opts.minConfidence)
+	if s.libs != nil {
+		findings = s.libs.filter(source, body, findings)
+	}
+	if s.opts.hostScope != nil {
+		findings = s.opts.hostScope.filter(findings)
+	}
+	if s.opts.labels != nil {
+		for i := range findings {
+			findings[i].Labels = s.opts.labels
+		}
+	}
+	return findings
+}
+
+// pause waits -delay plus a random share of -jitter between two requests
+// of the same worker.
+func (s *scanSession) pause(ctx context.Context) error {
+	d := s.opts.delay
+	if s.opts.jitter > 0 {
+		d += time.Duration(rand.Int63n(int64(s.opts.jitter)))
+	}
+	return sleep(ctx, d)
+}
+
+// waitRate waits for the next request slot of -rate.
+func (s *scanSession) waitRate(ctx context.Context) error {
+	if s.rateTick == nil {
+		return nil
+	}
+	select {
+	case <-s.rateTick.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// annotate fills in the notes of secret findings, verifying them first
+// with -verify-secrets.
+func (s *scanSession) annotate(ctx context.Context, findings []Finding) []Finding {
+	for i, f := range findings {
+		if f.Category != categorySecret {
+			continue
+		}
+		if note, ok := s.secretNotes.Load(f.Value); ok {
+			findings[i].Note = note.(string)
+			continue
+		}
+		note := describeSecret(ctx, s.verifyClient, f.Value, s.opts.verifySecrets)
+		s.secretNotes.Store(f.Value, note)
+		findings[i].Note = note
+	}
+	if s.hosts != nil {
+		s.enrichHosts(ctx, findings)
+	}
+	return findings
+}
+
+func newHTTPClient(o *scanOptions) (*http.Client, error) {
+	dial, err := newDialer(o)
+	if err != nil {
+		return nil, err
+	}
+	if o.budget != nil {
+		dial = metered(dial, o.budget)
+	}
+	// QUIC runs over UDP, so it can't go through the custom TCP dialers or
+	// carry an impersonated TLS hello.
+	if o.http3 && (o.unixSocket != "" || o.upstream != "" || o.tlsImpersonate != "" || o.ipv4 || o.ipv6) {
+		return nil, fmt.Errorf("-http3 can't be combined with -unix, -upstream, -tls-impersonate, -4 or -6")
+	}
+	newTransport := func(serverName string) (http.RoundTripper, error) {
+		transport := &http.Transport{
+			DialContext:     dial,
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true, ServerName: serverName},
+			IdleConnTimeout: 90 * time.Second,
+		}
+		if o.tlsImpersonate != "" {
+			dialTLS, err := impersonatingDialer(o.tlsImpersonate, serverName, dial)
+			if err != nil {
+				return nil, err
+			}
+			transport.DialTLSContext = dialTLS
+		}
+		if !o.http3 {
+			return transport, nil
+		}
+		transport.ForceAttemptHTTP2 = true
+		return newHTTP3Transport(transport.TLSClientConfig, transport), nil
+	}
+	base, err := newTransport("")
+	if err != nil {
+		return nil, err
+	}
+	// With a vhost Host header the vhost is also sent as SNI, as a browser
+	// resolving it to that address would.
+	transport := &vhostTransport{base: base, newTransport: newTransport, byName: make(map[string]http.RoundTripper)}
+	return &http.Client{Timeout: o.fetchTimeout, Transport: transport}, nil
+}
+
+func readLines(r io.Reader, lines []string) []string {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// readTargets merges every input given: -git, -burp and -archive sources,
+// each -u, each -l list, and piped stdin. Stdin is read when nothing else
+// was given, or when asked for with -l -.
+func readTargets(o *scanOptions) ([]scanJob, error) {
+	jobs := make([]scanJob, 0)
+	add := func(more []scanJob, err error) error {
+		jobs = append(jobs, more...)
+		return err
+	}
+	if o.gitRepo != "" {
+		if err := add(gitJobs(o.gitRepo, o.gitHistory)); err != nil {
+			return nil, err
+		}
+	}
+	if o.burpFile != "" {
+		if err := add(burpJobs(o.burpFile)); err != nil {
+			return nil, err
+		}
+	}
+	if o.archive != "" {
+		if err := add(archiveJobs(o.archive)); err != nil {
+			return nil, err
+		}
+	}
+	jobs = append(jobs, urlJobs(o.targetURLs)...)
+	for _, path := range o.urlLists {
+		if path == "-" {
+			continue
+		}
+		if err := add(readTargetFile(path)); err != nil {
+			return nil, err
+		}
+	}
+	if o.targetsDir != "" {
+		if err := add(readTargetDir(o.targetsDir)); err != nil {
+			return nil, err
+		}
+	}
+	if (len(jobs) == 0 && len(o.urlLists) == 0 && o.targetsDir == "" || containsString(o.urlLists, "-")) && stdinPiped() {
+		data, err := readStdin()
+		if err != nil {
+			return nil, fmt.Errorf("could not read stdin: %v", err)
+		}
+		if o.stdinBody || !looksLikeURLList(data) {
+			return append(jobs, stdinBodyJob(o, data)), nil
+		}
+		listed, err := parseTargets(readLines(bytes.NewReader(data), nil))
+		if err != nil {
+			return nil, fmt.Errorf("stdin: %v", err)
+		}
+		jobs = append(jobs, listed...)
+	}
+	for i := range jobs {
+		jobs[i].input = jobs[i].body == nil
+	}
+	return jobs, nil
+}
+
+var (
+	stdinOnce sync.Once
+	stdinData []byte
+	stdinErr  error
+)
+
+// readStdin reads piped stdin once; later calls, from a monitor reloading
+// its targets, get the same data.
+func readStdin() ([]byte, error) {
+	stdinOnce.Do(func() {
+		stdinData, stdinErr = io.ReadAll(stdin)
+	})
+	return stdinData, stdinErr
+}
+
+func readTargetFile(path string) ([]scanJob, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("the file '%s' was not found: %v", path, err)
+	}
+	lines := readLines(file, nil)
+	file.Close()
+	listed, err := parseTargets(lines)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return listed, nil
+}
+
+// readTargetDir reads every file of dir as a target list, skipping hidden
+// files (editor swap files, .gitkeep).
+func readTargetDir(dir string) ([]scanJob, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("could not read targets directory: %v", err)
+	}
+	var jobs []scanJob
+	for _, e := range entries {
+		if strings.HasPrefix(e.Name(), ".") || !e.Type().IsRegular() {
+			continue
+		}
+		listed, err := readTargetFile(filepath.Join(dir, e.Name()))
+		if err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, listed...)
+	}
+	return jobs, nil
+}
+
+// looksLikeURLList reports whether piped stdin is a target list rather than
+// raw content, judging by its first non-empty line.
+func looksLikeURLList(data []byte) bool {
+	for _, line := range strings.Split(string(data), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			if strings.HasPrefix(line, "{") || strings.HasPrefix(strings.ToLower(line), "url,") {
+				var t jsonTarget
+				return !strings.HasPrefix(line, "{") || json.Unmarshal([]byte(line), &t) == nil && t.URL != ""
+			}
+			return strings.Contains(line, "://") && !strings.ContainsAny(line, " \t<>{};")
+		}
+	}
+	return true
+}
+
+func stdinBodyJob(o *scanOptions, data []byte) scanJob {
+	source := o.base
+	if source == "" {
+		source = "stdin"
+	}
+	return scanJob{url: source, body: data}
+}
+
+// parseScanFlags parses args into o, then applies the config file and any
+// -profile beneath the flags given explicitly.
+func parseScanFlags(fs *flag.FlagSet, args []string, o *scanOptions) error {
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := checkTheme(o.theme); err != nil {
+		return err
+	}
+
+	cfg, err := loadConfig(o.configPath)
+	if err != nil {
+		return err
+	}
+	if o.profile != "" {
+		profile, err := resolveProfile(o.profile, cfg)
+		if err == nil {
+			err = applyProfile(fs, profile)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	// Read after parsing, so -h doesn't print the credential as a default.
+	if o.auth == "" {
+		o.auth = os.Getenv("GLF_AUTH")
+	}
+	o.config = effectiveConfig(fs)
+	if o.customRules, err = compileUserRules(cfg.Rules); err != nil {
+		return err
+	}
+	var packIgnore *ignoreList
+	if !o.noRulePack {
+		pack, err := loadRulePack(rulePackDir())
+		if err != nil {
+			return err
+		}
+		if pack != nil {
+			rules, ignore, err := pack.compile()
+			if err != nil {
+				return err
+			}
+			o.customRules, packIgnore = append(o.customRules, rules...), ignore
+		}
+	}
+	if cfg.Login != nil {
+		if err := cfg.Login.compile(); err != nil {
+			return err
+		}
+		o.login = cfg.Login
+	}
+	if err := checkConfidence(o.minConfidence); err != nil {
+		return err
+	}
+	if o.probeVerbs, err = parseProbeMethods(o.probeMethods); err != nil {
+		return err
+	}
+	if o.probeFilter, err = loadProbeBodyFilter(o.probeBodies); err != nil {
+		return err
+	}
+	if o.scopeFile != "" {
+		if o.hostScope, err = loadHostScope(o.scopeFile); err != nil {
+			return err
+		}
+	}
+	if o.safe {
+		if err := checkSafe(o); err != nil {
+			return err
+		}
+	}
+	if o.ignore, err = openIgnoreList(o.ignoreFile, o.noIgnore); err != nil {
+		return err
+	}
+	o.ignore = o.ignore.merge(packIgnore)
+	if o.vars, err = parseVars(o.varPairs); err != nil {
+		return err
+	}
+	if o.labels, err = parseLabels(o.labelPairs); err != nil {
+		return err
+	}
+	if o.sinks, err = newSinkMatcher(o.sinkNames); err != nil {
+		return err
+	}
+	if o.signer, err = newRequestSigner(o.awsSigV4, o.signHeaders); err != nil {
+		return err
+	}
+	if o.budget, err = newByteBudget(o.maxBandwidth, o.maxTotalBytes); err != nil {
+		return err
+	}
+	return nil
+}
+
+// initOutput sets the package's colors and writers from a command's
+// flags.
+func initOutput(o *scanOptions) {
+	initColors(o.noColor, o.theme)
+	// -machine keeps stdout for results and moves everything else to
+	// stderr.
+	if o.machine {
+		statusOut = os.Stderr
+	}
+}
+
+// loadTargets parses the command line into o and returns the URLs to scan,
+// exiting with the command usage when no input was given.
+func loadTargets(fs *flag.FlagSet, args []string, o *scanOptions) []scanJob {
+	parseTargetFlags(fs, args, o)
+	return readTargetList(fs, o)
+}
+
+func parseTargetFlags(fs *flag.FlagSet, args []string, o *scanOptions) {
+	err := parseScanFlags(fs, args, o)
+	initOutput(o)
+	if err != nil {
+		fatal(err)
+	}
+
+	// Template lines are meant for other tools; progress output would only
+	// get in their way, unless -machine moves it to stderr.
+	if (o.format != "" || o.jsonl) && !o.machine {
+		o.quiet = true
+	}
+}
+
+// readTargetList reads and filters every target of the input.
+func readTargetList(fs *flag.FlagSet, o *scanOptions) []scanJob {
+	urlsToScan, err := readTargets(o)
+	if err != nil {
+		fatal(err)
+	}
+	if len(urlsToScan) == 0 {
+		fmt.Fprintf(os.Stderr, "%sGoLinkFinder - A fast, concurrent endpoint finder for JavaScript files.%s\n", c.Bold, c.End)
+		fs.Usage()
+		fmt.Fprintf(os.Stderr, "\n%s[!] No input provided. Please use -u, -l, or pipe data from stdin.%s\n", c.Red, c.End)
+		os.Exit(1)
+	}
+	urlsToScan = prepareTargets(o, urlsToScan)
+	if o.dryRun {
+		printDryRun(urlsToScan, o.quiet)
+		os.Exit(0)
+	}
+	return urlsToScan
+}
+
+// prepareTargets filters, canonicalizes and de-duplicates the input
+// targets.
+func prepareTargets(o *scanOptions, urlsToScan []scanJob) []scanJob {
+	urlsToScan, dropped := filterByExtension(urlsToScan, splitList(o.ext), splitList(o.excludeExt))
+	if dropped > 0 && !o.quiet {
+		fmt.Fprintf(statusOut, "%s[*] %d input URL(s) dropped by -ext/-exclude-ext.%s\n", c.Yellow, dropped, c.End)
+	}
+	if o.hostScope != nil {
+		urlsToScan, dropped = o.hostScope.filterJobs(urlsToScan)
+		if dropped > 0 && !o.quiet {
+			fmt.Fprintf(statusOut, "%s[*] %d input URL(s) dropped as out of -scope.%s\n", c.Yellow, dropped, c.End)
+		}
+	}
+	if o.safe {
+		urlsToScan, dropped = safeJobs(urlsToScan)
+		if dropped > 0 && !o.quiet {
+			fmt.Fprintf(statusOut, "%s[*] %d input target(s) dropped by -safe (not GET/HEAD, or state-changing-looking).%s\n", c.Yellow, dropped, c.End)
+		}
+		if o.hostScope == nil {
+			o.hostScope = inputScope(urlsToScan)
+		}
+	}
+	if o.hostHeader != "" {
+		for i := range urlsToScan {
+			if urlsToScan[i].host == "" {
+				urlsToScan[i].host = o.hostHeader
+			}
+		}
+	}
+	canonicalJobs(urlsToScan, o.stripQuery)
+	urlsToScan, dropped = dedupJobs(urlsToScan)
+	if dropped > 0 && !o.quiet {
+		fmt.Fprintf(statusOut, "%s[*] %d duplicate input target(s) skipped.%s\n", c.Yellow, dropped, c.End)
+	}
+	return urlsToScan
+}
+
+// dedupJobs drops repeated targets, keeping the first, and returns how
+// many it dropped.
+func dedupJobs(jobs []scanJob) ([]scanJob, int) {
+	total := len(jobs)
+	seen := make(map[string]struct{}, total)
+	unique := jobs[:0]
+	for _, job := range jobs {
+		key := jobKey(job)
+		if _, ok := seen[key]; ok && job.body == nil {
+			continue
+		}
+		seen[key] = struct{}{}
+		unique = append(unique, job)
+	}
+	return unique, total - len(unique)
+}
+
+func printDryRun(jobs []scanJob, quiet bool) {
+	if !quiet {
+		fmt.Fprintf(statusOut, "%s[*] Dry run: %d target(s) would be scanned:%s\n", c.Yellow, len(jobs), c.End)
+	}
+	for _, job := range jobs {
+		method := job.method
+		if method == "" {
+			method = "GET"
+		}
+		// The list is the command's output, so it stays on stdout under
+		// -machine; only the header is status.
+		if quiet {
+			fmt.Fprintln(findingsOut, job.url)
+			continue
+		}
+		note := ""
+		if job.body != nil {
+			note = fmt.Sprintf("  %s(%d bytes of supplied content, not fetched)%s", c.Blue, len(job.body), c.End)
+		} else if len(job.headers) > 0 {
+			note = fmt.Sprintf("  %s(%d header(s))%s", c.Blue, len(job.headers), c.End)
+		}
+		if job.host != "" {
+			note = fmt.Sprintf("  %s(Host: %s)%s", c.Blue, job.host, c.End) + note
+		}
+		fmt.Fprintf(findingsOut, "  %s %s%s\n", method, job.url, note)
+	}
+}
+
+// coreExtractors returns the built-in extractors for o, ahead of any plugin.
+// Custom rules report overruns to out.
+func coreExtractors(o *scanOptions, rules []extractionRule, out *output) []Extractor {
+	extractors := []Extractor{grpcExtractor{}, ruleExtractor{rules: rules, stringsOnly: o.stringsOnly}, specExtractor{}, feedExtractor{}, webpackExtractor{}, esmExtractor{}, routeExtractor{}, baseExtractor{}, envExtractor{vars: o.vars}}
+	if o.verifySecrets || wantsExtract(o.extract, "secrets") {
+		extractors = append(extractors, secretExtractor{})
+	}
+	if len(o.customRules) > 0 {
+		extractors = append(extractors, newGuardedRuleExtractor(o.customRules, o.ruleTimeout, o.stringsOnly, out))
+	}
+	if wantsExtract(o.extract, "jwt") {
+		extractors = append(extractors, jwtExtractor{})
+	}
+	if wantsExtract(o.extract, "backends") {
+		extractors = append(extractors, backendExtractor{})
+	}
+	return extractors
+}
+
+type scanSession struct {
+	opts       *scanOptions
+	out        *output
+	client     *http.Client
+	extractors []Extractor
+	plugins    []*subprocessExtractor
+	rdb        *resultsDB
+	// runID is the -db run of the current pass.
+	runID    int64
+	adaptive *adaptiveScheduler
+	rateTick *time.Ticker
+	format   *template.Template
+	// stats describes the last run.
+	stats  *scanStats
+	sarif  *sarifReport
+	bodies *bodyCache
+	scope  crawlScope
+	creds  *credentialStore
+	libs   *libraryFilter
+	// allowedTypes is the parsed -content-type allowlist.
+	allowedTypes []string
+	layout       *listLayout
+	// sampler thins the printed findings with -sample-per-host; only the
+	// reporter goroutine uses it.
+	sampler *hostSampler
+	metrics *scanMetrics
+	debug   *httpDebug
+	// capped is set once -max-findings or -max-total-bytes is reached.
+	capped bool
+	// files collects the values by category for -o-dir.
+	files *categoryFiles
+	// verifyClient makes the -verify-secrets calls to providers.
+	verifyClient *http.Client
+	// secretNotes caches describeSecret per value so a key seen in many
+	// bundles is verified once.
+	secretNotes sync.Map
+	dedup       *deduper
+	// hosts and hostInfos are the -resolve-hosts lookups, cached per host.
+	hosts     *hostEnricher
+	hostInfos sync.Map
+	// stream receives every finding as it is found with -o x.ndjson.
+	stream *ndjsonSink
+}
+
+// newScanSession sets up a session for o that writes to out.
+func newScanSession(o *scanOptions, out *output) (*scanSession, error) {
+	rules, err := buildRules(o.extract, o.sinks)
+	if err != nil {
+		return nil, err
+	}
+	client, err := newHTTPClient(o)
+	if err != nil {
+		return nil, err
+	}
+	var debug *httpDebug
+	if o.debugHTTP {
+		debug = newHTTPDebug(out.errs)
+		client.Transport = &debugTransport{next: client.Transport, debug: debug}
+	}
+	var metrics *scanMetrics
+	if o.metrics {
+		metrics = newScanMetrics()
+		client.Transport = &metricsTransport{next: client.Transport, metrics: metrics}
+	}
+	creds, err := loadCredentials(o)
+	if err != nil {
+		return nil, err
+	}
+	id, err := newIdentity(o)
+	if err != nil {
+		return nil, err
+	}
+	if o.signer != nil {
+		client.Transport = &signingTransport{next: client.Transport, signer: o.signer, creds: creds}
+	}
+	client.Transport = &authTransport{next: &identityTransport{next: client.Transport, id: id}, creds: creds}
+	if o.login != nil {
+		session, err := login(context.Background(), client, o.login)
+		if err != nil {
+			return nil, err
+		}
+		client.Transport = &loginTransport{next: client.Transport, session: session, creds: creds}
+		if !o.quiet {
+			captured := fmt.Sprintf("%d cookie(s)", session.cookies())
+			if session.value != "" {
+				captured += " and a token"
+			}
+			fmt.Fprintf(out.status, "%s[*] Logged in at %s: %s.%s\n", out.c.Yellow, session.host, captured, out.c.End)
+		}
+	}
+	if o.safe {
+		client.Transport = &safeTransport{next: client.Transport}
+	}
+	if o.hostScope != nil || o.safe {
+		client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+			if !o.hostScope.allows(req.URL.String()) {
+				return fmt.Errorf("redirect to out-of-scope host %s", req.URL.Hostname())
+			}
+			if o.safe && looksStateChanging(req.URL.String()) {
+				return fmt.Errorf("-safe: refusing redirect to %s", req.URL.Path)
+			}
+			if len(via) >= 10 {
+				return errors.New("stopped after 10 redirects")
+			}
+			return nil
+		}
+	}
+	s := &scanSession{
+		opts:       o,
+		client:     client,
+		creds:      creds,
+		extractors: coreExtractors(o, rules, out),
+		out:        out,
+		bodies:     newBodyCache(),
+		metrics:    metrics,
+		debug:      debug,
+	}
+	if o.ignoreLibs {
+		s.libs = newLibraryFilter()
+	}
+	if o.verifySecrets {
+		s.verifyClient = newVerifyClient()
+	}
+	s.allowedTypes = splitList(o.contentTypes)
+	if s.hosts, err = newHostEnricher(o, client); err != nil {
+		return nil, err
+	}
+	if s.dedup, err = newDeduper(o.dedupKey); err != nil {
+		return nil, err
+	}
+	if s.dedup.resolves() {
+		o.resolve = true
+	}
+	if s.layout, err = newListLayout(o.sortBy, o.groupBy, o.minCount, o.counts); err != nil {
+		return nil, err
+	}
+	s.sampler = newHostSampler(o.samplePerHost)
+	if o.outputDir != "" {
+		s.files = newCategoryFiles()
+	}
+	if o.format != "" {
+		if s.format, err = parseFormat(o.format); err != nil {
+			return nil, err
+		}
+	}
+	for _, command := range o.plugins {
+		plugin, err := startPlugin(command, o.pluginTimeout, out)
+		if err != nil {
+			return nil, err
+		}
+		s.plugins = append(s.plugins, plugin)
+		s.extractors = append(s.extractors, plugin)
+	}
+	if o.rate > 0 {
+		s.rateTick = time.NewTicker(time.Duration(float64(time.Second) / o.rate))
+	}
+	if o.adaptive {
+		s.adaptive = newAdaptiveScheduler(o.threads)
+	}
+	if o.dbPath != "" {
+		rdb, err := openResultsDB(o.dbPath)
+		if err != nil {
+			return nil, err
+		}
+		s.rdb = rdb
+	}
+	return s, nil
+}
+
+// reportError reports an error the session carries on after.
+func (s *scanSession) reportError(err error) {
+	fmt.Fprintf(s.out.errs, "%s[!] Error: %v%s\n", s.out.c.Red, err, s.out.c.End)
+}
+
+func (s *scanSession) Close() {
+	if s.debug != nil {
+		s.debug.report()
+	}
+	for _, plugin := range s.plugins {
+		plugin.Close()
+	}
+	if s.rdb != nil {
+		s.rdb.Close()
+	}
+	if s.rateTick != nil {
+		s.rateTick.Stop()
+	}
+}
+
+// run scans every URL with the worker pool and returns the set of unique
+// endpoints along with the number of targets that failed. The caller owns
+// the set and must Close it.
+func (s *scanSession) run(ctx context.Context, urlsToScan []scanJob) (resultSet, int) {
+	s.creds.trust(urlsToScan)
+	if s.opts.crawl {
+		s.scope = newCrawlScope(urlsToScan)
+	}
+	return s.runFeed(ctx, &sliceFeed{jobs: urlsToScan}, len(urlsToScan))
+}
+
+// runFeed is run for targets handed out by feed as workers free up;
+// targets is how many there are, or 0 when the feed doesn't know.
+func (s *scanSession) runFeed(ctx context.Context, feed jobFeed, targets int) (resultSet, int) {
+	o := s.opts
+	ctx, cancel := withTimeout(ctx, o.scanTimeout)
+	defer cancel()
+	s.stats = newScanStats(o.config)
+	s.stats.Labels = o.labels
+	s.capped = false
+	if s.rdb != nil {
+		var err error
+		if s.runID, err = s.rdb.startRun(s.stats, os.Args[1:], targets); err != nil {
+			fatal(err)
+		}
+	}
+
+	if o.sarifFile != "" {
+		s.sarif = newSarifReport()
+	}
+	// Probing with -format needs each endpoint's source for {{.Source}}.
+	found, err := newResultSet(o.spillDir, o.provenance || (s.format != nil && o.probe) || s.layout.needsRefs())
+	if err != nil {
+		fatal(err)
+	}
+	// Followed specs, chunks and crawled links are fetched once even when
+	// several sources point at them. URLs are kept hashed, as the targets
+	// of a streamed list can run into millions.
+	scanned := make(map[uint64]struct{})
+	failed := 0
+	seeds := 0
+	for pass := feed; pass != nil; {
+		passFailed, discovered := s.scanPass(ctx, pass, found, scanned)
+		failed += passFailed
+		if seeds == 0 {
+			seeds = s.stats.Targets
+		}
+
+		pass = nil
+		if s.capped || ctx.Err() != nil {
+			break
+		}
+		next := make([]scanJob, 0, len(discovered))
+		for _, job := range discovered {
+			key := hashKey(job.url)
+			if _, ok := scanned[key]; !ok {
+				scanned[key] = struct{}{}
+				next = append(next, job)
+			}
+		}
+		if len(next) > 0 {
+			if !o.quiet {
+				fmt.Fprintf(statusOut, "\n%s[*] Following %d discovered URL(s)...%s\n", c.Yellow, len(next), c.End)
+			}
+			pass = &sliceFeed{jobs: next}
+		}
+	}
+
+	switch ctx.Err() {
+	case context.DeadlineExceeded:
+		fmt.Fprintf(os.Stderr, "%s[!] The scan hit -scan-timeout (%s); reporting what was found so far.%s\n", c.Yellow, o.scanTimeout, c.End)
+	case context.Canceled:
+		fmt.Fprintf(os.Stderr, "%s[!] Scan interrupted; reporting what was found so far.%s\n", c.Yellow, c.End)
+	}
+	s.stats.Failed = failed
+	s.stats.Reused = s.bodies.hits
+	if s.libs != nil {
+		s.stats.Suppressed = s.libs.suppressed
+	}
+	if o.hostScope != nil {
+		s.stats.OutOfScope = o.hostScope.dropped
+	}
+	s.stats.finish(found)
+	if s.rdb != nil {
+		if err := s.rdb.finishRun(s.runID, seeds, failed, found.Len()); err != nil {
+			fmt.Fprintf(os.Stderr, "%s[!] Error: %v%s\n", c.Red, err, c.End)
+		}
+	}
+	return found, failed
+}
+
+// scanPass fetches one batch of URLs, adding new values to found. It returns
+// the number of failed targets and the discovered URLs worth fetching next:
+// webpack chunks, API specifications with -follow-specs, sitemaps with
+// -follow-sitemaps, and in-scope links with -crawl.
+func (s *scanSession) scanPass(ctx context.Context, feed jobFeed, found resultSet, scanned map[uint64]struct{}) (int, []scanJob) {
+	o := s.opts
+
+	// Both queues are bounded by the thread count: the feeder blocks while
+	// workers are busy and workers block while results are being handled,
+	// so a streamed target list is read no faster than it is scanned.
+	jobs := make(chan scanJob, o.threads)
+	results := make(chan linkFinderResult, o.threads)
+
+	var wg sync.WaitGroup
+	for i := 0; i < o.threads; i++ {
+		wg.Add(1)
+		go s.worker(ctx, jobs, results, &wg)
+	}
+	// The feeder shrinks feed as it goes, so only it may ask feed for its
+	// size once started.
+	total := feed.size()
+	// stop ends the feeder early once -max-findings is reached, as does
+	// ctx being done; the jobs never handed out count as aborted.
+	stop := make(chan struct{})
+	unsent := 0
+	go func() {
+		defer close(jobs)
+		for {
+			job, ok := feed.next()
+			if !ok {
+				return
+			}
+			s.metrics.queue(1)
+			select {
+			case jobs <- job:
+				continue
+			case <-stop:
+			case <-ctx.Done():
+				unsent = 1
+				if n := feed.size(); n > 0 {
+					unsent += n
+				}
+			}
+			s.metrics.queue(-1)
+			return
+		}
+	}()
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	if !o.quiet {
+		if total >= 0 {
+			fmt.Fprintf(statusOut, "%s[*] Scanning %d URL(s) with %d threads...%s\n", c.Yellow, total, o.threads, c.End)
+		} else {
+			fmt.Fprintf(statusOut, "%s[*] Scanning URLs as they are read with %d threads...%s\n", c.Yellow, o.threads, c.End)
+		}
+	}
+
+	// Output goes through rep, drained before the pass returns.
+	rep := newReporter()
+	defer rep.close()
+
+	failed := 0
+	discovered := make([]scanJob, 0)
+	for res := range results {
+		res := res
+		s.stats.Targets++
+		scanned[hashKey(res.job.url)] = struct{}{}
+		s.metrics.observe(res)
+		// Past -max-findings or -max-total-bytes the jobs already handed
+		// out are drained.
+		if s.capped {
+			continue
+		}
+		if o.budget.exhausted() {
+			s.capped = true
+			close(stop)
+			fmt.Fprintf(os.Stderr, "%s[!] Reached -max-total-bytes (%s); stopping the scan.%s\n", c.Yellow, formatBytes(o.budget.limit), c.End)
+		}
+		if aborted(res.err) {
+			s.stats.Aborted++
+			continue
+		}
+		s.stats.addSource(res)
+		var skipped *skipError
+		if errors.As(res.err, &skipped) {
+			s.stats.Skipped++
+			if !o.quiet {
+				rep.do(func() {
+					fmt.Fprintf(os.Stderr, "%s[*] Skipped %s: %v%s\n", c.Yellow, res.sourceURL, res.err, c.End)
+				})
+			}
+			continue
+		}
+		var authErr *authRequiredError
+		if errors.As(res.err, &authErr) {
+			s.stats.AuthRequired = append(s.stats.AuthRequired, res.sourceURL)
+			rep.do(func() {
+				if !o.quiet {
+					fmt.Fprintf(os.Stderr, "%s[!] %s needs credentials (%s)%s\n", c.Yellow, res.sourceURL, authErr.reason, c.End)
+				}
+				s.recordSource(res, nil)
+			})
+			continue
+		}
+		if res.err != nil {
+			failed++
+			rep.do(func() {
+				if !o.quiet {
+					fmt.Fprintf(os.Stderr, "%s[-] Error scanning %s: %v%s\n", c.Red, res.sourceURL, res.err, c.End)
+				}
+				s.recordSource(res, nil)
+			})
+			continue
+		}
+
+		if !o.quiet {
+			rep.do(func() {
+				if res.meta.Truncated > 0 {
+					fmt.Fprintf(os.Stderr, "%s[!] %s: kept the first %d findings, dropped %d more (-max-findings-per-source)%s\n", c.Yellow, res.sourceURL, len(res.findings), res.meta.Truncated, c.End)
+				}
+				if res.meta.servesErrorPage() {
+					fmt.Fprintf(os.Stderr, "%s[!] %s answered with %s instead of a script (error page or SPA fallback?)%s\n", c.Yellow, res.sourceURL, res.meta.ContentType, c.End)
+				}
+			})
+		}
+		sourceFindings := make([]Finding, 0, len(res.findings))
+		if len(res.findings) > 0 {
+			if !o.quiet {
+				rep.do(func() {
+					fmt.Fprintf(statusOut, "\n%s[+] Endpoints found in %s (%s):%s\n", c.Blue, res.sourceURL, res.meta.describe(), c.End)
+				})
+			}
+
+			baseURL, _ := url.Parse(res.sourceURL)
+			// Followed URLs on the origin of the job that found them keep its
+			// headers and Host, so cookies given for a seed stay with its
+			// pages and never reach another host.
+			follow := func(u string, depth, imports int) {
+				if !isHTTPURL(u) || !o.hostScope.allows(u) || (o.safe && looksStateChanging(u)) {
+					return
+				}
+				job := scanJob{url: u, depth: depth, imports: imports}
+				if sameOrigin(baseURL, u) {
+					job.host, job.headers = res.job.host, res.job.headers
+				}
+				discovered = append(discovered, job)
+			}
+			var apiBases map[string]string
+			if o.resolve && !o.noBasePaths {
+				apiBases = sourceBases(baseURL, res.findings)
+			}
+			for _, f := range res.findings {
+				if s.capped {
+					break
+				}
+				if o.followSpecs {
+					if u := resolveAgainst(baseURL, f.Value, false); looksLikeSpecURL(u) {
+						follow(u, res.job.depth, res.job.imports)
+					}
+				}
+				if f.Category == categoryFeed && f.Note == "sitemap index" && o.followSitemaps {
+					follow(resolveAgainst(baseURL, f.Value, false), res.job.depth, res.job.imports)
+				}
+				if f.Category == categoryChunk && !o.noChunks {
+					follow(f.Value, res.job.depth, res.job.imports)
+				}
+				if f.Category == categoryModule && !o.noModules && res.job.imports < o.moduleDepth && s.moduleInScope(baseURL, f.Value) {
+					follow(f.Value, res.job.depth, res.job.imports+1)
+				}
+				if s.scope != nil && (f.Category == categoryEndpoint || f.Category == categoryFeed) && res.job.depth < o.depth {
+					if u, ok := s.scope.crawlTarget(baseURL, f.Value); ok {
+						follow(u, res.job.depth+1, res.job.imports)
+					}
+				}
+				raw := f.Value
+				if o.resolve && resolvableCategory(f.Category) {
+					f = withBase(apiBases, f)
+					f.Value = resolveAgainst(baseURL, f.Value, false)
+				}
+				if e := o.ignore.match(f.Category, raw, f.Value); e != nil {
+					tag := e.tag
+					if tag == "" {
+						tag = "untagged"
+					}
+					s.stats.Ignored[tag]++
+					continue
+				}
+
+				sourceFindings = append(sourceFindings, f)
+				if o.onlyInteresting && !isInteresting(f.Value) {
+					continue
+				}
+				f.Value = s.dedup.value(baseURL, f, raw)
+				isNew, err := found.Add(f.Value, sourceRef{Source: res.sourceURL, Line: f.Line, Offset: f.Offset})
+				if err != nil {
+					fatal(err)
+				}
+				if isNew {
+					s.stats.addNew(f)
+					s.layout.record(f.Value, f.Category)
+					if s.files != nil {
+						s.files.add(f)
+					}
+					if o.maxFindings > 0 && found.Len() >= o.maxFindings && !s.capped {
+						s.capped = true
+						close(stop)
+						fmt.Fprintf(os.Stderr, "%s[!] Reached -max-findings (%d); stopping the scan.%s\n", c.Yellow, o.maxFindings, c.End)
+					}
+				}
+				if s.sarif != nil {
+					s.sarif.add(f)
+				}
+				if !isNew && !o.provenance {
+					continue
+				}
+				f := f
+				rep.do(func() {
+					if s.stream != nil {
+						if err := s.stream.write(findingRecord(f).withSource(res.meta)); err != nil {
+							fatal(err)
+						}
+					}
+					if !o.probe && (s.format != nil || o.jsonl || o.machine) {
+						printRecord(s.format, o.jsonl, findingRecord(f).withSource(res.meta))
+					}
+					if !o.quiet && s.format == nil && !o.jsonl && s.sampler.keep(f) {
+						printFinding(f, o.provenance)
+					}
+				})
+			}
+		}
+
+		rep.do(func() { s.recordSource(res, sourceFindings) })
+	}
+	s.stats.Targets += unsent
+	s.stats.Aborted += unsent
+
+	return failed, discovered
+}
+
+// recordSource saves a scanned source and its findings to -db.
+func (s *scanSession) recordSource(res linkFinderResult, findings []Finding) {
+	if s.rdb == nil {
+		return
+	}
+	hash := res.meta.Hash
+	if res.err != nil {
+		hash = ""
+	}
+	if err := s.rdb.recordSource(s.runID, res.sourceURL, hash, res.err, findings); err != nil {
+		fmt.Fprintf(os.Stderr, "%s[!] Error: %v%s\n", c.Red, err, c.End)
+	}
+}
+
+func printFinding(f Finding, withPosition bool) {
+	color := categoryColor(f.Category)
+	if isInteresting(f.Value) {
+		color = c.Red
+	}
+	position := ""
+	if withPosition && f.Line > 0 {
+		position = fmt.Sprintf("  %s(line %d, offset %d)%s", c.Blue, f.Line, f.Offset, c.End)
+	}
+	if template := templatePath(f.Value); template != f.Value && resolvableCategory(f.Category) {
+		position += fmt.Sprintf("  %s-> %s%s", c.Blue, template, c.End)
+	}
+	if f.Sink != "" {
+		position += fmt.Sprintf("  %s(%s)%s", c.Blue, strings.TrimSpace(f.Method+" "+f.Sink), c.End)
+	}
+	if f.Base != "" {
+		position += fmt.Sprintf("  %s(base %s)%s", c.Blue, f.Base, c.End)
+	}
+	if h := f.HostInfo; h != nil && len(h.IPs) > 0 {
+		details := strings.Join(h.IPs, ", ")
+		if h.ASN != "" {
+			details += " " + strings.TrimSpace(h.ASN+" "+h.Org)
+		}
+		if h.Private {
+			position += fmt.Sprintf("  %s(%s, private)%s", c.Red, details, c.End)
+		} else {
+			position += fmt.Sprintf("  %s(%s)%s", c.Blue, details, c.End)
+		}
+	}
+	if f.Note != "" {
+		noteColor := c.Blue
+		if strings.HasSuffix(f.Note, ", active") {
+			noteColor = c.Bold + c.Red
+		}
+		position += fmt.Sprintf("  %s(%s)%s", noteColor, f.Note, c.End)
+	}
+	switch {
+	case f.Category == categoryEndpoint:
+		fmt.Fprintf(statusOut, "  %s%s%s%s\n", color, f.Value, c.End, position)
+	case f.Method != "":
+		fmt.Fprintf(statusOut, "  %s[%s]%s %s %s%s%s%s\n", c.Yellow, f.Category, c.End, f.Method, color, f.Value, c.End, position)
+	default:
+		fmt.Fprintf(statusOut, "  %s[%s]%s %s%s%s%s\n", c.Yellow, f.Category, c.End, color, f.Value, c.End, position)
+	}
+}
+
+// writeResultSet saves the list to path, as JSON when it ends in .json.
+func writeResultSet(path string, set resultSet, provenance bool, layout *listLayout) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	writer := bufio.NewWriter(file)
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		err = layout.writeJSON(writer, set, provenance)
+	} else {
+		err = printResultSet(writer, set, provenance, layout)
+	}
+	if err != nil {
+		return err
+	}
+	return writer.Flush()
+}
+
+// printResultSet writes one value per line or, with provenance, one
+// tab-separated "value, source, line, offset" row per source of each value,
+// ordered and grouped by layout.
+func printResultSet(w io.Writer, set resultSet, provenance bool, layout *listLayout) error {
+	if !layout.plain() {
+		return layout.print(w, set, provenance)
+	}
+	if !provenance {
+		return set.Each(func(value string) error {
+			_, err := fmt.Fprintln(w, value)
+			return err
+		})
+	}
+	return set.EachWithRefs(func(value string, refs []sourceRef) error {
+		for _, ref := range refs {
+			if _, err := fmt.Fprintf(w, "%s\t%s\t%d\t%d\n", value, ref.Source, ref.Line, ref.Offset); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func runScan(args []string) {
+	o := defaultScanOptions()
+	fs := flag.NewFlagSet("scan", flag.ExitOnError)
+	addScanFlags(fs, &o)
+	fs.BoolVar(&o.probe, "probe", o.probe, "Request every resolved endpoint after the scan and report its status (implies -r).")
+	fs.IntVar(&o.probeCluster, "probe-cluster", o.probeCluster, "Show at most this many probe answers with the same status, length and body, and hide answers matching a non-existent path (0 shows all).")
+	scanAndReport(fs, args, &o)
+}
+
+func scanAndReport(fs *flag.FlagSet, args []string, o *scanOptions) {
+	parseTargetFlags(fs, args, o)
+	stream, err := openTargetStream(o)
+	if err != nil {
+		fatal(err)
+	}
+	var urlsToScan []scanJob
+	if stream == nil {
+		urlsToScan = readTargetList(fs, o)
+	} else {
+		defer stream.Close()
+	}
+	if o.probe || o.nucleiDir != "" {
+		o.resolve = true
+	}
+
+	ctx, stop := signalContext()
+	defer stop()
+	s, err := newScanSession(o, cliOutput())
+	if err != nil {
+		fatal(err)
+	}
+	defer s.Close()
+	if isNDJSON(o.outputFile) {
+		stream, err := openNDJSON(o.outputFile)
+		if err != nil {
+			fatal(err)
+		}
+		defer stream.Close()
+		s.stream = stream
+	}
+	var found resultSet
+	if stream != nil {
+		stream.creds = s.creds
+		found, _ = s.runFeed(ctx, stream, 0)
+		stream.report()
+	} else {
+		if o.precheck {
+			urlsToScan = s.precheck(ctx, urlsToScan)
+		}
+		found, _ = s.run(ctx, urlsToScan)
+	}
+	defer found.Close()
+	if s.sampler != nil {
+		s.stats.Unprinted = s.sampler.hidden
+	}
+
+	// listed is what the endpoint list and -o show: the raw values, or
+	// their templates with -template.
+	listed := found
+	if o.template {
+		templated, err := templatedResultSet(found, o.provenance)
+		if err != nil {
+			fatal(err)
+		}
+		if !o.quiet {
+			fmt.Fprintf(statusOut, "\n%s[*] %d endpoints collapse into %d templates.%s\n", c.Yellow, found.Len(), templated.Len(), c.End)
+		}
+		listed = templated
+		s.layout = s.layout.templated()
+	}
+
+	// -format, -jsonl and -machine already streamed every finding.
+	if o.quiet && !o.probe && s.format == nil && !o.jsonl && !o.machine {
+		printResultSet(findingsOut, listed, o.provenance, s.layout)
+	}
+
+	// saved lists the files written, for -checksums.
+	var saved []string
+	if s.stream != nil {
+		saved = append(saved, o.outputFile)
+		if !o.quiet {
+			fmt.Fprintf(statusOut, "\n%s[*] Streamed %d unique endpoints to '%s'.%s\n", c.Yellow, found.Len(), o.outputFile, c.End)
+		}
+	} else if o.outputFile != "" {
+		if !o.quiet {
+			fmt.Fprintf(statusOut, "\n%s[*] Saving %d unique endpoints to '%s'...%s\n", c.Yellow, listed.Len(), o.outputFile, c.End)
+		}
+		if err := writeResultSet(o.outputFile, listed, o.provenance, s.layout); err != nil {
+			fmt.Fprintf(os.Stderr, "%s[!] Error creating output file: %v%s\n", c.Red, err, c.End)
+			os.Exit(1)
+		}
+		saved = append(saved, o.outputFile)
+	}
+
+	if o.authFile != "" {
+		if err := writeAuthRequired(o.authFile, s.stats.AuthRequired); err != nil {
+			fmt.Fprintf(os.Stderr, "%s[!] Error: %v%s\n", c.Red, err, c.End)
+		} else {
+			saved = append(saved, o.authFile)
+		}
+	}
+	if o.retryFile != "" {
+		if err := writeRetryable(o.retryFile, s.stats.Sources); err != nil {
+			fmt.Fprintf(os.Stderr, "%s[!] Error: %v%s\n", c.Red, err, c.End)
+		} else {
+			saved = append(saved, o.retryFile)
+		}
+	}
+
+	if o.outputDir != "" {
+		files, err := s.files.write(o.outputDir)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s[!] Error: %v%s\n", c.Red, err, c.End)
+		} else {
+			if !o.quiet {
+				fmt.Fprintf(statusOut, "\n%s[*] Wrote %s to '%s'.%s\n", c.Yellow, strings.Join(files, ", "), o.outputDir, c.End)
+			}
+			if o.checksums {
+				if err := writeChecksums(filepath.Join(o.outputDir, "checksums.json"), o.outputDir, files, s.stats); err != nil {
+					fmt.Fprintf(os.Stderr, "%s[!] Error writing checksums: %v%s\n", c.Red, err, c.End)
+				}
+			}
+		}
+	}
+
+	if o.nucleiDir != "" {
+		hosts, err := writeNuclei(o.nucleiDir, found)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s[!] Error writing nuclei output: %v%s\n", c.Red, err, c.End)
+		} else if !o.quiet {
+			fmt.Fprintf(statusOut, "\n%s[*] Wrote nuclei targets and templates for %d host(s) to '%s'.%s\n", c.Yellow, hosts, o.nucleiDir, c.End)
+		}
+	}
+
+	if o.probe {
+		probeEndpoints(ctx, s.client, found, o, s.format)
+	}
+
+	if o.sarifFile != "" {
+		if err := s.sarif.write(o.sarifFile, s.stats); err != nil {
+			fmt.Fprintf(os.Stderr, "%s[!] Error writing SARIF output: %v%s\n", c.Red, err, c.End)
+		} else {
+			saved = append(saved, o.sarifFile)
+		}
+	}
+	if o.summaryFile != "" {
+		if err := writeSummary(o.summaryFile, s.stats); err != nil {
+			fmt.Fprintf(os.Stderr, "%s[!] Error writing summary: %v%s\n", c.Red, err, c.End)
+		} else {
+			saved = append(saved, o.summaryFile)
+		}
+	}
+	if o.checksums {
+		for _, path := range saved {
+			checksumSidecar(path, s.stats)
+		}
+	}
+	if !o.quiet {
+		printInterestingSection(found)
+		printSummary(s.stats)
+
+		fmt.Fprintf(statusOut, "\n%s%s[✔] Done. Found a total of %d unique endpoints.%s%s\n", c.Bold, c.Yellow, found.Len(), c.End, c.End)
+	}
+}
@@ -0,0 +1,63 @@
+package golinkfinder
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestLooksLikeURLList(t *testing.T) {
+	tests := []struct {
+		name, data string
+		want       bool
+	}{
+		{"urls", "https://example.com/a.js\nhttps://example.com/b.js\n", true},
+		{"leading blank lines", "\n\n  https://example.com/a.js\n", true},
+		{"empty", "", true},
+		{"jsonl target", `{"url": "https://example.com/a.js", "method": "GET"}`, true},
+		{"json object without url", `{"name": "app", "version": "1.0.0"}`, false},
+		{"csv header", "url,method\nhttps://example.com/a.js,GET\n", true},
+		{"csv header upper case", "URL,header:Authorization\n", true},
+		{"script", "fetch(\"https://example.com/api\");\n", false},
+		{"minified script with url", "var a={u:\"https://example.com\"};", false},
+		{"html", "<script src=\"https://example.com/a.js\"></script>", false},
+		{"plain text", "hello world\n", false},
+	}
+	for _, tt := range tests {
+		if got := looksLikeURLList([]byte(tt.data)); got != tt.want {
+			t.Errorf("%s: looksLikeURLList(%q) = %v, want %v", tt.name, tt.data, got, tt.want)
+		}
+	}
+}
+
+// Run with -race: the "Scanning N URL(s)" banner used to read the feed's
+// size while the feeder goroutine was shrinking it.
+func TestRunSliceFeed(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/javascript")
+		fmt.Fprintf(w, "fetch('/api%s');", r.URL.Path)
+	}))
+	defer srv.Close()
+	defer func(w io.Writer) { statusOut = w }(statusOut)
+	statusOut = io.Discard
+
+	o := defaultScanOptions()
+	o.threads = 8
+	s, err := newScanSession(&o, cliOutput())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	jobs := make([]scanJob, 200)
+	for i := range jobs {
+		jobs[i] = scanJob{url: fmt.Sprintf("%s/%d", srv.URL, i)}
+	}
+	found, failed := s.run(context.Background(), jobs)
+	defer found.Close()
+	if failed != 0 || found.Len() != len(jobs) {
+		t.Errorf("got %d endpoints and %d failures, want %d and 0", found.Len(), failed, len(jobs))
+	}
+}
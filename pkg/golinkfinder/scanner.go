@@ -0,0 +1,78 @@
+// Package golinkfinder is the scanner behind the golinkfinder command,
+// for Go programs that embed it: a Scanner streams the findings of each
+// target as soon as it is scanned, leaving dedup and storage to the
+// caller.
+package golinkfinder
+
+import (
+	"context"
+	"flag"
+	"io"
+)
+
+// Scanner is a scan session set up like the scan command: one HTTP
+// client, set of extractors, rate limit and pool of -t workers shared by
+// every Stream. It prints nothing and leaves the package's colors and
+// writers alone, so several Scanners and the program around them don't
+// get in each other's way.
+type Scanner struct {
+	session *scanSession
+	server  *scanServer
+}
+
+// NewScanner returns a Scanner configured by scan command flags, such as
+// "-extract", "all", "-rate", "10"; the config file and -profile apply as
+// on the command line. Targets are given to Stream, so -u and -l are
+// ignored.
+func NewScanner(args ...string) (*Scanner, error) {
+	o := defaultScanOptions()
+	fs := flag.NewFlagSet("golinkfinder", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	addScanFlags(fs, &o)
+	if err := parseScanFlags(fs, args, &o); err != nil {
+		return nil, err
+	}
+	if err := checkAPISafe(&o); err != nil {
+		return nil, err
+	}
+	o.quiet = true
+	quiet := &output{findings: io.Discard, status: io.Discard, errs: io.Discard}
+	s, err := newScanSession(&o, quiet)
+	if err != nil {
+		return nil, err
+	}
+	return &Scanner{session: s, server: newScanServer(s, "", 0, 0)}, nil
+}
+
+// Stream scans the target URLs and sends every finding as soon as its
+// source is done. The channel is closed once every target was scanned or
+// ctx is done; the caller must read it until then. Targets are filtered
+// like scan input, and one that -ext, -exclude-ext, -scope or -safe drops
+// is an error.
+func (s *Scanner) Stream(ctx context.Context, targets []string) (<-chan Finding, error) {
+	jobs, err := serveRequest{URLs: targets}.jobs(s.session.opts)
+	if err != nil {
+		return nil, err
+	}
+	findings := make(chan Finding)
+	go func() {
+		defer close(findings)
+		s.server.scan(ctx, jobs, func(src serveSource) {
+			for _, f := range src.Findings {
+				select {
+				case findings <- f:
+				case <-ctx.Done():
+					return
+				}
+			}
+		})
+	}()
+	return findings, nil
+}
+
+// Close stops the workers and releases the session. No Stream may be
+// running.
+func (s *Scanner) Close() {
+	s.server.close()
+	s.session.Close()
+}
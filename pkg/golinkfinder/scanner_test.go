@@ -0,0 +1,62 @@
+package golinkfinder
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"sync"
+	"testing"
+)
+
+func TestScannerStream(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/javascript")
+		fmt.Fprintf(w, "fetch('/api%s');", r.URL.Path)
+	}))
+	defer srv.Close()
+
+	// The host program's colors and writers must come through untouched.
+	var status, findings bytes.Buffer
+	defer func(colors Colors, s, f io.Writer) { c, statusOut, findingsOut = colors, s, f }(c, statusOut, findingsOut)
+	c, statusOut, findingsOut = themes["light"], &status, &findings
+
+	// Two Scanners streaming at once don't share state either.
+	var wg sync.WaitGroup
+	for i, args := range [][]string{{"-machine", "-no-color", "-t", "2"}, {"-theme", "dark", "-t", "3"}} {
+		s, err := NewScanner(args...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer s.Close()
+		targets := []string{fmt.Sprintf("%s/%d/a", srv.URL, i), fmt.Sprintf("%s/%d/b", srv.URL, i)}
+		ch, err := s.Stream(context.Background(), targets)
+		if err != nil {
+			t.Fatal(err)
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var got []string
+			for f := range ch {
+				got = append(got, f.Value)
+			}
+			sort.Strings(got)
+			want := []string{fmt.Sprintf("/api/%d/a", i), fmt.Sprintf("/api/%d/b", i)}
+			if fmt.Sprint(got) != fmt.Sprint(want) {
+				t.Errorf("scanner %d: got %v, want %v", i, got, want)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if c != themes["light"] || statusOut != &status || findingsOut != &findings {
+		t.Error("NewScanner changed the package's colors or writers")
+	}
+	if status.Len() > 0 || findings.Len() > 0 {
+		t.Errorf("Stream wrote %q and %q", status.String(), findings.String())
+	}
+}
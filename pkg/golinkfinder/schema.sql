@@ -0,0 +1,45 @@
+CREATE TABLE IF NOT EXISTS runs (
+	id          INTEGER PRIMARY KEY AUTOINCREMENT,
+	started_at  TEXT NOT NULL,
+	finished_at TEXT,
+	args        TEXT NOT NULL DEFAULT '',
+	targets     INTEGER NOT NULL DEFAULT 0,
+	failed      INTEGER NOT NULL DEFAULT 0,
+	endpoints   INTEGER NOT NULL DEFAULT 0,
+	scan_id     TEXT NOT NULL DEFAULT '',
+	version     TEXT NOT NULL DEFAULT '',
+	config      TEXT NOT NULL DEFAULT '{}',
+	labels      TEXT NOT NULL DEFAULT '{}'
+);
+
+CREATE TABLE IF NOT EXISTS sources (
+	id          INTEGER PRIMARY KEY AUTOINCREMENT,
+	url         TEXT NOT NULL UNIQUE,
+	first_seen  TEXT NOT NULL,
+	last_seen   TEXT NOT NULL,
+	last_run_id INTEGER REFERENCES runs(id),
+	last_error  TEXT NOT NULL DEFAULT '',
+	content_hash TEXT NOT NULL DEFAULT ''
+);
+
+CREATE TABLE IF NOT EXISTS endpoints (
+	id          INTEGER PRIMARY KEY AUTOINCREMENT,
+	value       TEXT NOT NULL,
+	category    TEXT NOT NULL,
+	first_seen  TEXT NOT NULL,
+	last_seen   TEXT NOT NULL,
+	UNIQUE (value, category)
+);
+
+CREATE TABLE IF NOT EXISTS endpoint_sources (
+	endpoint_id INTEGER NOT NULL REFERENCES endpoints(id),
+	source_id   INTEGER NOT NULL REFERENCES sources(id),
+	first_seen  TEXT NOT NULL,
+	last_seen   TEXT NOT NULL,
+	last_run_id INTEGER REFERENCES runs(id),
+	line        INTEGER NOT NULL DEFAULT 0,
+	offset      INTEGER NOT NULL DEFAULT 0,
+	PRIMARY KEY (endpoint_id, source_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_endpoint_sources_run ON endpoint_sources(last_run_id);
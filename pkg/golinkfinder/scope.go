@@ -0,0 +1,153 @@
+package golinkfinder
+
+import (
+	"bufio"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+)
+
+// hostScope is a -scope file: one hostname pattern per line, excludes
+// prefixed with "!", "#" comments.
+//
+//	*.example.com
+//	api.example.org
+//	!*.cdn.example.com
+//
+// "*.example.com" matches the subdomains of example.com, not example.com
+// itself, as in Burp. A URL's host is in scope when it matches an include
+// (or there are none) and no exclude.
+type hostScope struct {
+	include []string
+	exclude []string
+	// requestsOnly limits what is requested but keeps every finding, for
+	// the scope -safe derives from the input hosts.
+	requestsOnly bool
+
+	mu      sync.Mutex
+	dropped int
+}
+
+func loadHostScope(path string) (*hostScope, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not open scope file: %v", err)
+	}
+	defer file.Close()
+	scope := &hostScope{}
+	scanner := bufio.NewScanner(file)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		list := &scope.include
+		if strings.HasPrefix(line, "!") {
+			list = &scope.exclude
+			line = strings.TrimSpace(line[1:])
+		}
+		pattern, err := scopePattern(line)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %v", path, n, err)
+		}
+		*list = append(*list, pattern)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("could not read scope file: %v", err)
+	}
+	if len(scope.include) == 0 && len(scope.exclude) == 0 {
+		return nil, fmt.Errorf("scope file %s has no patterns", path)
+	}
+	return scope, nil
+}
+
+// scopePattern normalizes one pattern; full URLs are reduced to their
+// hostname.
+func scopePattern(line string) (string, error) {
+	if strings.Contains(line, "://") {
+		u, err := url.Parse(line)
+		if err != nil || u.Hostname() == "" {
+			return "", fmt.Errorf("invalid scope pattern '%s'", line)
+		}
+		line = u.Hostname()
+	}
+	line = strings.TrimSuffix(strings.ToLower(line), ".")
+	if strings.Contains(strings.TrimPrefix(line, "*."), "*") || strings.ContainsAny(line, "/ ") {
+		return "", fmt.Errorf("invalid scope pattern '%s' (use host, *.host or !pattern)", line)
+	}
+	return line, nil
+}
+
+func matchHost(pattern, host string) bool {
+	if strings.HasPrefix(pattern, "*.") {
+		return strings.HasSuffix(host, pattern[1:])
+	}
+	return host == pattern
+}
+
+// allowsHost reports whether host is in scope.
+func (scope *hostScope) allowsHost(host string) bool {
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	for _, p := range scope.exclude {
+		if matchHost(p, host) {
+			return false
+		}
+	}
+	if len(scope.include) == 0 {
+		return true
+	}
+	for _, p := range scope.include {
+		if matchHost(p, host) {
+			return true
+		}
+	}
+	return false
+}
+
+// allows reports whether a URL may be requested or reported. Values
+// without a host (relative paths, local files) are always allowed.
+func (scope *hostScope) allows(value string) bool {
+	if scope == nil {
+		return true
+	}
+	u, err := url.Parse(strings.TrimSpace(value))
+	if err != nil || u.Host == "" {
+		return true
+	}
+	return scope.allowsHost(u.Hostname())
+}
+
+// filter drops the URL findings pointing at out-of-scope hosts.
+func (scope *hostScope) filter(findings []Finding) []Finding {
+	if scope.requestsOnly {
+		return findings
+	}
+	kept := findings[:0]
+	dropped := 0
+	for _, f := range findings {
+		if (resolvableCategory(f.Category) || suppressibleCategory(f.Category)) && !scope.allows(f.Value) {
+			dropped++
+			continue
+		}
+		kept = append(kept, f)
+	}
+	if dropped > 0 {
+		scope.mu.Lock()
+		scope.dropped += dropped
+		scope.mu.Unlock()
+	}
+	return kept
+}
+
+// filterJobs drops the out-of-scope targets.
+func (scope *hostScope) filterJobs(jobs []scanJob) ([]scanJob, int) {
+	kept := make([]scanJob, 0, len(jobs))
+	for _, job := range jobs {
+		if scope.allows(job.url) {
+			kept = append(kept, job)
+		}
+	}
+	return kept, len(jobs) - len(kept)
+}
@@ -0,0 +1,54 @@
+package golinkfinder
+
+import "testing"
+
+func TestMatchHost(t *testing.T) {
+	tests := []struct {
+		pattern, host string
+		want          bool
+	}{
+		{"example.com", "example.com", true},
+		{"example.com", "api.example.com", false},
+		{"*.example.com", "api.example.com", true},
+		{"*.example.com", "a.b.example.com", true},
+		{"*.example.com", "example.com", false},
+		{"*.example.com", "badexample.com", false},
+		{"*.example.com", "example.com.evil.net", false},
+	}
+	for _, tt := range tests {
+		if got := matchHost(tt.pattern, tt.host); got != tt.want {
+			t.Errorf("matchHost(%q, %q) = %v, want %v", tt.pattern, tt.host, got, tt.want)
+		}
+	}
+}
+
+func TestHostScopeAllows(t *testing.T) {
+	scope := &hostScope{include: []string{"*.example.com", "api.example.org"}, exclude: []string{"*.cdn.example.com"}}
+	tests := []struct {
+		value string
+		want  bool
+	}{
+		{"https://www.example.com/app.js", true},
+		{"https://WWW.Example.COM./app.js", true},
+		{"https://api.example.org:8443/v1", true},
+		{"https://example.com/", false},
+		{"https://static.cdn.example.com/app.js", false},
+		{"https://other.org/", false},
+		{"/api/v1/users", true},
+		{"file:///tmp/app.js", true},
+	}
+	for _, tt := range tests {
+		if got := scope.allows(tt.value); got != tt.want {
+			t.Errorf("allows(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+
+	excludeOnly := &hostScope{exclude: []string{"internal.example.com"}}
+	if !excludeOnly.allows("https://www.example.com/") || excludeOnly.allows("https://internal.example.com/") {
+		t.Error("a scope with only excludes should allow every other host")
+	}
+	var none *hostScope
+	if !none.allows("https://anything.example/") {
+		t.Error("a nil scope should allow everything")
+	}
+}
@@ -0,0 +1,314 @@
+package golinkfinder
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+const categorySecret = "secret"
+
+type secretKind struct {
+	name     string
+	severity string
+	re       *regexp.Regexp
+	// verify reports whether the secret is live, using a read-only call to
+	// the provider. It is nil for kinds that can't be checked benignly.
+	verify func(ctx context.Context, client *http.Client, value string) (bool, error)
+}
+
+// verifyTimeout bounds each -verify-secrets call.
+const verifyTimeout = 10 * time.Second
+
+// newVerifyClient returns the client provider calls are made with. Live
+// credentials only go out with certificate checks, so it shares none of the
+// scan client's dialers, TLS settings or request rewriting.
+func newVerifyClient() *http.Client {
+	return &http.Client{Timeout: verifyTimeout}
+}
+
+var secretKinds = []secretKind{
+	{name: "github-token", severity: "high", re: regexp.MustCompile(`\b(?:gh[pousr]_[A-Za-z0-9]{36}|github_pat_[A-Za-z0-9_]{82})\b`), verify: verifyGitHub},
+	{name: "slack-token", severity: "high", re: regexp.MustCompile(`\bxox[baprs]-[A-Za-z0-9\-]{10,}\b`), verify: verifySlackToken},
+	{name: "slack-webhook", severity: "medium", re: regexp.MustCompile(`https://hooks\.slack\.com/services/T[A-Z0-9]+/B[A-Z0-9]+/[A-Za-z0-9]+`), verify: verifySlackWebhook},
+	{name: "aws-access-key", severity: "high", re: regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`), verify: verifyAWS},
+	{name: "stripe-live-key", severity: "high", re: regexp.MustCompile(`\b[rs]k_live_[A-Za-z0-9]{24,}\b`), verify: verifyStripe},
+	{name: "google-api-key", severity: "medium", re: regexp.MustCompile(`\bAIza[0-9A-Za-z_\-]{35}\b`)},
+}
+
+// awsSecretRegex finds the secret access key that usually sits next to an
+// access key ID in the same object or config block.
+var awsSecretRegex = regexp.MustCompile(`["'=:\s]([A-Za-z0-9/+]{40})["'\s,;]`)
+
+const awsSecretWindow = 300
+
+// secretKindOf returns the kind of a reported secret. Only AWS findings
+// are "ID:secret" pairs, matched by their ID; other values, such as Slack
+// webhook URLs, contain colons of their own.
+func secretKindOf(value string) *secretKind {
+	for i := range secretKinds {
+		v := value
+		if secretKinds[i].name == "aws-access-key" {
+			v = strings.SplitN(value, ":", 2)[0]
+		}
+		if secretKinds[i].re.MatchString(v) {
+			return &secretKinds[i]
+		}
+	}
+	return nil
+}
+
+// secretExtractor reports credentials in the secret category. AWS access
+// key IDs are reported as "ID:secret" when a secret key appears nearby,
+// since the ID alone can neither be used nor verified.
+type secretExtractor struct{}
+
+func (secretExtractor) Extract(source, contentType string, body []byte) []Finding {
+	content := string(body)
+	lines := newLineIndex(content)
+	seen := make(map[string]struct{})
+	findings := make([]Finding, 0)
+	for _, kind := range secretKinds {
+		for _, loc := range findAllSubmatchIndex(kind.re, content) {
+			value := content[loc[0]:loc[1]]
+			if kind.name == "aws-access-key" {
+				start, end := loc[0]-awsSecretWindow, loc[1]+awsSecretWindow
+				if start < 0 {
+					start = 0
+				}
+				if end > len(content) {
+					end = len(content)
+				}
+				if m := awsSecretRegex.FindStringSubmatch(content[start:end]); m != nil {
+					value += ":" + m[1]
+				}
+			}
+			if _, ok := seen[value]; ok {
+				continue
+			}
+			seen[value] = struct{}{}
+			findings = append(findings, Finding{Source: source, Value: value, Category: categorySecret, Line: lines.line(loc[0]), Offset: loc[0]})
+		}
+	}
+	return findings
+}
+
+// describeSecret returns the note printed next to a secret: its kind,
+// severity and, when verified, whether it is still active. Active secrets
+// are raised to critical.
+func describeSecret(ctx context.Context, client *http.Client, value string, verify bool) string {
+	kind := secretKindOf(value)
+	if kind == nil {
+		return ""
+	}
+	if !verify {
+		return kind.name + ", " + kind.severity
+	}
+	if kind.verify == nil {
+		return kind.name + ", " + kind.severity + ", unverified"
+	}
+	active, err := kind.verify(ctx, client, value)
+	switch {
+	case err != nil:
+		return fmt.Sprintf("%s, %s, unverified: %v", kind.name, kind.severity, err)
+	case active:
+		return kind.name + ", critical, active"
+	default:
+		return kind.name + ", " + kind.severity + ", inactive"
+	}
+}
+
+// statusVerdict maps a provider's answer to active (2xx) or inactive
+// (401/403); anything else leaves the secret unverified.
+func statusVerdict(resp *http.Response, err error) (bool, error) {
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return true, nil
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		return false, nil
+	}
+	return false, &statusError{code: resp.StatusCode}
+}
+
+func verifyGitHub(ctx context.Context, client *http.Client, token string) (bool, error) {
+	req, _ := http.NewRequestWithContext(ctx, "GET", "https://api.github.com/user", nil)
+	req.Header.Set("Authorization", "token "+token)
+	req.Header.Set("User-Agent", userAgent)
+	return statusVerdict(client.Do(req))
+}
+
+func verifyStripe(ctx context.Context, client *http.Client, key string) (bool, error) {
+	req, _ := http.NewRequestWithContext(ctx, "GET", "https://api.stripe.com/v1/balance", nil)
+	req.SetBasicAuth(key, "")
+	return statusVerdict(client.Do(req))
+}
+
+func verifySlackToken(ctx context.Context, client *http.Client, token string) (bool, error) {
+	req, _ := http.NewRequestWithContext(ctx, "POST", "https://slack.com/api/auth.test", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := client.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+	var answer struct {
+		OK bool `json:"ok"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&answer); err != nil {
+		return false, fmt.Errorf("could not decode slack response: %v", err)
+	}
+	return answer.OK, nil
+}
+
+// verifySlackWebhook posts an empty payload, which Slack rejects without
+// posting anything: "invalid_payload" or "no_text" means the hook exists.
+func verifySlackWebhook(ctx context.Context, client *http.Client, hook string) (bool, error) {
+	req, _ := http.NewRequestWithContext(ctx, "POST", hook, strings.NewReader("{}"))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := client.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	switch answer := strings.TrimSpace(string(body)); {
+	case answer == "invalid_payload" || answer == "no_text":
+		return true, nil
+	case resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusForbidden || resp.StatusCode == http.StatusGone:
+		return false, nil
+	}
+	return false, &statusError{code: resp.StatusCode}
+}
+
+// verifyAWS calls STS GetCallerIdentity, which needs no permissions and
+// succeeds for any valid key pair.
+func verifyAWS(ctx context.Context, client *http.Client, value string) (bool, error) {
+	parts := strings.SplitN(value, ":", 2)
+	if len(parts) != 2 {
+		return false, fmt.Errorf("no secret access key found next to the key ID")
+	}
+	body := "Action=GetCallerIdentity&Version=2011-06-15"
+	req, _ := http.NewRequestWithContext(ctx, "POST", "https://sts.amazonaws.com/", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded; charset=utf-8")
+	signV4(req, []byte(body), parts[0], parts[1], "", "us-east-1", "sts", time.Now().UTC())
+	return statusVerdict(client.Do(req))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// signV4 adds AWS Signature Version 4 headers to req, signing the host,
+// the content type when there is one and every X-Amz- header, including
+// the session token of temporary credentials.
+func signV4(req *http.Request, body []byte, accessKey, secretKey, sessionToken, region, service string, now time.Time) {
+	amzDate := now.Format("20060102T150405Z")
+	day := now.Format("20060102")
+	payloadHash := sha256.Sum256(body)
+	req.Header.Set("X-Amz-Date", amzDate)
+	if service == "s3" {
+		req.Header.Set("X-Amz-Content-Sha256", hex.EncodeToString(payloadHash[:]))
+	}
+	if sessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", sessionToken)
+	}
+
+	host := req.Host
+	if host == "" {
+		host = req.URL.Host
+	}
+	headers := map[string]string{"host": host}
+	for name, values := range req.Header {
+		lower := strings.ToLower(name)
+		if lower == "content-type" || strings.HasPrefix(lower, "x-amz-") {
+			headers[lower] = strings.TrimSpace(strings.Join(values, ","))
+		}
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+	canonical := strings.Join([]string{
+		req.Method,
+		canonicalURI(req.URL, service),
+		canonicalQuery(req.URL.Query()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		hex.EncodeToString(payloadHash[:]),
+	}, "\n")
+	canonicalHash := sha256.Sum256([]byte(canonical))
+	scope := day + "/" + region + "/" + service + "/aws4_request"
+	toSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(canonicalHash[:])
+
+	key := hmacSHA256([]byte("AWS4"+secretKey), day)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, service)
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, toSign))
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s", accessKey, scope, signedHeaders, signature))
+}
+
+// canonicalURI is the path as SigV4 signs it: S3 takes the escaped path
+// as is, every other service URI-encodes each of its segments again.
+func canonicalURI(u *url.URL, service string) string {
+	path := u.EscapedPath()
+	if path == "" {
+		return "/"
+	}
+	if service == "s3" {
+		return path
+	}
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		segments[i] = awsEscape(segment)
+	}
+	return strings.Join(segments, "/")
+}
+
+// canonicalQuery sorts and strictly encodes query parameters for SigV4.
+func canonicalQuery(query url.Values) string {
+	var pairs [][2]string
+	for key, values := range query {
+		for _, value := range values {
+			pairs = append(pairs, [2]string{awsEscape(key), awsEscape(value)})
+		}
+	}
+	sort.Slice(pairs, func(i, j int) bool {
+		if pairs[i][0] != pairs[j][0] {
+			return pairs[i][0] < pairs[j][0]
+		}
+		return pairs[i][1] < pairs[j][1]
+	})
+	encoded := make([]string, len(pairs))
+	for i, p := range pairs {
+		encoded[i] = p[0] + "=" + p[1]
+	}
+	return strings.Join(encoded, "&")
+}
+
+func awsEscape(s string) string {
+	return strings.ReplaceAll(url.QueryEscape(s), "+", "%20")
+}
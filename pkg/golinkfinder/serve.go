@@ -0,0 +1,417 @@
+package golinkfinder
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// serveRequest is the body of POST /scan: URLs or targets to fetch, and/or
+// content to extract from directly. Stream sends every source as soon as
+// it is done instead of all of them at the end.
+type serveRequest struct {
+	URLs        []string     `json:"urls"`
+	Targets     []jsonTarget `json:"targets"`
+	Content     string       `json:"content"`
+	Source      string       `json:"source"`
+	ContentType string       `json:"content_type"`
+	Stream      bool         `json:"stream"`
+}
+
+type serveSource struct {
+	Source      string    `json:"source"`
+	Error       string    `json:"error,omitempty"`
+	ErrorClass  string    `json:"error_class,omitempty"`
+	Retryable   bool      `json:"retryable,omitempty"`
+	Status      int       `json:"status,omitempty"`
+	ContentType string    `json:"content_type,omitempty"`
+	Bytes       int64     `json:"bytes"`
+	Millis      int64     `json:"duration_ms"`
+	Findings    []Finding `json:"findings"`
+}
+
+type serveScan struct {
+	ID       string        `json:"id"`
+	Started  time.Time     `json:"started"`
+	Duration string        `json:"duration"`
+	Targets  int           `json:"targets"`
+	Failed   int           `json:"failed"`
+	Findings int           `json:"findings"`
+	Sources  []serveSource `json:"sources,omitempty"`
+}
+
+type serveTask struct {
+	ctx   context.Context
+	job   scanJob
+	reply chan<- linkFinderResult
+}
+
+// scanServer runs one scan session for every API request, so the HTTP
+// client, extractors, plugins and rate limit are shared, and feeds a
+// fixed pool of workers.
+type scanServer struct {
+	session *scanSession
+	token   string
+	// port is set when listening on loopback; requests must then name a
+	// loopback host and this port.
+	port    string
+	maxBody int64
+	keep    int
+	tasks   chan serveTask
+
+	mu    sync.Mutex
+	scans map[string]*serveScan
+	order []string
+}
+
+func newScanServer(s *scanSession, token string, maxBody int64, keep int) *scanServer {
+	srv := &scanServer{session: s, token: token, maxBody: maxBody, keep: keep, tasks: make(chan serveTask), scans: make(map[string]*serveScan)}
+	for i := 0; i < s.opts.threads; i++ {
+		go func() {
+			first := true
+			for task := range srv.tasks {
+				reply := task.reply
+				s.handle(task.ctx, task.job, &first, func(res linkFinderResult) { reply <- res })
+			}
+		}()
+	}
+	return srv
+}
+
+// close stops the workers once no scan is running.
+func (srv *scanServer) close() {
+	close(srv.tasks)
+}
+
+func (srv *scanServer) authorized(r *http.Request) bool {
+	if srv.token == "" {
+		return true
+	}
+	given := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return subtle.ConstantTimeCompare([]byte(given), []byte(srv.token)) == 1
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeJSONError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
+
+func (srv *scanServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	// Without this check any page in the operator's browser could post a
+	// scan, or read results through DNS rebinding.
+	if srv.port != "" && !loopbackHost(r.Host, srv.port) {
+		writeJSONError(w, http.StatusForbidden, fmt.Errorf("forbidden host %q", r.Host))
+		return
+	}
+	if !srv.authorized(r) {
+		writeJSONError(w, http.StatusUnauthorized, fmt.Errorf("missing or invalid bearer token"))
+		return
+	}
+	switch {
+	case r.URL.Path == "/scan" && r.Method == http.MethodPost:
+		srv.handleScan(w, r)
+	case r.URL.Path == "/results" && r.Method == http.MethodGet:
+		srv.handleResults(w, r)
+	case r.URL.Path == "/metrics" && r.Method == http.MethodGet:
+		srv.session.metrics.ServeHTTP(w, r)
+	default:
+		writeJSONError(w, http.StatusNotFound, fmt.Errorf("unknown endpoint %s %s", r.Method, r.URL.Path))
+	}
+}
+
+// jobs returns the targets of req, filtered and canonicalized like the
+// input of a scan. A target -ext, -exclude-ext, -scope or -safe drops is
+// an error rather than silently skipped.
+func (req serveRequest) jobs(o *scanOptions) ([]scanJob, error) {
+	targets := make([]scanJob, 0, len(req.URLs)+len(req.Targets))
+	for _, u := range req.URLs {
+		targets = append(targets, scanJob{url: u})
+	}
+	for _, t := range req.Targets {
+		targets = append(targets, t.job())
+	}
+	jobs := make([]scanJob, 0, len(targets)+1)
+	for _, job := range targets {
+		if u, err := url.Parse(job.url); err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+			return nil, fmt.Errorf("invalid target URL %q", job.url)
+		}
+		prepared := prepareTargets(o, []scanJob{job})
+		if len(prepared) == 0 {
+			return nil, fmt.Errorf("target %q is excluded by -ext, -exclude-ext, -scope or -safe", job.url)
+		}
+		jobs = append(jobs, prepared[0])
+	}
+	jobs, _ = dedupJobs(jobs)
+	if req.Content != "" {
+		source := req.Source
+		if source == "" {
+			source = "request"
+		}
+		jobs = append(jobs, scanJob{url: source, contentType: req.ContentType, body: []byte(req.Content)})
+	}
+	if len(jobs) == 0 {
+		return nil, fmt.Errorf("nothing to scan: give urls, targets or content")
+	}
+	return jobs, nil
+}
+
+func (srv *scanServer) handleScan(w http.ResponseWriter, r *http.Request) {
+	// Browsers only send JSON cross-origin after a CORS preflight, which is
+	// never answered.
+	if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType != "application/json" {
+		writeJSONError(w, http.StatusUnsupportedMediaType, fmt.Errorf("request body must be application/json"))
+		return
+	}
+	var req serveRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, srv.maxBody)).Decode(&req); err != nil {
+		writeJSONError(w, http.StatusBadRequest, fmt.Errorf("invalid request body: %v", err))
+		return
+	}
+	jobs, err := req.jobs(srv.session.opts)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	// A client that hangs up cancels its scan.
+	if !req.Stream {
+		scan := srv.scan(r.Context(), jobs, nil)
+		srv.store(scan)
+		writeJSON(w, http.StatusOK, scan)
+		return
+	}
+
+	// Streamed answers are JSON lines: one per source, then the summary
+	// without the sources.
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	flusher, _ := w.(http.Flusher)
+	enc := json.NewEncoder(w)
+	scan := srv.scan(r.Context(), jobs, func(src serveSource) {
+		enc.Encode(src)
+		if flusher != nil {
+			flusher.Flush()
+		}
+	})
+	srv.store(scan)
+	summary := *scan
+	summary.Sources = nil
+	enc.Encode(summary)
+}
+
+// scan runs jobs on the worker pool and collects every source's findings,
+// within -scan-timeout. Jobs not started when ctx is done fail with its
+// error. each, if not nil, is called with every source as it completes.
+func (srv *scanServer) scan(ctx context.Context, jobs []scanJob, each func(serveSource)) *serveScan {
+	ctx, cancel := withTimeout(ctx, srv.session.opts.scanTimeout)
+	defer cancel()
+	ctx = withTrust(ctx, jobs)
+	scan := &serveScan{ID: newScanID(), Started: time.Now().UTC(), Targets: len(jobs)}
+	// Each API scan is a run of -db, so its sources are tied to one.
+	var runID int64
+	if rdb := srv.session.rdb; rdb != nil {
+		st := newScanStats(srv.session.opts.config)
+		st.ScanID, st.Labels = scan.ID, srv.session.opts.labels
+		srv.mu.Lock()
+		id, err := rdb.startRun(st, os.Args[1:], len(jobs))
+		srv.mu.Unlock()
+		if err != nil {
+			srv.session.reportError(err)
+		}
+		runID = id
+	}
+	replies := make(chan linkFinderResult, len(jobs))
+	srv.session.metrics.queue(len(jobs))
+	go func() {
+		for _, job := range jobs {
+			select {
+			case srv.tasks <- serveTask{ctx: ctx, job: job, reply: replies}:
+				srv.session.metrics.queue(-1)
+			case <-ctx.Done():
+				srv.session.metrics.queue(-1)
+				replies <- linkFinderResult{job: job, sourceURL: job.url, meta: sourceMeta{URL: job.url}, err: ctx.Err()}
+			}
+		}
+	}()
+	for range jobs {
+		src := srv.source(<-replies, runID)
+		if each != nil {
+			each(src)
+		}
+		scan.Sources = append(scan.Sources, src)
+	}
+	for _, src := range scan.Sources {
+		if src.Error != "" {
+			scan.Failed++
+		}
+		scan.Findings += len(src.Findings)
+	}
+	scan.Duration = time.Since(scan.Started).Round(time.Millisecond).String()
+	if rdb := srv.session.rdb; rdb != nil && runID != 0 {
+		srv.mu.Lock()
+		err := rdb.finishRun(runID, scan.Targets, scan.Failed, scan.Findings)
+		srv.mu.Unlock()
+		if err != nil {
+			srv.session.reportError(err)
+		}
+	}
+	srv.session.metrics.scanDone()
+	return scan
+}
+
+// source turns a worker result into its API form, resolving values the
+// way the scan command does and recording it in -db.
+func (srv *scanServer) source(res linkFinderResult, runID int64) serveSource {
+	o := srv.session.opts
+	srv.session.metrics.observe(res)
+	src := serveSource{Source: res.sourceURL, Status: res.meta.Status, ContentType: res.meta.ContentType, Bytes: res.meta.Bytes, Millis: res.meta.Duration.Milliseconds(), Findings: make([]Finding, 0, len(res.findings))}
+	if res.err != nil {
+		src.Error = res.err.Error()
+		src.ErrorClass = errorClass(res.err)
+		src.Retryable = retryableClasses[src.ErrorClass]
+	}
+	baseURL, _ := url.Parse(res.sourceURL)
+	for _, f := range res.findings {
+		if o.resolve && resolvableCategory(f.Category) {
+			f.Value = resolveAgainst(baseURL, f.Value, false)
+		}
+		src.Findings = append(src.Findings, f)
+	}
+	if rdb := srv.session.rdb; rdb != nil {
+		srv.mu.Lock()
+		err := rdb.recordSource(runID, res.sourceURL, res.meta.Hash, res.err, src.Findings)
+		srv.mu.Unlock()
+		if err != nil {
+			srv.session.reportError(err)
+		}
+	}
+	return src
+}
+
+// store keeps scan for GET /results, dropping the oldest beyond -keep.
+func (srv *scanServer) store(scan *serveScan) {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	srv.scans[scan.ID] = scan
+	srv.order = append(srv.order, scan.ID)
+	for len(srv.order) > srv.keep {
+		delete(srv.scans, srv.order[0])
+		srv.order = srv.order[1:]
+	}
+}
+
+// handleResults returns one scan with ?id=, or a summary of every kept
+// scan, newest first.
+func (srv *scanServer) handleResults(w http.ResponseWriter, r *http.Request) {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	if id := r.URL.Query().Get("id"); id != "" {
+		scan, ok := srv.scans[id]
+		if !ok {
+			writeJSONError(w, http.StatusNotFound, fmt.Errorf("no scan with id %q", id))
+			return
+		}
+		writeJSON(w, http.StatusOK, scan)
+		return
+	}
+	summaries := make([]serveScan, 0, len(srv.order))
+	for i := len(srv.order) - 1; i >= 0; i-- {
+		summary := *srv.scans[srv.order[i]]
+		summary.Sources = nil
+		summaries = append(summaries, summary)
+	}
+	writeJSON(w, http.StatusOK, summaries)
+}
+
+func loopbackAddr(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// loopbackHost reports whether a request's Host names a loopback address
+// or localhost on port, so pages on other origins can't reach a local
+// listener through DNS rebinding.
+func loopbackHost(hostport, port string) bool {
+	host, p, err := net.SplitHostPort(hostport)
+	if err != nil {
+		host, p = hostport, ""
+	}
+	if p != "" && p != port {
+		return false
+	}
+	return loopbackAddr(net.JoinHostPort(strings.Trim(host, "[]"), port))
+}
+
+func runServe(args []string) {
+	o := defaultScanOptions()
+	var listen, token string
+	var maxBody int64
+	var keep int
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	addScanFlags(fs, &o)
+	fs.StringVar(&listen, "listen", "127.0.0.1:8080", "Address to serve the API on.")
+	fs.Var((*secretString)(&token), "token", "Bearer token required on every request (default $GOLINKFINDER_TOKEN).")
+	fs.Int64Var(&maxBody, "max-body", 32<<20, "Largest accepted POST /scan body in bytes.")
+	fs.IntVar(&keep, "keep", 100, "Number of recent scans kept for GET /results.")
+	err := parseScanFlags(fs, args, &o)
+	initOutput(&o)
+	if err != nil {
+		fatal(err)
+	}
+	if token == "" {
+		token = os.Getenv("GOLINKFINDER_TOKEN")
+	}
+	if err := checkAPISafe(&o); err != nil {
+		fatal(err)
+	}
+	if token == "" && !loopbackAddr(listen) {
+		fatal(fmt.Errorf("refusing to serve on %s without -token", listen))
+	}
+	if keep < 1 {
+		fatal(fmt.Errorf("-keep must be at least 1"))
+	}
+	o.quiet = true
+	o.metrics = true
+
+	s, err := newScanSession(&o, cliOutput())
+	if err != nil {
+		fatal(err)
+	}
+	defer s.Close()
+	srv := newScanServer(s, token, maxBody, keep)
+	listener, err := net.Listen("tcp", listen)
+	if err != nil {
+		fatal(err)
+	}
+	if loopbackAddr(listen) {
+		_, srv.port, _ = net.SplitHostPort(listener.Addr().String())
+	}
+	fmt.Fprintf(statusOut, "%s[*] Serving the API on http://%s with %d workers (POST /scan, GET /results, GET /metrics)%s\n", c.Yellow, listener.Addr(), o.threads, c.End)
+	// Scans can stream for as long as -scan-timeout allows, so only reading
+	// the request is bounded.
+	server := &http.Server{Handler: srv, ReadHeaderTimeout: 10 * time.Second, ReadTimeout: time.Minute, IdleTimeout: 2 * time.Minute}
+	if err := server.Serve(listener); err != nil {
+		fatal(err)
+	}
+}
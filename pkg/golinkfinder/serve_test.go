@@ -0,0 +1,195 @@
+package golinkfinder
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// newTestAPI serves the API on loopback like the serve command, with
+// the given scan flags and token, in front of a target site.
+func newTestAPI(t *testing.T, token string, args ...string) (api, site *httptest.Server) {
+	t.Helper()
+	site = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/javascript")
+		fmt.Fprintf(w, "fetch('/api%s');", r.URL.Path)
+	}))
+	t.Cleanup(site.Close)
+
+	o := defaultScanOptions()
+	fs := flag.NewFlagSet("serve", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	addScanFlags(fs, &o)
+	if err := parseScanFlags(fs, args, &o); err != nil {
+		t.Fatal(err)
+	}
+	o.quiet, o.metrics = true, true
+	s, err := newScanSession(&o, &output{findings: io.Discard, status: io.Discard, errs: io.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := newScanServer(s, token, 1<<20, 2)
+	api = httptest.NewServer(srv)
+	_, srv.port, _ = net.SplitHostPort(api.Listener.Addr().String())
+	t.Cleanup(func() {
+		api.Close()
+		srv.close()
+		s.Close()
+	})
+	return api, site
+}
+
+func postScan(t *testing.T, api *httptest.Server, token string, body interface{}) *http.Response {
+	t.Helper()
+	data, _ := json.Marshal(body)
+	req, _ := http.NewRequest(http.MethodPost, api.URL+"/scan", strings.NewReader(string(data)))
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := api.Client().Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { resp.Body.Close() })
+	return resp
+}
+
+func sourceValues(src serveSource) []string {
+	var values []string
+	for _, f := range src.Findings {
+		values = append(values, f.Value)
+	}
+	return values
+}
+
+func TestServeScan(t *testing.T) {
+	api, site := newTestAPI(t, "secret")
+	resp := postScan(t, api, "secret", serveRequest{
+		URLs:    []string{site.URL + "/a.js", site.URL + "/b.js"},
+		Content: "fetch('/inline');",
+	})
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("POST /scan answered %s", resp.Status)
+	}
+	var scan serveScan
+	if err := json.NewDecoder(resp.Body).Decode(&scan); err != nil {
+		t.Fatal(err)
+	}
+	if scan.Targets != 3 || scan.Failed != 0 || scan.Findings != 3 {
+		t.Errorf("got %d targets, %d failed, %d findings; want 3, 0, 3", scan.Targets, scan.Failed, scan.Findings)
+	}
+	got := map[string]string{}
+	for _, src := range scan.Sources {
+		got[src.Source] = strings.Join(sourceValues(src), " ")
+	}
+	want := map[string]string{
+		site.URL + "/a.js": "/api/a.js",
+		site.URL + "/b.js": "/api/b.js",
+		"request":          "/inline",
+	}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("got sources %v, want %v", got, want)
+	}
+
+	// The scan is kept for GET /results.
+	req, _ := http.NewRequest(http.MethodGet, api.URL+"/results?id="+scan.ID, nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	res, err := api.Client().Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	var kept serveScan
+	if err := json.NewDecoder(res.Body).Decode(&kept); err != nil {
+		t.Fatal(err)
+	}
+	if kept.ID != scan.ID || len(kept.Sources) != 3 {
+		t.Errorf("GET /results returned scan %q with %d sources", kept.ID, len(kept.Sources))
+	}
+}
+
+func TestServeStream(t *testing.T) {
+	api, site := newTestAPI(t, "")
+	resp := postScan(t, api, "", serveRequest{URLs: []string{site.URL + "/a.js", site.URL + "/b.js"}, Stream: true})
+	if ct := resp.Header.Get("Content-Type"); ct != "application/x-ndjson" {
+		t.Errorf("streamed answer has Content-Type %q", ct)
+	}
+	var lines []string
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	if len(lines) != 3 {
+		t.Fatalf("got %d lines, want a source per target and the summary: %q", len(lines), lines)
+	}
+	var sources []string
+	for _, line := range lines[:2] {
+		var src serveSource
+		if err := json.Unmarshal([]byte(line), &src); err != nil {
+			t.Fatal(err)
+		}
+		sources = append(sources, src.Source)
+	}
+	sort.Strings(sources)
+	if want := []string{site.URL + "/a.js", site.URL + "/b.js"}; fmt.Sprint(sources) != fmt.Sprint(want) {
+		t.Errorf("streamed sources %v, want %v", sources, want)
+	}
+	var summary serveScan
+	if err := json.Unmarshal([]byte(lines[2]), &summary); err != nil {
+		t.Fatal(err)
+	}
+	if summary.Findings != 2 || summary.Sources != nil {
+		t.Errorf("summary has %d findings and sources %v", summary.Findings, summary.Sources)
+	}
+}
+
+func TestServeRejects(t *testing.T) {
+	api, site := newTestAPI(t, "secret", "-ext", "js")
+	send := func(host, token, contentType, body string) int {
+		req, _ := http.NewRequest(http.MethodPost, api.URL+"/scan", strings.NewReader(body))
+		if host != "" {
+			req.Host = host
+		}
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		req.Header.Set("Content-Type", contentType)
+		resp, err := api.Client().Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+	target := fmt.Sprintf(`{"urls":[%q]}`, site.URL+"/a.js")
+	tests := []struct {
+		name            string
+		host, token, ct string
+		body            string
+		want            int
+	}{
+		{"rebound host", "evil.example:80", "secret", "application/json", target, http.StatusForbidden},
+		{"no token", "", "", "application/json", target, http.StatusUnauthorized},
+		{"wrong token", "", "guess", "application/json", target, http.StatusUnauthorized},
+		{"form post", "", "secret", "text/plain", target, http.StatusUnsupportedMediaType},
+		{"bad JSON", "", "secret", "application/json", "{", http.StatusBadRequest},
+		{"not HTTP", "", "secret", "application/json", `{"urls":["file:///etc/passwd"]}`, http.StatusBadRequest},
+		{"dropped by -ext", "", "secret", "application/json", fmt.Sprintf(`{"urls":[%q]}`, site.URL+"/page.html"), http.StatusBadRequest},
+		{"nothing to scan", "", "secret", "application/json", `{}`, http.StatusBadRequest},
+		{"allowed", "", "secret", "application/json", target, http.StatusOK},
+	}
+	for _, tt := range tests {
+		if got := send(tt.host, tt.token, tt.ct, tt.body); got != tt.want {
+			t.Errorf("%s: got status %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,160 @@
+package golinkfinder
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// requestSigner signs the requests sent to the target hosts: with AWS
+// SigV4 for -aws-sigv4 service:region, using the standard AWS_ACCESS_KEY_ID,
+// AWS_SECRET_ACCESS_KEY and AWS_SESSION_TOKEN variables, and with the
+// -sign-header templates, e.g.
+//
+//	-sign-header 'X-Signature: {{hmacSHA256 (env "API_SECRET") (print .Method "\n" .Path "\n" .Timestamp)}}'
+//
+// Templates see the request's Method, URL, Host, Path, Query, BodySHA256,
+// Timestamp (Unix seconds), Date (RFC 3339, UTC) and a random Nonce, and
+// can call env, sha256, hmacSHA256 (hex), hmacSHA256Base64 and base64.
+type requestSigner struct {
+	service, region                    string
+	accessKey, secretKey, sessionToken string
+	headers                            []signedHeader
+}
+
+type signedHeader struct {
+	name string
+	tmpl *template.Template
+}
+
+type signingRequest struct {
+	Method, URL, Host, Path, Query string
+	BodySHA256, Date, Nonce        string
+	Timestamp                      int64
+}
+
+var signingFuncs = template.FuncMap{
+	"env": os.Getenv,
+	"sha256": func(data string) string {
+		sum := sha256.Sum256([]byte(data))
+		return hex.EncodeToString(sum[:])
+	},
+	"hmacSHA256": func(key, data string) string {
+		return hex.EncodeToString(hmacSHA256([]byte(key), data))
+	},
+	"hmacSHA256Base64": func(key, data string) string {
+		return base64.StdEncoding.EncodeToString(hmacSHA256([]byte(key), data))
+	},
+	"base64": func(data string) string {
+		return base64.StdEncoding.EncodeToString([]byte(data))
+	},
+}
+
+func newRequestSigner(sigv4 string, headers []string) (*requestSigner, error) {
+	if sigv4 == "" && len(headers) == 0 {
+		return nil, nil
+	}
+	rs := &requestSigner{}
+	if sigv4 != "" {
+		parts := strings.SplitN(sigv4, ":", 2)
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return nil, fmt.Errorf("-aws-sigv4 must be service:region, e.g. s3:us-east-1")
+		}
+		rs.service, rs.region = parts[0], parts[1]
+		rs.accessKey, rs.secretKey = os.Getenv("AWS_ACCESS_KEY_ID"), os.Getenv("AWS_SECRET_ACCESS_KEY")
+		rs.sessionToken = os.Getenv("AWS_SESSION_TOKEN")
+		if rs.accessKey == "" || rs.secretKey == "" {
+			return nil, fmt.Errorf("-aws-sigv4 needs AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY")
+		}
+	}
+	for _, header := range headers {
+		name, text, ok := strings.Cut(header, ":")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" {
+			return nil, fmt.Errorf("-sign-header must be 'Name: template', got '%s'", header)
+		}
+		t, err := template.New(name).Option("missingkey=error").Funcs(signingFuncs).Parse(strings.TrimSpace(text))
+		if err != nil {
+			return nil, fmt.Errorf("invalid -sign-header template for %s: %v", name, err)
+		}
+		rs.headers = append(rs.headers, signedHeader{name: name, tmpl: t})
+	}
+	return rs, nil
+}
+
+// sign signs req, which the caller owns, in place.
+func (rs *requestSigner) sign(req *http.Request) error {
+	var body []byte
+	if req.GetBody != nil {
+		rc, err := req.GetBody()
+		if err != nil {
+			return err
+		}
+		body, err = io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			return err
+		}
+	}
+	now := time.Now().UTC()
+	if len(rs.headers) > 0 {
+		sum := sha256.Sum256(body)
+		nonce := make([]byte, 16)
+		rand.Read(nonce)
+		host := req.Host
+		if host == "" {
+			host = req.URL.Host
+		}
+		data := signingRequest{
+			Method:     req.Method,
+			URL:        req.URL.String(),
+			Host:       host,
+			Path:       req.URL.EscapedPath(),
+			Query:      req.URL.RawQuery,
+			BodySHA256: hex.EncodeToString(sum[:]),
+			Date:       now.Format(time.RFC3339),
+			Nonce:      hex.EncodeToString(nonce),
+			Timestamp:  now.Unix(),
+		}
+		for _, h := range rs.headers {
+			var b strings.Builder
+			if err := h.tmpl.Execute(&b, data); err != nil {
+				return fmt.Errorf("-sign-header %s: %v", h.name, err)
+			}
+			req.Header.Set(h.name, b.String())
+		}
+	}
+	// SigV4 goes last so it covers any X-Amz- header a template set.
+	if rs.service != "" {
+		signV4(req, body, rs.accessKey, rs.secretKey, rs.sessionToken, rs.region, rs.service, now)
+	}
+	return nil
+}
+
+// signingTransport signs the requests to the target hosts just before they
+// are sent, after every other header is in place; its signature replaces
+// any other Authorization header.
+type signingTransport struct {
+	next   http.RoundTripper
+	signer *requestSigner
+	creds  *credentialStore
+}
+
+func (t *signingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !t.creds.trusted(req.Context(), req.URL.Host) {
+		return t.next.RoundTrip(req)
+	}
+	req = req.Clone(req.Context())
+	if err := t.signer.sign(req); err != nil {
+		return nil, err
+	}
+	return t.next.RoundTrip(req)
+}
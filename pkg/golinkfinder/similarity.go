@@ -0,0 +1,135 @@
+package golinkfinder
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"math/bits"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sync"
+)
+
+var simhashToken = regexp.MustCompile(`[A-Za-z0-9_]+`)
+
+// simhash fingerprints a body so that pages differing in a few tokens (a
+// CSRF token, a timestamp) land within a small Hamming distance.
+func simhash(body []byte) uint64 {
+	var weights [64]int
+	for _, token := range simhashToken.FindAll(body, -1) {
+		h := fnv.New64a()
+		h.Write(token)
+		sum := h.Sum64()
+		for i := 0; i < 64; i++ {
+			if sum&(1<<uint(i)) != 0 {
+				weights[i]++
+			} else {
+				weights[i]--
+			}
+		}
+	}
+	var hash uint64
+	for i, w := range weights {
+		if w > 0 {
+			hash |= 1 << uint(i)
+		}
+	}
+	return hash
+}
+
+// similarResponses reports whether two probe answers are the same page: same
+// status, lengths within 10% and nearly identical simhashes.
+func similarResponses(a, b probeResult) bool {
+	if a.err != nil || b.err != nil || a.status != b.status || a.method != b.method {
+		return false
+	}
+	diff := a.length - b.length
+	if diff < 0 {
+		diff = -diff
+	}
+	max := a.length
+	if b.length > max {
+		max = b.length
+	}
+	if max > 0 && diff*10 > max {
+		return false
+	}
+	return bits.OnesCount64(a.simhash^b.simhash) <= 3
+}
+
+// probeBaselines holds, per origin, the answer to a path that cannot exist.
+// Endpoints answering the same way are served by a catch-all route (an SPA
+// shell, a soft 404) rather than really existing.
+type probeBaselines struct {
+	mu      sync.Mutex
+	origins map[string]*probeBaseline
+}
+
+type probeBaseline struct {
+	once sync.Once
+	res  probeResult
+}
+
+func newProbeBaselines() *probeBaselines {
+	return &probeBaselines{origins: make(map[string]*probeBaseline)}
+}
+
+func (b *probeBaselines) get(ctx context.Context, client *http.Client, target string) probeResult {
+	u, err := url.Parse(target)
+	if err != nil {
+		return probeResult{err: err}
+	}
+	origin := u.Scheme + "://" + u.Host
+	b.mu.Lock()
+	base, ok := b.origins[origin]
+	if !ok {
+		base = &probeBaseline{}
+		b.origins[origin] = base
+	}
+	b.mu.Unlock()
+	base.once.Do(func() {
+		base.res = probeURL(ctx, client, "GET", fmt.Sprintf("%s/golinkfinder-%016x", origin, rand.Uint64()))
+	})
+	return base.res
+}
+
+// probeClusters groups probe answers by similarity. Once a cluster holds
+// limit endpoints, further members are collapsed into its count.
+type probeClusters struct {
+	limit    int
+	clusters []*probeCluster
+}
+
+type probeCluster struct {
+	first probeResult
+	count int
+}
+
+// add files res under its cluster and reports whether it should still be
+// printed.
+func (pc *probeClusters) add(res probeResult) bool {
+	if res.err != nil {
+		return true
+	}
+	for _, cl := range pc.clusters {
+		if similarResponses(cl.first, res) {
+			cl.count++
+			return cl.count <= pc.limit
+		}
+	}
+	pc.clusters = append(pc.clusters, &probeCluster{first: res, count: 1})
+	return true
+}
+
+// collapsed returns the clusters that outgrew the limit.
+func (pc *probeClusters) collapsed() []*probeCluster {
+	var out []*probeCluster
+	for _, cl := range pc.clusters {
+		if cl.count > pc.limit {
+			out = append(out, cl)
+		}
+	}
+	return out
+}
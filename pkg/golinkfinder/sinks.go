@@ -0,0 +1,96 @@
+package golinkfinder
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// defaultSinks are the calls that send a request to the path they are
+// given. A trailing "." stands for any method of the object (axios.get,
+// axios.post...); -sink adds the clients of a codebase (api.request).
+var defaultSinks = []string{"fetch", "axios", "axios.", "$.", "jQuery.", "navigator.sendBeacon", "ky", "ky.", "superagent.", "got", "got.", "this.http.", "http.", "request", "request.", "new Request", "new EventSource", "importScripts"}
+
+var (
+	sinkVerbs = map[string]string{"get": "GET", "getjson": "GET", "post": "POST", "put": "PUT", "patch": "PATCH", "delete": "DELETE", "del": "DELETE", "head": "HEAD", "options": "OPTIONS"}
+	// xhrOpenRegex matches xhr.open("GET", just before a path; the
+	// receiver can be named anything.
+	xhrOpenRegex = regexp.MustCompile(`(?:^|[^\w$.])[\w$]+\.open\s*\(\s*["'` + "`" + `](\w+)["'` + "`" + `]\s*,\s*$`)
+	// axiosInstanceRegex matches api = axios.create( and instanceCallRegex
+	// a call of such an instance, api("/x") or api.get("/x").
+	axiosInstanceRegex = regexp.MustCompile(`(?:^|[^\w$.])([\w$]+)\s*=\s*axios\.create\s*\(`)
+	instanceCallRegex  = regexp.MustCompile(`(?:^|[^\w$.])([\w$]+)(?:\.([\w$]+))?\s*\(\s*$`)
+)
+
+// axiosInstances returns the names content assigns axios.create clients
+// to, whose calls are sinks as well.
+func axiosInstances(content string) map[string]bool {
+	if !strings.Contains(content, "axios.create") {
+		return nil
+	}
+	instances := make(map[string]bool)
+	for _, m := range axiosInstanceRegex.FindAllStringSubmatch(content, -1) {
+		instances[m[1]] = true
+	}
+	return instances
+}
+
+// sinkMatcher finds the HTTP call a path literal is passed to, looking at
+// the code just before its opening quote.
+type sinkMatcher struct {
+	re *regexp.Regexp
+}
+
+func newSinkMatcher(extra []string) (*sinkMatcher, error) {
+	names := make([]string, 0, len(defaultSinks)+len(extra))
+	for _, name := range append(append([]string{}, defaultSinks...), extra...) {
+		name = strings.TrimSpace(name)
+		if name == "" || strings.ContainsAny(name, "()\"'`") {
+			return nil, fmt.Errorf("-sink must be a function name such as api.request, got '%s'", name)
+		}
+		pattern := regexp.QuoteMeta(strings.TrimSuffix(name, "."))
+		pattern = strings.ReplaceAll(pattern, " ", `\s+`)
+		if strings.HasSuffix(name, ".") {
+			pattern += `\.[\w$]+`
+		}
+		names = append(names, pattern)
+	}
+	// The call, then an optional method argument or an options object
+	// whose url/path the literal is.
+	re, err := regexp.Compile(`(?:^|[^\w$.])(` + strings.Join(names, "|") + `)\s*\(\s*(?:\{\s*(?:url|uri|path)\s*:\s*)?$`)
+	if err != nil {
+		return nil, fmt.Errorf("invalid -sink: %v", err)
+	}
+	return &sinkMatcher{re: re}, nil
+}
+
+// match returns the sink of the literal whose opening quote is at quote
+// in content, and the HTTP method it implies, if any. instances are the
+// axios.create clients of content.
+func (m *sinkMatcher) match(content string, quote int, instances map[string]bool) (string, string) {
+	start := quote - 120
+	if start < 0 {
+		start = 0
+	}
+	before := content[start:quote]
+	if sub := xhrOpenRegex.FindStringSubmatch(before); sub != nil {
+		return "XMLHttpRequest.open", strings.ToUpper(sub[1])
+	}
+	sub := m.re.FindStringSubmatch(before)
+	if sub == nil {
+		call := instanceCallRegex.FindStringSubmatch(before)
+		if call == nil || !instances[call[1]] {
+			return "", ""
+		}
+		if call[2] == "" {
+			return call[1], ""
+		}
+		return call[1] + "." + call[2], sinkVerbs[strings.ToLower(call[2])]
+	}
+	sink := strings.Join(strings.Fields(sub[1]), " ")
+	method := ""
+	if i := strings.LastIndex(sink, "."); i >= 0 {
+		method = sinkVerbs[strings.ToLower(sink[i+1:])]
+	}
+	return sink, method
+}
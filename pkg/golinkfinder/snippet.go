@@ -0,0 +1,37 @@
+package golinkfinder
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// snippetSpace flattens line breaks and tabs so a snippet stays on one line.
+var snippetSpace = strings.NewReplacer("\r\n", " ", "\n", " ", "\r", " ", "\t", " ")
+
+// addContext sets, with -context, the code around every located finding:
+// up to n bytes on each side of the value, cut at character boundaries.
+func addContext(body []byte, contentType string, findings []Finding, n int) {
+	if n <= 0 {
+		return
+	}
+	body = toUTF8(body, contentType)
+	for i, f := range findings {
+		if f.Line == 0 || f.Offset < 0 || f.Offset >= len(body) {
+			continue
+		}
+		start, end := f.Offset-n, f.Offset+len(f.Value)+n
+		if start < 0 {
+			start = 0
+		}
+		if end > len(body) {
+			end = len(body)
+		}
+		for start > 0 && !utf8.RuneStart(body[start]) {
+			start++
+		}
+		for end < len(body) && !utf8.RuneStart(body[end]) {
+			end--
+		}
+		findings[i].Context = snippetSpace.Replace(string(body[start:end]))
+	}
+}
@@ -0,0 +1,126 @@
+package golinkfinder
+
+import (
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// Source languages that are lexed differently from plain JavaScript. They
+// come from original sources: -git repositories, -archive files and
+// sources named .ts, .tsx, .jsx or .vue.
+const (
+	langTS  = "ts"
+	langTSX = "tsx"
+	langJSX = "jsx"
+	langVue = "vue"
+)
+
+var sourceLangs = map[string]string{".ts": langTS, ".mts": langTS, ".cts": langTS, ".tsx": langTSX, ".jsx": langJSX, ".vue": langVue}
+
+var (
+	// typeOnlyRegex matches TypeScript statements that only name modules
+	// for their types; the paths in them are never requested.
+	typeOnlyRegex = regexp.MustCompile(`(?m)^[ \t]*(?:import\s+type\b|export\s+type\s*\{)[^;]*?\bfrom\s*["'][^"'\n]*["'];?|^[ \t]*declare\s+module\s+["'][^"'\n]*["']|^[ \t]*///\s*<reference\b[^\n]*`)
+	// vueScriptRegex matches the <script> blocks of a single-file component.
+	vueScriptRegex = regexp.MustCompile(`(?is)<script\b([^>]*)>(.*?)</script\s*>`)
+	vueLangRegex   = regexp.MustCompile(`(?i)\blang\s*=\s*["']?(\w+)`)
+)
+
+// sourceLang returns the language of a source from its file extension, or
+// "" for plain JavaScript, HTML and everything else.
+func sourceLang(source string) string {
+	name := strings.TrimSuffix(source, " (removed)")
+	if u, err := url.Parse(name); err == nil && u.Scheme != "" && u.Host != "" {
+		name = u.Path
+	}
+	return sourceLangs[strings.ToLower(path.Ext(name))]
+}
+
+func isJSXLang(lang string) bool {
+	return lang == langJSX || lang == langTSX
+}
+
+// blank replaces the bytes of src in spans with spaces, keeping newlines
+// so lines and offsets still point into the original.
+func blank(src []byte, spans []textSpan) []byte {
+	out := append([]byte(nil), src...)
+	for _, span := range spans {
+		for i := span.start; i < span.end; i++ {
+			if out[i] != '\n' {
+				out[i] = ' '
+			}
+		}
+	}
+	return out
+}
+
+// stripTypeOnly blanks the type-only imports, exports, module declarations
+// and reference directives of TypeScript source.
+func stripTypeOnly(content string) string {
+	var spans []textSpan
+	for _, loc := range typeOnlyRegex.FindAllStringIndex(content, -1) {
+		spans = append(spans, textSpan{loc[0], loc[1]})
+	}
+	if spans == nil {
+		return content
+	}
+	return string(blank([]byte(content), spans))
+}
+
+// vueBlock is the code of one <script> block and its lang attribute.
+type vueBlock struct {
+	span textSpan
+	lang string
+}
+
+func vueScripts(body []byte) []vueBlock {
+	var blocks []vueBlock
+	for _, loc := range vueScriptRegex.FindAllSubmatchIndex(body, -1) {
+		lang := ""
+		if m := vueLangRegex.FindSubmatch(body[loc[2]:loc[3]]); m != nil {
+			lang = strings.ToLower(string(m[1]))
+		}
+		blocks = append(blocks, vueBlock{span: textSpan{loc[4], loc[5]}, lang: lang})
+	}
+	return blocks
+}
+
+// extractVue scans a Vue single-file component as its parts: the template
+// and styles as HTML, with the scripts blanked out, and each <script> block
+// as JavaScript, TypeScript or JSX after its lang attribute, with the rest
+// of the file blanked. Files without a script block are plain HTML.
+func extractVue(source string, body []byte, rules []extractionRule, stringsOnly bool) ([]Finding, bool) {
+	blocks := vueScripts(body)
+	if len(blocks) == 0 {
+		return nil, false
+	}
+	spans := make([]textSpan, len(blocks))
+	for i, b := range blocks {
+		spans[i] = b.span
+	}
+	findings := make([]Finding, 0)
+	seen := make(map[[2]string]struct{})
+	add := func(more []Finding) {
+		for _, f := range more {
+			key := [2]string{f.Category, f.Value}
+			if _, ok := seen[key]; !ok {
+				seen[key] = struct{}{}
+				findings = append(findings, f)
+			}
+		}
+	}
+	add(extractHTML(source, blank(body, spans), rules))
+	for _, b := range blocks {
+		// Blank everything around the block.
+		around := []textSpan{{0, b.span.start}, {b.span.end, len(body)}}
+		lang := ""
+		switch b.lang {
+		case langTS, langTSX, langJSX:
+			lang = b.lang
+		}
+		add(matchLang(source, blank(body, around), rules, stringsOnly, lang))
+	}
+	return findings, true
+}
@@ -0,0 +1,98 @@
+package golinkfinder
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"mime"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+// sourceMeta describes how a source was fetched. Supplied content (stdin,
+// -burp, -git) has no status or duration.
+type sourceMeta struct {
+	URL         string        `json:"url"`
+	Status      int           `json:"status,omitempty"`
+	ContentType string        `json:"content_type,omitempty"`
+	Bytes       int64         `json:"bytes"`
+	Hash        string        `json:"sha256,omitempty"`
+	Duration    time.Duration `json:"-"`
+	Millis      int64         `json:"duration_ms"`
+	Findings    int           `json:"findings"`
+	// Truncated counts the findings dropped by -max-findings-per-source.
+	Truncated int    `json:"truncated,omitempty"`
+	Error     string `json:"error,omitempty"`
+	// ErrorClass sorts Error (dns, tls, timeout, http_5xx, blocked, ...)
+	// and Retryable says whether a later attempt may succeed.
+	ErrorClass string `json:"error_class,omitempty"`
+	Retryable  bool   `json:"retryable,omitempty"`
+}
+
+func contentHash(body []byte) string {
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:])
+}
+
+// describe renders the fetch details shown next to a source in the
+// console, e.g. "200, application/javascript, 1.2 MB, 340ms".
+func (m sourceMeta) describe() string {
+	var parts []string
+	if m.Status != 0 {
+		parts = append(parts, fmt.Sprint(m.Status))
+	}
+	if m.ContentType != "" {
+		mediaType, _, err := mime.ParseMediaType(m.ContentType)
+		if err != nil {
+			mediaType = m.ContentType
+		}
+		parts = append(parts, mediaType)
+	}
+	parts = append(parts, formatBytes(m.Bytes))
+	if m.Duration > 0 {
+		parts = append(parts, m.Duration.Round(time.Millisecond).String())
+	}
+	if m.Truncated > 0 {
+		parts = append(parts, "truncated")
+	}
+	return strings.Join(parts, ", ")
+}
+
+// servesErrorPage reports a script URL answered with HTML, which is how
+// SPA fallbacks and soft-404 pages hide a missing bundle behind a 200.
+func (m sourceMeta) servesErrorPage() bool {
+	u, err := url.Parse(m.URL)
+	if err != nil {
+		return false
+	}
+	ext := strings.ToLower(path.Ext(u.Path))
+	return (ext == ".js" || ext == ".mjs") && strings.Contains(strings.ToLower(m.ContentType), "html")
+}
+
+// summaryTopSources is how many of the slowest and largest sources the
+// summary lists.
+const summaryTopSources = 3
+
+// extremeSources returns the slowest and the largest fetched sources.
+func extremeSources(sources []sourceMeta) (slowest, largest []sourceMeta) {
+	fetched := make([]sourceMeta, 0, len(sources))
+	for _, m := range sources {
+		if m.Status != 0 {
+			fetched = append(fetched, m)
+		}
+	}
+	top := func(less func(a, b sourceMeta) bool) []sourceMeta {
+		sorted := append([]sourceMeta{}, fetched...)
+		sort.SliceStable(sorted, func(i, j int) bool { return less(sorted[i], sorted[j]) })
+		if len(sorted) > summaryTopSources {
+			sorted = sorted[:summaryTopSources]
+		}
+		return sorted
+	}
+	slowest = top(func(a, b sourceMeta) bool { return a.Duration > b.Duration })
+	largest = top(func(a, b sourceMeta) bool { return a.Bytes > b.Bytes })
+	return slowest, largest
+}
@@ -0,0 +1,299 @@
+package golinkfinder
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// scanStats summarizes one run for the end-of-scan report and -summary.
+type scanStats struct {
+	ScanID     string         `json:"scan_id"`
+	Version    string         `json:"version"`
+	Started    time.Time      `json:"started"`
+	Duration   time.Duration  `json:"-"`
+	Seconds    float64        `json:"duration_seconds"`
+	Targets    int            `json:"targets"`
+	Failed     int            `json:"failed"`
+	Skipped    int            `json:"skipped"`
+	Aborted    int            `json:"aborted,omitempty"`
+	Endpoints  int            `json:"endpoints"`
+	Bytes      int64          `json:"bytes_downloaded"`
+	Reused     int            `json:"duplicate_bodies"`
+	Categories map[string]int `json:"categories"`
+	Hosts      map[string]int `json:"hosts"`
+	// Errors counts the targets not scanned by error class.
+	Errors map[string]int `json:"errors,omitempty"`
+	// AuthRequired lists the targets that answered with a login wall.
+	AuthRequired []string `json:"auth_required,omitempty"`
+	// OutOfScope counts the URL findings -scope dropped.
+	OutOfScope int `json:"out_of_scope,omitempty"`
+	// Suppressed counts, per library, the findings -ignore-libs dropped.
+	Suppressed map[string]int `json:"suppressed_libraries,omitempty"`
+	// PrivateHosts lists the hosts found that resolve to private addresses.
+	PrivateHosts []string `json:"private_hosts,omitempty"`
+	// Ignored counts, per tag, the findings the ignore file dropped.
+	Ignored map[string]int `json:"ignored,omitempty"`
+	// Unprinted counts, per host, the findings -sample-per-host kept off
+	// the terminal.
+	Unprinted map[string]int `json:"unprinted,omitempty"`
+	// Sources lists every source with how it was fetched.
+	Sources []sourceMeta `json:"sources,omitempty"`
+	// Config is the effective flag configuration the run used.
+	Config map[string]string `json:"config"`
+	// Labels are the -label pairs of the run.
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+func newScanStats(config map[string]string) *scanStats {
+	return &scanStats{
+		ScanID:     newScanID(),
+		Version:    toolVersion(),
+		Started:    time.Now(),
+		Categories: make(map[string]int),
+		Hosts:      make(map[string]int),
+		Errors:     make(map[string]int),
+		Ignored:    make(map[string]int),
+		Config:     config,
+	}
+}
+
+// addNew counts a value seen for the first time under its category and
+// the host of the source it came from.
+func (st *scanStats) addNew(f Finding) {
+	st.Categories[f.Category]++
+	host := f.Source
+	if u, err := url.Parse(f.Source); err == nil && u.Host != "" {
+		host = u.Host
+	}
+	st.Hosts[host]++
+	if h := f.HostInfo; h != nil && h.Private && !containsString(st.PrivateHosts, h.Host) {
+		st.PrivateHosts = append(st.PrivateHosts, h.Host)
+	}
+}
+
+// addSource records the fetch details of one source.
+func (st *scanStats) addSource(res linkFinderResult) {
+	m := res.meta
+	m.Millis = m.Duration.Milliseconds()
+	m.Findings = len(res.findings)
+	if res.err != nil {
+		m.Error = res.err.Error()
+		m.ErrorClass = errorClass(res.err)
+		m.Retryable = retryableClasses[m.ErrorClass]
+		st.Errors[m.ErrorClass]++
+	}
+	if m.Status != 0 {
+		st.Bytes += m.Bytes
+	}
+	st.Sources = append(st.Sources, m)
+}
+
+func (st *scanStats) finish(found resultSet) {
+	st.Duration = time.Since(st.Started)
+	st.Seconds = st.Duration.Seconds()
+	st.Endpoints = found.Len()
+}
+
+type countEntry struct {
+	key   string
+	count int
+}
+
+func sortedCounts(m map[string]int) []countEntry {
+	entries := make([]countEntry, 0, len(m))
+	for k, v := range m {
+		entries = append(entries, countEntry{k, v})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].count != entries[j].count {
+			return entries[i].count > entries[j].count
+		}
+		return entries[i].key < entries[j].key
+	})
+	return entries
+}
+
+const summaryTopHosts = 5
+
+func formatBytes(n int64) string {
+	switch {
+	case n >= 1<<30:
+		return fmt.Sprintf("%.2f GB", float64(n)/(1<<30))
+	case n >= 1<<20:
+		return fmt.Sprintf("%.2f MB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.2f KB", float64(n)/(1<<10))
+	}
+	return fmt.Sprintf("%d B", n)
+}
+
+func printSummary(st *scanStats) {
+	fmt.Fprintf(statusOut, "\n%s%s[*] Summary%s%s\n", c.Bold, c.Yellow, c.End, c.End)
+	fmt.Fprintf(statusOut, "  Scan ID:    %s (golinkfinder %s)\n", st.ScanID, st.Version)
+	fmt.Fprintf(statusOut, "  Targets:    %d scanned, %d failed", st.Targets, st.Failed)
+	if st.Skipped > 0 {
+		fmt.Fprintf(statusOut, ", %d skipped by -content-type", st.Skipped)
+	}
+	if st.Aborted > 0 {
+		fmt.Fprintf(statusOut, ", %d not scanned (cancelled or timed out)", st.Aborted)
+	}
+	fmt.Fprintln(statusOut)
+	if len(st.Errors) > 0 {
+		var parts []string
+		retryable := 0
+		for _, e := range sortedCounts(st.Errors) {
+			parts = append(parts, fmt.Sprintf("%d %s", e.count, e.key))
+			if retryableClasses[e.key] {
+				retryable += e.count
+			}
+		}
+		fmt.Fprintf(statusOut, "  Errors:     %s", strings.Join(parts, ", "))
+		if retryable > 0 {
+			fmt.Fprintf(statusOut, " (%d retryable, save them with -o-retry)", retryable)
+		}
+		fmt.Fprintln(statusOut)
+	}
+	if len(st.AuthRequired) > 0 {
+		fmt.Fprintf(statusOut, "  Auth:       %d target(s) behind a login wall (save them with -o-auth)\n", len(st.AuthRequired))
+	}
+	fmt.Fprintf(statusOut, "  Endpoints:  %d unique\n", st.Endpoints)
+	fmt.Fprintf(statusOut, "  Downloaded: %s in %s\n", formatBytes(st.Bytes), st.Duration.Round(time.Millisecond))
+	if st.Reused > 0 {
+		fmt.Fprintf(statusOut, "  Duplicates: %d identical bodies reused without re-extracting\n", st.Reused)
+	}
+	if st.OutOfScope > 0 {
+		fmt.Fprintf(statusOut, "  Scope:      %d out-of-scope URL findings dropped\n", st.OutOfScope)
+	}
+	if len(st.Suppressed) > 0 {
+		total := 0
+		names := make([]string, 0, len(st.Suppressed))
+		for _, e := range sortedCounts(st.Suppressed) {
+			total += e.count
+			names = append(names, e.key)
+		}
+		fmt.Fprintf(statusOut, "  Libraries:  %d findings suppressed (%s)\n", total, strings.Join(names, ", "))
+	}
+	if len(st.PrivateHosts) > 0 {
+		fmt.Fprintf(statusOut, "  %sPrivate:    %d host(s) resolve to private addresses (%s)%s\n", c.Red, len(st.PrivateHosts), strings.Join(st.PrivateHosts, ", "), c.End)
+	}
+	if len(st.Ignored) > 0 {
+		total := 0
+		tags := make([]string, 0, len(st.Ignored))
+		for _, e := range sortedCounts(st.Ignored) {
+			total += e.count
+			tags = append(tags, fmt.Sprintf("%s %d", e.key, e.count))
+		}
+		fmt.Fprintf(statusOut, "  Ignored:    %d triaged findings (%s)\n", total, strings.Join(tags, ", "))
+	}
+	if len(st.Unprinted) > 0 {
+		total := 0
+		for _, n := range st.Unprinted {
+			total += n
+		}
+		fmt.Fprintf(statusOut, "  Sampled:    %d findings on %d host(s) not printed (-sample-per-host)\n", total, len(st.Unprinted))
+	}
+	if slowest, largest := extremeSources(st.Sources); len(slowest) > 1 {
+		fmt.Fprintf(statusOut, "  Slowest:\n")
+		for _, m := range slowest {
+			fmt.Fprintf(statusOut, "    %-8s %s\n", m.Duration.Round(time.Millisecond), m.URL)
+		}
+		fmt.Fprintf(statusOut, "  Largest:\n")
+		for _, m := range largest {
+			fmt.Fprintf(statusOut, "    %-8s %s\n", formatBytes(m.Bytes), m.URL)
+		}
+	}
+	if len(st.Categories) > 0 {
+		fmt.Fprintf(statusOut, "  By category:\n")
+		for _, e := range sortedCounts(st.Categories) {
+			fmt.Fprintf(statusOut, "    %-14s %d\n", e.key, e.count)
+		}
+	}
+	if len(st.Hosts) > 0 {
+		fmt.Fprintf(statusOut, "  Top hosts:\n")
+		for i, e := range sortedCounts(st.Hosts) {
+			if i == summaryTopHosts {
+				break
+			}
+			fmt.Fprintf(statusOut, "    %-30s %d\n", e.key, e.count)
+		}
+	}
+}
+
+// summaryCount is a countEntry the HTML summary template can read.
+type summaryCount struct {
+	Name  string
+	Count int
+}
+
+// summaryCounts returns the largest limit counts of m, or all of them when
+// limit is 0.
+func summaryCounts(m map[string]int, limit int) []summaryCount {
+	var rows []summaryCount
+	for i, e := range sortedCounts(m) {
+		if i == limit && limit > 0 {
+			break
+		}
+		rows = append(rows, summaryCount{e.key, e.count})
+	}
+	return rows
+}
+
+var summaryHTML = template.Must(template.New("summary").Funcs(template.FuncMap{
+	"bytes":  formatBytes,
+	"counts": summaryCounts,
+	"top":    func() int { return summaryTopHosts },
+}).Parse(`<!DOCTYPE html>
+<html><head><meta charset="utf-8"><title>golinkfinder summary: {{.ScanID}}</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; margin-bottom: 1.5em; }
+td, th { border: 1px solid #ccc; padding: 2px 8px; text-align: left; font-family: monospace; }
+</style></head><body>
+<h1>Summary of {{.ScanID}}</h1>
+<table>
+<tr><th>Version</th><td>{{.Version}}</td></tr>
+<tr><th>Started</th><td>{{.Started.Format "2006-01-02 15:04:05"}}</td></tr>
+<tr><th>Duration</th><td>{{printf "%.1fs" .Seconds}}</td></tr>
+<tr><th>Targets</th><td>{{.Targets}} scanned, {{.Failed}} failed{{if .Skipped}}, {{.Skipped}} skipped{{end}}</td></tr>
+<tr><th>Endpoints</th><td>{{.Endpoints}} unique</td></tr>
+<tr><th>Downloaded</th><td>{{bytes .Bytes}}</td></tr>
+</table>
+{{if .Errors}}<h2>Errors</h2>
+<table>
+{{range counts .Errors 0}}<tr><td>{{.Name}}</td><td>{{.Count}}</td></tr>
+{{end}}</table>
+{{end}}{{if .Categories}}<h2>By category</h2>
+<table>
+{{range counts .Categories 0}}<tr><td>{{.Name}}</td><td>{{.Count}}</td></tr>
+{{end}}</table>
+{{end}}{{if .Hosts}}<h2>Top hosts</h2>
+<table>
+{{range counts .Hosts top}}<tr><td>{{.Name}}</td><td>{{.Count}}</td></tr>
+{{end}}</table>
+{{end}}</body></html>
+`))
+
+// writeSummary writes st as JSON, or as an HTML page when path ends in
+// .html.
+func writeSummary(path string, st *scanStats) error {
+	if ext := strings.ToLower(filepath.Ext(path)); ext == ".html" || ext == ".htm" {
+		var buf bytes.Buffer
+		if err := summaryHTML.Execute(&buf, st); err != nil {
+			return err
+		}
+		return os.WriteFile(path, buf.Bytes(), 0644)
+	}
+	data, err := json.MarshalIndent(st, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
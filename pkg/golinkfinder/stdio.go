@@ -0,0 +1,113 @@
+package golinkfinder
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"sync"
+)
+
+// protocolVersion is the version of the stdio JSONL protocol. Fields may be
+// added within a version; removing or changing one bumps it.
+const protocolVersion = 1
+
+// stdioRequest is one input line: a serveRequest tagged with an id the
+// answer echoes, so requests can be pipelined.
+type stdioRequest struct {
+	ID string `json:"id"`
+	serveRequest
+}
+
+// stdioMessage is one output line. The first line is always a "hello"
+// carrying the protocol and tool versions; every request then gets exactly
+// one "result" or "error" line, preceded by a "source" line per source
+// when it asked to stream.
+type stdioMessage struct {
+	V       int          `json:"v"`
+	Type    string       `json:"type"`
+	ID      string       `json:"id,omitempty"`
+	Version string       `json:"version,omitempty"`
+	Error   string       `json:"error,omitempty"`
+	Source  *serveSource `json:"source,omitempty"`
+	// *serveScan is the result, with the same fields as POST /scan
+	// answers (its own id is shadowed by the request's).
+	*serveScan
+}
+
+func runStdio(args []string) {
+	o := defaultScanOptions()
+	var maxLine int
+	fs := flag.NewFlagSet("stdio", flag.ExitOnError)
+	addScanFlags(fs, &o)
+	fs.IntVar(&maxLine, "max-line", 32<<20, "Longest accepted request line in bytes.")
+	err := parseScanFlags(fs, args, &o)
+	initOutput(&o)
+	if err != nil {
+		fatal(err)
+	}
+	if err := checkAPISafe(&o); err != nil {
+		fatal(err)
+	}
+	o.quiet = true
+
+	// stdout belongs to the protocol; anything else goes to stderr.
+	out := json.NewEncoder(os.Stdout)
+	statusOut = os.Stderr
+	var mu sync.Mutex
+	send := func(m stdioMessage) {
+		m.V = protocolVersion
+		mu.Lock()
+		defer mu.Unlock()
+		out.Encode(m)
+	}
+
+	ctx, stop := signalContext()
+	defer stop()
+	s, err := newScanSession(&o, cliOutput())
+	if err != nil {
+		fatal(err)
+	}
+	defer s.Close()
+	srv := newScanServer(s, "", 0, 0)
+	send(stdioMessage{Type: "hello", Version: toolVersion()})
+
+	var wg sync.WaitGroup
+	scanner := bufio.NewScanner(os.Stdin)
+	scanner.Buffer(make([]byte, 64*1024), maxLine)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		var req stdioRequest
+		if err := json.Unmarshal(line, &req); err != nil {
+			send(stdioMessage{Type: "error", Error: fmt.Sprintf("invalid request: %v", err)})
+			continue
+		}
+		jobs, err := req.jobs(&o)
+		if err != nil {
+			send(stdioMessage{Type: "error", ID: req.ID, Error: err.Error()})
+			continue
+		}
+		wg.Add(1)
+		go func(req stdioRequest, jobs []scanJob) {
+			defer wg.Done()
+			if !req.Stream {
+				send(stdioMessage{Type: "result", ID: req.ID, serveScan: srv.scan(ctx, jobs, nil)})
+				return
+			}
+			scan := srv.scan(ctx, jobs, func(src serveSource) {
+				send(stdioMessage{Type: "source", ID: req.ID, Source: &src})
+			})
+			scan.Sources = nil
+			send(stdioMessage{Type: "result", ID: req.ID, serveScan: scan})
+		}(req, jobs)
+	}
+	wg.Wait()
+	if err := scanner.Err(); err != nil {
+		send(stdioMessage{Type: "error", Error: fmt.Sprintf("could not read request: %v", err)})
+		os.Exit(1)
+	}
+}
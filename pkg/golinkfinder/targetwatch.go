@@ -0,0 +1,96 @@
+package golinkfinder
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// targetPollInterval is how often a monitor looks for edited target lists.
+const targetPollInterval = 5 * time.Second
+
+// targetWatcher notices edits to the -l files and -targets-dir of a
+// monitor, so targets can be added and removed without restarting it.
+// Files are polled by size and modification time.
+type targetWatcher struct {
+	files []string
+	dir   string
+	last  string
+}
+
+func newTargetWatcher(o *scanOptions) *targetWatcher {
+	w := &targetWatcher{dir: o.targetsDir}
+	for _, path := range o.urlLists {
+		if path != "-" {
+			w.files = append(w.files, path)
+		}
+	}
+	if len(w.files) == 0 && w.dir == "" {
+		return nil
+	}
+	w.last = w.fingerprint()
+	return w
+}
+
+func (w *targetWatcher) fingerprint() string {
+	var b strings.Builder
+	stat := func(path string) {
+		if info, err := os.Stat(path); err == nil {
+			fmt.Fprintf(&b, "%s %d %d\n", path, info.Size(), info.ModTime().UnixNano())
+		} else {
+			fmt.Fprintf(&b, "%s missing\n", path)
+		}
+	}
+	for _, path := range w.files {
+		stat(path)
+	}
+	if w.dir != "" {
+		entries, _ := os.ReadDir(w.dir)
+		for _, e := range entries {
+			if !strings.HasPrefix(e.Name(), ".") {
+				stat(filepath.Join(w.dir, e.Name()))
+			}
+		}
+	}
+	return b.String()
+}
+
+// changed reports whether a watched file was edited, added or removed since
+// the last call.
+func (w *targetWatcher) changed() bool {
+	fp := w.fingerprint()
+	if fp == w.last {
+		return false
+	}
+	w.last = fp
+	return true
+}
+
+// diffTargets returns the URLs of next missing from prev and of prev
+// missing from next.
+func diffTargets(prev, next []scanJob) (added, removed []string) {
+	urls := func(jobs []scanJob) map[string]bool {
+		set := make(map[string]bool, len(jobs))
+		for _, job := range jobs {
+			set[job.url] = true
+		}
+		return set
+	}
+	before, after := urls(prev), urls(next)
+	for u := range after {
+		if !before[u] {
+			added = append(added, u)
+		}
+	}
+	for u := range before {
+		if !after[u] {
+			removed = append(removed, u)
+		}
+	}
+	sort.Strings(added)
+	sort.Strings(removed)
+	return added, removed
+}
@@ -0,0 +1,78 @@
+package golinkfinder
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+var (
+	numericSegment = regexp.MustCompile(`^\d+$`)
+	uuidSegment    = regexp.MustCompile(`(?i)^[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$`)
+	hashSegment    = regexp.MustCompile(`(?i)^[0-9a-f]{16,}$`)
+	// Route parameters already spelled out by frameworks: :id, ${id}, {id}, [id].
+	routeParamSegment = regexp.MustCompile(`^(?::(\w+)|\$\{\s*([\w.]+)\s*\}|\{(\w+)\}|\[(\w+)\])$`)
+)
+
+// templateSegment returns the placeholder for a path segment that looks
+// like a parameter, or the segment unchanged.
+func templateSegment(segment string) string {
+	switch {
+	case numericSegment.MatchString(segment):
+		return "{id}"
+	case uuidSegment.MatchString(segment):
+		return "{uuid}"
+	case hashSegment.MatchString(segment) && strings.ContainsAny(segment, "0123456789"):
+		return "{hash}"
+	}
+	if m := routeParamSegment.FindStringSubmatch(segment); m != nil {
+		for _, name := range m[1:] {
+			if name != "" {
+				return "{" + name + "}"
+			}
+		}
+	}
+	return segment
+}
+
+// templatePath collapses the parameter-looking segments of an endpoint's
+// path, e.g. /users/123/orders/456 becomes /users/{id}/orders/{id}. Query
+// strings and fragments are kept as they are.
+func templatePath(value string) string {
+	pathPart, rest := value, ""
+	if i := strings.IndexAny(value, "?#"); i >= 0 {
+		pathPart, rest = value[:i], value[i:]
+	}
+	prefix := ""
+	if u, err := url.Parse(pathPart); err == nil && u.Host != "" {
+		prefix = u.Scheme + "://" + u.Host
+		pathPart = strings.TrimPrefix(pathPart, prefix)
+	}
+	segments := strings.Split(pathPart, "/")
+	for i, segment := range segments {
+		if segment != "" {
+			segments[i] = templateSegment(segment)
+		}
+	}
+	return prefix + strings.Join(segments, "/") + rest
+}
+
+// templatedResultSet returns a set of the templated forms of every value
+// in set, keeping their sources.
+func templatedResultSet(set resultSet, trackRefs bool) (resultSet, error) {
+	templated := newMemoryResultSet(trackRefs)
+	err := set.EachWithRefs(func(value string, refs []sourceRef) error {
+		t := templatePath(value)
+		if len(refs) == 0 {
+			_, err := templated.Add(t, sourceRef{})
+			return err
+		}
+		for _, ref := range refs {
+			if _, err := templated.Add(t, ref); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return templated, err
+}
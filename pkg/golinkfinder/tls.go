@@ -0,0 +1,82 @@
+package golinkfinder
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+
+	utls "github.com/refraction-networking/utls"
+)
+
+// tlsFingerprints maps -tls-impersonate names to the uTLS ClientHello they
+// mimic. "random" picks a fresh randomized fingerprint per connection.
+var tlsFingerprints = map[string]utls.ClientHelloID{
+	"chrome":  utls.HelloChrome_Auto,
+	"firefox": utls.HelloFirefox_Auto,
+	"safari":  utls.HelloSafari_Auto,
+	"edge":    utls.HelloEdge_Auto,
+	"ios":     utls.HelloIOS_Auto,
+	"random":  utls.HelloRandomizedNoALPN,
+}
+
+func tlsFingerprintNames() string {
+	names := make([]string, 0, len(tlsFingerprints))
+	for name := range tlsFingerprints {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+// impersonatingDialer returns a DialTLSContext func that connects with dial
+// and performs the handshake with uTLS, sending serverName as SNI when set.
+// Browser presets advertise h2 via ALPN, but net/http can't speak HTTP/2
+// over a custom TLS connection, so the ALPN list is narrowed to http/1.1
+// and the rest of the hello is left as is.
+func impersonatingDialer(name, serverName string, dial dialFunc) (dialFunc, error) {
+	id, ok := tlsFingerprints[strings.ToLower(name)]
+	if !ok {
+		return nil, fmt.Errorf("unknown -tls-impersonate fingerprint '%s' (valid: %s)", name, tlsFingerprintNames())
+	}
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		host, _, err := net.SplitHostPort(addr)
+		if err != nil {
+			return nil, err
+		}
+		conn, err := dial(ctx, network, addr)
+		if err != nil {
+			return nil, err
+		}
+		if serverName != "" {
+			host = serverName
+		}
+		config := &utls.Config{ServerName: host, InsecureSkipVerify: true}
+		var uconn *utls.UConn
+		if id == utls.HelloRandomizedNoALPN {
+			uconn = utls.UClient(conn, config, id)
+		} else {
+			spec, err := utls.UTLSIdToSpec(id)
+			if err != nil {
+				conn.Close()
+				return nil, err
+			}
+			for _, ext := range spec.Extensions {
+				if alpn, ok := ext.(*utls.ALPNExtension); ok {
+					alpn.AlpnProtocols = []string{"http/1.1"}
+				}
+			}
+			uconn = utls.UClient(conn, config, utls.HelloCustom)
+			if err := uconn.ApplyPreset(&spec); err != nil {
+				conn.Close()
+				return nil, err
+			}
+		}
+		if err := uconn.HandshakeContext(ctx); err != nil {
+			conn.Close()
+			return nil, err
+		}
+		return uconn, nil
+	}, nil
+}
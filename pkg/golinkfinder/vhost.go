@@ -0,0 +1,55 @@
+package golinkfinder
+
+import (
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// vhostTransport sends https requests whose Host header names another host
+// than their URL (-host-header, or a target's "host") with that host as
+// the TLS server name. Each name gets its own transport, so those
+// connections are never pooled with the ones to the bare address.
+type vhostTransport struct {
+	base         http.RoundTripper
+	newTransport func(serverName string) (http.RoundTripper, error)
+	mu           sync.Mutex
+	byName       map[string]http.RoundTripper
+}
+
+func (t *vhostTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	name := vhostServerName(req)
+	if name == "" {
+		return t.base.RoundTrip(req)
+	}
+	t.mu.Lock()
+	rt, ok := t.byName[name]
+	if !ok {
+		var err error
+		if rt, err = t.newTransport(name); err != nil {
+			t.mu.Unlock()
+			return nil, err
+		}
+		t.byName[name] = rt
+	}
+	t.mu.Unlock()
+	return rt.RoundTrip(req)
+}
+
+// vhostServerName returns the server name req.Host asks for, or "" when it
+// is the URL's own host.
+func vhostServerName(req *http.Request) string {
+	if req.URL.Scheme != "https" || req.Host == "" {
+		return ""
+	}
+	name := req.Host
+	if host, _, err := net.SplitHostPort(name); err == nil {
+		name = host
+	}
+	name = strings.Trim(name, "[]")
+	if strings.EqualFold(name, req.URL.Hostname()) {
+		return ""
+	}
+	return name
+}
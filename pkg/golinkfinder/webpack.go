@@ -0,0 +1,236 @@
+package golinkfinder
+
+import (
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const categoryChunk = "chunk"
+
+var (
+	// The chunk filename function: webpack 5 emits __webpack_require__.u
+	// (r.u once minified), webpack 4 a jsonpScriptSrc helper.
+	webpackChunkFuncRegex  = regexp.MustCompile(`(?:\.u\s*=\s*(?:function\s*\(\s*([\w$]+)\s*\)\s*\{\s*return|\(?\s*([\w$]+)\s*\)?\s*=>)|function\s+jsonpScriptSrc\s*\(\s*([\w$]+)\s*\)\s*\{\s*return)\s*`)
+	webpackPublicPathRegex = regexp.MustCompile(`(?:__webpack_require__|\b[\w$])\.p\s*=\s*["']([^"']*)["']`)
+	webpackEnsureRegex     = regexp.MustCompile(`(?:__webpack_require__|\b[\w$])\.e\(\s*["']?([\w\-./]+?)["']?\s*\)`)
+	webpackMapEntryRegex   = regexp.MustCompile(`(?:"([^"]+)"|'([^']+)'|([\w$]+))\s*:\s*["']([^"']*)["']`)
+)
+
+// chunkExpr evaluates a parsed chunk filename expression for one chunk id.
+type chunkExpr func(id string) (string, bool)
+
+// chunkParser reads the small subset of JavaScript webpack uses for chunk
+// filenames: string concatenation of literals, the chunk id, the public
+// path, and object-literal lookups optionally falling back with ||.
+type chunkParser struct {
+	src    string
+	pos    int
+	param  string
+	public string
+	ids    map[string]struct{}
+	// usesPublic records whether the expression prepends the public path
+	// itself, as webpack 4 does; webpack 5 prepends it at the call site.
+	usesPublic bool
+	// bare holds the last object literal read without a subscript, for the
+	// ({...})[e] form.
+	bare map[string]string
+}
+
+func (p *chunkParser) skipSpace() {
+	for p.pos < len(p.src) && strings.IndexByte(" \t\r\n", p.src[p.pos]) >= 0 {
+		p.pos++
+	}
+}
+
+func (p *chunkParser) consume(token string) bool {
+	p.skipSpace()
+	if strings.HasPrefix(p.src[p.pos:], token) {
+		p.pos += len(token)
+		return true
+	}
+	return false
+}
+
+func (p *chunkParser) expr() chunkExpr {
+	terms := []chunkExpr{}
+	for {
+		term := p.or()
+		if term == nil {
+			return nil
+		}
+		terms = append(terms, term)
+		if !p.consume("+") {
+			break
+		}
+	}
+	return func(id string) (string, bool) {
+		var b strings.Builder
+		for _, term := range terms {
+			s, ok := term(id)
+			if !ok {
+				return "", false
+			}
+			b.WriteString(s)
+		}
+		return b.String(), true
+	}
+}
+
+func (p *chunkParser) or() chunkExpr {
+	alternatives := []chunkExpr{}
+	for {
+		alt := p.primary()
+		if alt == nil {
+			return nil
+		}
+		alternatives = append(alternatives, alt)
+		if !p.consume("||") {
+			break
+		}
+	}
+	// As in JavaScript, only a missing or empty value falls through to the
+	// next alternative; the last one stands even when empty, like the ""
+	// webpack 4 puts after the public path.
+	return func(id string) (string, bool) {
+		for i, alt := range alternatives {
+			if s, ok := alt(id); ok && (s != "" || i == len(alternatives)-1) {
+				return s, true
+			}
+		}
+		return "", false
+	}
+}
+
+func (p *chunkParser) primary() chunkExpr {
+	p.skipSpace()
+	if p.pos >= len(p.src) {
+		return nil
+	}
+	switch ch := p.src[p.pos]; {
+	case ch == '"' || ch == '\'':
+		end := strings.IndexByte(p.src[p.pos+1:], ch)
+		if end < 0 {
+			return nil
+		}
+		literal := p.src[p.pos+1 : p.pos+1+end]
+		p.pos += end + 2
+		return func(string) (string, bool) { return literal, true }
+	case ch == '(':
+		p.pos++
+		p.bare = nil
+		inner := p.expr()
+		if inner == nil || !p.consume(")") {
+			return nil
+		}
+		if table := p.bare; table != nil && p.subscript() {
+			return tableLookup(table)
+		}
+		return inner
+	case ch == '{':
+		end := strings.IndexByte(p.src[p.pos:], '}')
+		if end < 0 {
+			return nil
+		}
+		table := make(map[string]string)
+		for _, m := range webpackMapEntryRegex.FindAllStringSubmatch(p.src[p.pos:p.pos+end], -1) {
+			key := m[1] + m[2] + m[3]
+			table[key] = m[4]
+			p.ids[key] = struct{}{}
+		}
+		p.pos += end + 1
+		if p.subscript() {
+			return tableLookup(table)
+		}
+		p.bare = table
+		return func(string) (string, bool) { return "", false }
+	default:
+		start := p.pos
+		for p.pos < len(p.src) && (isIdentChar(p.src[p.pos]) || p.src[p.pos] == '.') {
+			p.pos++
+		}
+		switch ident := p.src[start:p.pos]; {
+		case ident == p.param:
+			return func(id string) (string, bool) { return id, true }
+		case strings.HasSuffix(ident, ".p"):
+			p.usesPublic = true
+			return func(string) (string, bool) { return p.public, true }
+		}
+		return nil
+	}
+}
+
+// subscript consumes a trailing [id] if there is one.
+func (p *chunkParser) subscript() bool {
+	save := p.pos
+	if p.consume("[") && p.consume(p.param) && p.consume("]") {
+		return true
+	}
+	p.pos = save
+	return false
+}
+
+func tableLookup(table map[string]string) chunkExpr {
+	return func(id string) (string, bool) {
+		value, ok := table[id]
+		return value, ok
+	}
+}
+
+func isIdentChar(ch byte) bool {
+	return ch == '_' || ch == '$' || ch >= 'a' && ch <= 'z' || ch >= 'A' && ch <= 'Z' || ch >= '0' && ch <= '9'
+}
+
+// webpackChunks reconstructs the URLs of every lazily-loaded chunk a webpack
+// runtime knows about. Relative chunk paths are resolved against the
+// bundle's own URL, as webpack does for publicPath "auto".
+func webpackChunks(source, content string) []string {
+	loc := webpackChunkFuncRegex.FindStringSubmatchIndex(content)
+	if loc == nil {
+		return nil
+	}
+	param := ""
+	for g := 1; g <= 3; g++ {
+		if loc[2*g] >= 0 {
+			param = content[loc[2*g]:loc[2*g+1]]
+		}
+	}
+	public := ""
+	if m := webpackPublicPathRegex.FindStringSubmatch(content); m != nil && m[1] != "auto" {
+		public = m[1]
+	}
+	p := &chunkParser{src: content, pos: loc[1], param: param, public: public, ids: make(map[string]struct{})}
+	filename := p.expr()
+	if filename == nil {
+		return nil
+	}
+	for _, m := range webpackEnsureRegex.FindAllStringSubmatch(content, -1) {
+		p.ids[m[1]] = struct{}{}
+	}
+
+	base, _ := url.Parse(source)
+	urls := make([]string, 0, len(p.ids))
+	for id := range p.ids {
+		name, ok := filename(id)
+		if !ok || !strings.HasSuffix(strings.SplitN(name, "?", 2)[0], ".js") {
+			continue
+		}
+		if !p.usesPublic {
+			name = public + name
+		}
+		urls = append(urls, resolveAgainst(base, name, false))
+	}
+	sort.Strings(urls)
+	return urls
+}
+
+type webpackExtractor struct{}
+
+func (webpackExtractor) Extract(source, contentType string, body []byte) []Finding {
+	findings := make([]Finding, 0)
+	for _, u := range webpackChunks(source, string(body)) {
+		findings = append(findings, Finding{Source: source, Value: u, Category: categoryChunk})
+	}
+	return findings
+}
@@ -0,0 +1,99 @@
+package golinkfinder
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestWebpackChunks(t *testing.T) {
+	tests := []struct {
+		name, source, content string
+		want                  []string
+	}{
+		{
+			"webpack 5 minified",
+			"https://app.example/static/js/main.js",
+			`r.p="/static/";r.u=e=>"js/"+({12:"admin",34:"users"}[e]||e)+"."+{12:"abc123",34:"def456",56:"789aaa"}[e]+".chunk.js";`,
+			[]string{
+				"https://app.example/static/js/56.789aaa.chunk.js",
+				"https://app.example/static/js/admin.abc123.chunk.js",
+				"https://app.example/static/js/users.def456.chunk.js",
+			},
+		},
+		{
+			"webpack 4 with a CDN public path",
+			"https://app.example/main.js",
+			`__webpack_require__.p = "https://cdn.example/";
+function jsonpScriptSrc(chunkId) { return __webpack_require__.p + "" + ({"0":"vendors"}[chunkId]||chunkId) + ".js" }
+__webpack_require__.e(3).then(load);`,
+			[]string{"https://cdn.example/3.js", "https://cdn.example/vendors.js"},
+		},
+		{
+			"publicPath auto",
+			"https://app.example/app/main.js",
+			`r.p="auto";r.u=function(e){return e+".js?v=2"};r.e("7");`,
+			[]string{"https://app.example/app/7.js?v=2"},
+		},
+		{
+			"not JavaScript chunks",
+			"https://app.example/main.js",
+			`r.u=e=>"css/"+e+".css";r.e(1);`,
+			nil,
+		},
+		{
+			"no runtime",
+			"https://app.example/main.js",
+			`var m={0:"chunk-abc123"};`,
+			nil,
+		},
+	}
+	for _, tt := range tests {
+		if got := webpackChunks(tt.source, tt.content); fmt.Sprint(got) != fmt.Sprint(tt.want) {
+			t.Errorf("%s: got %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestScanFollowsChunks(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/javascript")
+		switch r.URL.Path {
+		case "/main.js":
+			fmt.Fprint(w, `r.u=e=>"chunks/"+e+".js";r.e(1).then(route);`)
+		case "/chunks/1.js":
+			fmt.Fprint(w, `fetch('/api/lazy');`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+	defer func(w io.Writer) { statusOut = w }(statusOut)
+	statusOut = io.Discard
+
+	for _, noChunks := range []bool{false, true} {
+		o := defaultScanOptions()
+		o.quiet, o.noChunks = true, noChunks
+		s, err := newScanSession(&o, cliOutput())
+		if err != nil {
+			t.Fatal(err)
+		}
+		found, failed := s.run(context.Background(), []scanJob{{url: srv.URL + "/main.js"}})
+		values := map[string]bool{}
+		found.Each(func(value string) error {
+			values[value] = true
+			return nil
+		})
+		found.Close()
+		s.Close()
+		if failed != 0 {
+			t.Errorf("-no-chunks=%v: %d targets failed", noChunks, failed)
+		}
+		if values["/api/lazy"] == noChunks {
+			t.Errorf("-no-chunks=%v: got %v", noChunks, values)
+		}
+	}
+}